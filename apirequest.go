@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultUserAgent names Sentry in provider API calls when global.user_agent is unset
+const defaultUserAgent = "Sentry"
+
+// requestIDHeader carries the correlation ID of each provider API call
+const requestIDHeader = "X-Request-ID"
+
+// userAgent returns the User-Agent sent to provider APIs, e.g. "Sentry/1.0.0 (commit abc1234)"
+func userAgent(config *Config) string {
+	base := defaultUserAgent
+	if config.Global.UserAgent != "" {
+		base = config.Global.UserAgent
+	}
+	return fmt.Sprintf("%s/%s (commit %s)", base, Version, GitCommit)
+}
+
+// newRequestID returns a random correlation ID for one API call
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// newAPIRequest builds a provider API request identified by Sentry's User-Agent and a fresh request ID
+func (m *MonitorService) newAPIRequest(method string, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent(m.currentConfig()))
+	req.Header.Set(requestIDHeader, newRequestID())
+	return req, nil
+}
+
+// doAPIRequest sends req, records its status and latency with LogAPICall, and logs its request ID so a
+// failing call can be traced; a request that got no response is recorded with status 0
+func (m *MonitorService) doAPIRequest(req *http.Request) (*http.Response, error) {
+	startTime := time.Now()
+	resp, err := m.httpClient.Do(req)
+	duration := time.Since(startTime)
+
+	statusCode := 0
+	if err == nil {
+		statusCode = resp.StatusCode
+	}
+	AppLogger.LogAPICall(req.Method, req.URL.Redacted(), statusCode, duration)
+
+	if err != nil {
+		AppLogger.DebugS("API call failed",
+			"method", req.Method,
+			"url", req.URL.Redacted(),
+			"request_id", req.Header.Get(requestIDHeader),
+			"error", err)
+		return nil, err
+	}
+
+	AppLogger.DebugS("API call",
+		"method", req.Method,
+		"url", req.URL.Redacted(),
+		"status", resp.StatusCode,
+		"request_id", req.Header.Get(requestIDHeader))
+	return resp, nil
+}
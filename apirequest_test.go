@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProviderRequestsCarryUserAgentAndRequestID(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		userAgent string
+		fetch     func(m *MonitorService) (*CommitInfo, error)
+		body      string
+		wantAgent string
+	}{
+		{
+			name: "github",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGitHubLatestCommit(&MonitorConfig{RepoURL: "https://github.com/owner/app", RepoType: "github"}, "main", "")
+			},
+			body:      `{"sha":"abc123","commit":{"message":"m","author":{"name":"a"}}}`,
+			wantAgent: "Sentry/" + Version + " (commit " + GitCommit + ")",
+		},
+		{
+			name:      "gitlab with configured user agent",
+			userAgent: "Sentry-qa-team",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGitLabLatestCommit(&MonitorConfig{RepoURL: "https://gitlab.com/group/app", RepoType: "gitlab"}, "main")
+			},
+			body:      `{"id":"abc123","title":"m","author_name":"a"}`,
+			wantAgent: "Sentry-qa-team/" + Version + " (commit " + GitCommit + ")",
+		},
+		{
+			name: "gitea",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGiteaLatestCommit(&MonitorConfig{RepoURL: "https://gitea.example.com/owner/app", RepoType: "gitea"}, "main")
+			},
+			body:      `{"sha":"abc123","commit":{"message":"m","author":{"name":"a"}}}`,
+			wantAgent: "Sentry/" + Version + " (commit " + GitCommit + ")",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Global: GlobalConfig{UserAgent: tt.userAgent}}
+			monitor := NewMonitorService(config, nil)
+
+			var captured []*http.Request
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				captured = append(captured, req)
+				return stubResponse(http.StatusOK, tt.body), nil
+			})})
+
+			for i := 0; i < 2; i++ {
+				if _, err := tt.fetch(monitor); err != nil {
+					t.Fatalf("fetch error = %v", err)
+				}
+			}
+
+			if len(captured) != 2 {
+				t.Fatalf("captured %d requests, want 2", len(captured))
+			}
+			for _, req := range captured {
+				if got := req.Header.Get("User-Agent"); got != tt.wantAgent {
+					t.Errorf("User-Agent = %q, want %q", got, tt.wantAgent)
+				}
+				if req.Header.Get(requestIDHeader) == "" {
+					t.Errorf("%s header missing", requestIDHeader)
+				}
+			}
+			if captured[0].Header.Get(requestIDHeader) == captured[1].Header.Get(requestIDHeader) {
+				t.Error("request IDs repeat across calls")
+			}
+		})
+	}
+}
+
+func TestAPIErrorIncludesRequestID(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	monitor := NewMonitorService(&Config{}, nil)
+	var requestID string
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		requestID = req.Header.Get(requestIDHeader)
+		return stubResponse(http.StatusInternalServerError, `{"message":"boom"}`), nil
+	})})
+
+	_, err := monitor.getGitHubLatestCommit(&MonitorConfig{RepoURL: "https://github.com/owner/app", RepoType: "github"}, "main", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.RequestID != requestID || !strings.Contains(err.Error(), "request "+requestID) {
+		t.Errorf("error = %q, want request ID %s", err.Error(), requestID)
+	}
+}
+
+func TestProviderRequestsLogAPICall(t *testing.T) {
+	// LogAPICall reports successful calls at debug level
+	InitializeLogger(true)
+	t.Cleanup(func() { InitializeLogger(false) })
+
+	apiCallLine := regexp.MustCompile(`API call (successful|failed): GET (\S+) - (\d+) \((\S+)\)`)
+
+	tests := []struct {
+		name       string
+		fetch      func(m *MonitorService) (*CommitInfo, error)
+		status     int // 0 makes the transport fail without a response
+		body       string
+		wantURL    string
+		wantResult string
+	}{
+		{
+			name: "github success",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGitHubLatestCommit(&MonitorConfig{RepoURL: "https://github.com/owner/app", RepoType: "github"}, "main", "")
+			},
+			status:     http.StatusOK,
+			body:       `{"sha":"abc123","commit":{"message":"m","author":{"name":"a"}}}`,
+			wantURL:    "https://api.github.com/repos/owner/app/commits/main",
+			wantResult: "successful",
+		},
+		{
+			name: "gitlab not found",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGitLabLatestCommit(&MonitorConfig{RepoURL: "https://gitlab.com/group/app", RepoType: "gitlab"}, "main")
+			},
+			status:     http.StatusNotFound,
+			body:       `{"message":"404 Branch Not Found"}`,
+			wantURL:    "https://gitlab.com/api/v4/projects/group%2Fapp/repository/commits/main",
+			wantResult: "failed",
+		},
+		{
+			name: "gitea transport error",
+			fetch: func(m *MonitorService) (*CommitInfo, error) {
+				return m.getGiteaLatestCommit(&MonitorConfig{RepoURL: "https://gitea.example.com/owner/app", RepoType: "gitea"}, "main")
+			},
+			wantURL:    "https://gitea.example.com/api/v1/repos/owner/app/commits/main",
+			wantResult: "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			AppLogger.SetOutput(&logs)
+
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.retry = RetryConfig{}
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				time.Sleep(2 * time.Millisecond)
+				if tt.status == 0 {
+					return nil, errors.New("connection reset by peer")
+				}
+				return stubResponse(tt.status, tt.body), nil
+			})})
+
+			tt.fetch(monitor)
+
+			matches := apiCallLine.FindAllStringSubmatch(logs.String(), -1)
+			if len(matches) == 0 {
+				t.Fatalf("found no LogAPICall line:\n%s", logs.String())
+			}
+			// A failed commit lookup may be followed by diagnostic calls; the first is the commit request
+			match := matches[0]
+			if match[1] != tt.wantResult || match[2] != tt.wantURL || match[3] != strconv.Itoa(tt.status) {
+				t.Errorf("LogAPICall line = %q, want %s call to %s with status %d", match[0], tt.wantResult, tt.wantURL, tt.status)
+			}
+			if duration, err := time.ParseDuration(match[4]); err != nil || duration <= 0 {
+				t.Errorf("LogAPICall duration = %q, want a positive duration", match[4])
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"sort"
+)
+
+// qaBranchFor returns the QA branch a change on the monitored branch deploys to
+// An exact branch_map key wins; otherwise patterns are tried in sorted order, falling back to qa_repo_branch.
+func qaBranchFor(deploy *DeployConfig, branch string) string {
+	if branch == "" || len(deploy.BranchMap) == 0 {
+		return deploy.QARepoBranch
+	}
+	if qaBranch, ok := deploy.BranchMap[branch]; ok {
+		return qaBranch
+	}
+
+	patterns := make([]string, 0, len(deploy.BranchMap))
+	for pattern := range deploy.BranchMap {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if matchesBranch(pattern, branch) {
+			return deploy.BranchMap[pattern]
+		}
+	}
+	return deploy.QARepoBranch
+}
+
+// routeQABranch returns repoConfig deploying to the QA branch mapped from the trigger branch
+// A copy is returned when the branch differs so the shared configuration is never modified.
+func routeQABranch(repoConfig *RepositoryConfig, branch string) *RepositoryConfig {
+	qaBranch := qaBranchFor(&repoConfig.Deploy, branch)
+	if qaBranch == repoConfig.Deploy.QARepoBranch {
+		return repoConfig
+	}
+
+	AppLogger.InfoS("Routing deployment to mapped QA branch",
+		"repo", repoConfig.GetDisplayName(),
+		"branch", branch,
+		"qa_branch", qaBranch)
+	routed := *repoConfig
+	routed.Deploy.QARepoBranch = qaBranch
+	return &routed
+}
+
+// splitTriggerRounds groups trigger sources into deployment rounds, each routing every repository to one QA branch
+// A repository whose changed branches map to different QA branches deploys once per QA branch, in the order
+// first seen; sources of a repository mapping to the same QA branch share a deployment. Without sources
+// there is still one round, deploying without a trigger.
+func splitTriggerRounds(config *Config, sources []TriggerSource) [][]TriggerSource {
+	if len(sources) == 0 {
+		return [][]TriggerSource{nil}
+	}
+
+	deploys := make(map[string]*DeployConfig)
+	for i := range config.Repositories {
+		deploys[config.Repositories[i].Name] = &config.Repositories[i].Deploy
+	}
+
+	var rounds [][]TriggerSource
+	qaBranches := make(map[string][]string) // repoName -> QA branches in first-seen order
+	for _, source := range sources {
+		qaBranch := ""
+		if deploy, ok := deploys[source.RepoName]; ok {
+			branch := ""
+			if source.Trigger != nil {
+				branch = source.Trigger.Branch
+			}
+			qaBranch = qaBranchFor(deploy, branch)
+		}
+
+		round := slices.Index(qaBranches[source.RepoName], qaBranch)
+		if round < 0 {
+			qaBranches[source.RepoName] = append(qaBranches[source.RepoName], qaBranch)
+			round = len(qaBranches[source.RepoName]) - 1
+		}
+		if round == len(rounds) {
+			rounds = append(rounds, nil)
+		}
+		rounds[round] = append(rounds[round], source)
+	}
+	return rounds
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQABranchFor(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	deploy := &DeployConfig{
+		QARepoBranch: "main",
+		BranchMap: map[string]string{
+			"release/.*":   "release",
+			"release/1.0":  "release-1.0",
+			"hotfix/.*":    "hotfix",
+			"(hotfix|x)/a": "shadowed",
+		},
+	}
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"main", "main"},
+		{"release/2.0", "release"},
+		{"release/1.0", "release-1.0"}, // Exact keys win over patterns
+		{"hotfix/a", "shadowed"},       // Patterns are tried in sorted order
+		{"feature/release/1", "main"},  // Patterns match the whole branch name
+		{"", "main"},                   // Tag and manual triggers
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			if got := qaBranchFor(deploy, tt.branch); got != tt.want {
+				t.Errorf("qaBranchFor(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployRoutesTriggerBranchToQABranch(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "cat target.txt"}})
+	qaRepo := config.Repositories[0].Deploy.QARepoURL
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = qaRepo
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, output)
+		}
+	}
+	writeTarget := func(content string) {
+		if err := os.WriteFile(filepath.Join(qaRepo, "target.txt"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write target.txt: %v", err)
+		}
+	}
+	writeTarget("qa-main")
+	git("add", "target.txt")
+	git("commit", "-q", "-m", "main target")
+	git("checkout", "-q", "-b", "qa-release")
+	writeTarget("qa-release")
+	git("commit", "-q", "-am", "release target")
+	git("checkout", "-q", "main")
+
+	config.Repositories[0].Deploy.BranchMap = map[string]string{"release/.*": "qa-release"}
+	service := NewDeployService(config)
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"main", "qa-main"},
+		{"release/1.0", "qa-release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			trigger := &DeployTrigger{Branch: tt.branch, CommitInfo: CommitInfo{SHA: "abc123"}}
+			result := service.deployRepository("drain-repo", context.Background(), trigger)
+			if !result.Success {
+				t.Fatalf("deployRepository() failed: %s", result.Error)
+			}
+			if len(result.CommandOutputs) != 1 || strings.TrimSpace(result.CommandOutputs[0].Stdout) != tt.want {
+				t.Errorf("deployed checkout = %+v, want %s", result.CommandOutputs, tt.want)
+			}
+		})
+	}
+
+	// Routing works on a copy, leaving the configured default branch in place
+	if got := config.Repositories[0].Deploy.QARepoBranch; got != "main" {
+		t.Errorf("qa_repo_branch = %q after routing, want main", got)
+	}
+}
+
+func TestSplitTriggerRounds(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{Repositories: []RepositoryConfig{
+		{Name: "web-a", Deploy: DeployConfig{QARepoBranch: "main", BranchMap: map[string]string{"release/.*": "qa-release"}}},
+		{Name: "web-b", Deploy: DeployConfig{QARepoBranch: "main"}},
+	}}
+	source := func(repo, branch string) TriggerSource {
+		return TriggerSource{RepoName: repo, Branch: branch, Trigger: &DeployTrigger{Branch: branch}}
+	}
+
+	tests := []struct {
+		name    string
+		sources []TriggerSource
+		want    []string // repo:branch sources of each round
+	}{
+		{"no sources deploy once", nil, []string{""}},
+		{"same QA branch shares a deployment", []TriggerSource{source("web-a", "main"), source("web-a", "dev")}, []string{"web-a:main,web-a:dev"}},
+		{"different QA branches deploy separately", []TriggerSource{source("web-a", "release/1"), source("web-a", "main")}, []string{"web-a:release/1", "web-a:main"}},
+		{"members route independently", []TriggerSource{source("web-a", "release/1"), source("web-b", "main")}, []string{"web-a:release/1,web-b:main"}},
+		{"tag routes to qa_repo_branch", []TriggerSource{source("web-a", "main"), {RepoName: "web-a", Branch: tagRef("v.*"), Trigger: &DeployTrigger{}}}, []string{"web-a:main,web-a:tag:v.*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, round := range splitTriggerRounds(config, tt.sources) {
+				var names []string
+				for _, s := range round {
+					names = append(names, s.RepoName+":"+s.Branch)
+				}
+				got = append(got, strings.Join(names, ","))
+			}
+			if strings.Join(got, " | ") != strings.Join(tt.want, " | ") {
+				t.Errorf("splitTriggerRounds() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployChangesRoutesEachAllowedBranch(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name          string
+		repo          string
+		triggerBranch []string // group_trigger_branches of web-a
+		refs          []string
+		want          map[string]string // repo -> branch and QA checkout seen by each deployment
+	}{
+		{
+			name: "individual deploys once per QA branch",
+			repo: "solo",
+			refs: []string{"main", "release/1"},
+			want: map[string]string{"solo": "main@main release/1@qa-release"},
+		},
+		{
+			name: "group deploys once per QA branch",
+			repo: "web-a",
+			refs: []string{"release/1", "main"},
+			want: map[string]string{"web-a": "release/1@qa-release main@main", "web-b": "@main @main"},
+		},
+		{
+			name:          "ignored branch does not route",
+			repo:          "web-a",
+			triggerBranch: []string{"main"},
+			refs:          []string{"main", "release/1"},
+			want:          map[string]string{"web-a": "main@main", "web-b": "@main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, markers := newTriggerTestApp(t, "", "")
+			qaRepo := app.config.Repositories[0].Deploy.QARepoURL
+			if output, err := exec.Command("git", "-C", qaRepo, "branch", "qa-release").CombinedOutput(); err != nil {
+				t.Fatalf("git branch failed: %v (%s)", err, output)
+			}
+
+			var changed *RepositoryConfig
+			for i := range app.config.Repositories {
+				repo := &app.config.Repositories[i]
+				repo.Deploy.BranchMap = map[string]string{"release/.*": "qa-release"}
+				repo.Deploy.Commands = []CommandSpec{{Run: `echo "$SENTRY_BRANCH@$(git branch --show-current)" >> ` + filepath.Join(markers, repo.Name)}}
+				if repo.Name == tt.repo {
+					repo.GroupTriggerBranches = tt.triggerBranch
+					changed = repo
+				}
+			}
+
+			monitor := app.monitorService
+			for _, ref := range tt.refs {
+				monitor.setPendingTrigger(changed.Name, ref, DeployTrigger{Branch: ref, CommitInfo: CommitInfo{SHA: "sha-" + ref}})
+			}
+			if failures := monitor.deployChanges(context.Background(), []repoChange{{repo: changed, refs: tt.refs}}); len(failures) > 0 {
+				t.Fatalf("deployChanges() failures = %v", failures)
+			}
+
+			for _, repo := range []string{"solo", "web-a", "web-b"} {
+				data, _ := os.ReadFile(filepath.Join(markers, repo))
+				if got := strings.Join(strings.Fields(string(data)), " "); got != tt.want[repo] {
+					t.Errorf("%s deployments = %q, want %q", repo, got, tt.want[repo])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BranchBreaker suppresses deployments for repository branches that keep failing
+//
+// Failure counts are tracked per "repo:branch" so one broken branch does not block
+// the others. State is persisted to a file so a separate reset-breaker invocation
+// can clear a suppression held by a running watcher.
+type BranchBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	statePath string
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// breakerEntry holds the failure state of a single repo branch
+type breakerEntry struct {
+	Failures     int       `json:"failures"`
+	SuppressedAt time.Time `json:"suppressed_at,omitempty"`
+}
+
+// NewBranchBreaker creates a branch breaker from global configuration
+func NewBranchBreaker(config *Config) *BranchBreaker {
+	return &BranchBreaker{
+		threshold: config.Global.BreakerThreshold,
+		cooldown:  getBreakerCooldown(config),
+		statePath: getBreakerStatePath(config),
+		now:       time.Now,
+	}
+}
+
+// getBreakerCooldown returns the configured breaker cooldown or default
+func getBreakerCooldown(config *Config) time.Duration {
+	if config.Global.BreakerCooldown > 0 {
+		return time.Duration(config.Global.BreakerCooldown) * time.Second
+	}
+	return 30 * time.Minute
+}
+
+// getBreakerStatePath returns the configured breaker state file or default
+func getBreakerStatePath(config *Config) string {
+	if config.Global.BreakerStateFile != "" {
+		return config.Global.BreakerStateFile
+	}
+	tmpDir := config.Global.TmpDir
+	if tmpDir == "" {
+		tmpDir = "/tmp/sentry"
+	}
+	return filepath.Join(tmpDir, "sentry-breaker.json")
+}
+
+// breakerKey builds the state key for a repo branch
+func breakerKey(repoName string, branch string) string {
+	return fmt.Sprintf("%s:%s", repoName, branch)
+}
+
+// Enabled reports whether the breaker is configured
+func (b *BranchBreaker) Enabled() bool {
+	return b.threshold > 0
+}
+
+// Allow reports whether a deployment triggered by the given branch may run
+// A suppressed branch is allowed one retry after the cooldown has elapsed
+func (b *BranchBreaker) Allow(repoName string, branch string) bool {
+	if !b.Enabled() {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.load()
+	entry, exists := state[breakerKey(repoName, branch)]
+	if !exists || entry.SuppressedAt.IsZero() {
+		return true
+	}
+
+	return b.now().Sub(entry.SuppressedAt) >= b.cooldown
+}
+
+// RecordResult updates the failure count of a branch after a deployment
+func (b *BranchBreaker) RecordResult(repoName string, branch string, success bool) {
+	if !b.Enabled() {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.load()
+	key := breakerKey(repoName, branch)
+
+	if success {
+		if _, exists := state[key]; !exists {
+			return
+		}
+		delete(state, key)
+		AppLogger.InfoS("Branch deploy breaker closed", "repo", repoName, "branch", branch)
+	} else {
+		entry, exists := state[key]
+		if !exists {
+			entry = &breakerEntry{}
+			state[key] = entry
+		}
+		entry.Failures++
+
+		if entry.Failures >= b.threshold {
+			entry.SuppressedAt = b.now()
+			AppLogger.WarnS("Branch deploy breaker opened, suppressing deploys",
+				"repo", repoName,
+				"branch", branch,
+				"consecutive_failures", entry.Failures,
+				"cooldown", b.cooldown)
+		}
+	}
+
+	if err := b.save(state); err != nil {
+		AppLogger.WarnS("Failed to persist breaker state", "path", b.statePath, "error", err)
+	}
+}
+
+// Reset clears the failure state of a branch, or of every branch of the repo when branch is empty
+// It returns the number of entries cleared
+func (b *BranchBreaker) Reset(repoName string, branch string) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.load()
+	cleared := 0
+
+	if branch != "" {
+		if _, exists := state[breakerKey(repoName, branch)]; exists {
+			delete(state, breakerKey(repoName, branch))
+			cleared++
+		}
+	} else {
+		prefix := repoName + ":"
+		for key := range state {
+			if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+				delete(state, key)
+				cleared++
+			}
+		}
+	}
+
+	if cleared == 0 {
+		return 0, nil
+	}
+	return cleared, b.save(state)
+}
+
+// load reads breaker state from disk; missing or unreadable state starts empty
+func (b *BranchBreaker) load() map[string]*breakerEntry {
+	state := make(map[string]*breakerEntry)
+
+	data, err := os.ReadFile(b.statePath)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		AppLogger.WarnS("Ignoring corrupt breaker state", "path", b.statePath, "error", err)
+		return make(map[string]*breakerEntry)
+	}
+	return state
+}
+
+// save writes breaker state to disk atomically
+func (b *BranchBreaker) save(state map[string]*breakerEntry) error {
+	if err := os.MkdirAll(filepath.Dir(b.statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create breaker state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode breaker state: %w", err)
+	}
+
+	tmpPath := b.statePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write breaker state: %w", err)
+	}
+	return os.Rename(tmpPath, b.statePath)
+}
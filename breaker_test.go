@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(t *testing.T, threshold int) *BranchBreaker {
+	t.Helper()
+	config := &Config{
+		Global: GlobalConfig{
+			BreakerThreshold: threshold,
+			BreakerCooldown:  60,
+			BreakerStateFile: filepath.Join(t.TempDir(), "breaker.json"),
+		},
+	}
+	return NewBranchBreaker(config)
+}
+
+func TestBranchBreakerTripsPerBranch(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	breaker := newTestBreaker(t, 2)
+
+	breaker.RecordResult("app", "feature", false)
+	if !breaker.Allow("app", "feature") {
+		t.Fatal("Allow() should remain true below the failure threshold")
+	}
+
+	breaker.RecordResult("app", "feature", false)
+	if breaker.Allow("app", "feature") {
+		t.Error("Allow() should be false once the threshold is reached")
+	}
+
+	// Other branches of the same repo, and the same branch of other repos, are unaffected
+	if !breaker.Allow("app", "main") {
+		t.Error("Allow() should be true for an unrelated branch of the same repo")
+	}
+	if !breaker.Allow("other", "feature") {
+		t.Error("Allow() should be true for the same branch of another repo")
+	}
+}
+
+func TestBranchBreakerSuccessResetsCount(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	breaker := newTestBreaker(t, 2)
+
+	breaker.RecordResult("app", "main", false)
+	breaker.RecordResult("app", "main", true)
+	breaker.RecordResult("app", "main", false)
+
+	if !breaker.Allow("app", "main") {
+		t.Error("Allow() should be true; failures must be consecutive to trip the breaker")
+	}
+}
+
+func TestBranchBreakerCooldown(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	breaker := newTestBreaker(t, 1)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	breaker.RecordResult("app", "main", false)
+	if breaker.Allow("app", "main") {
+		t.Fatal("Allow() should be false right after tripping")
+	}
+
+	now = now.Add(61 * time.Second)
+	if !breaker.Allow("app", "main") {
+		t.Fatal("Allow() should be true once the cooldown has elapsed")
+	}
+
+	// A failed retry re-opens the breaker for another cooldown
+	breaker.RecordResult("app", "main", false)
+	if breaker.Allow("app", "main") {
+		t.Error("Allow() should be false after the retry fails")
+	}
+}
+
+func TestBranchBreakerReset(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	breaker := newTestBreaker(t, 1)
+
+	breaker.RecordResult("app", "main", false)
+	breaker.RecordResult("app", "release", false)
+
+	cleared, err := breaker.Reset("app", "main")
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if cleared != 1 {
+		t.Errorf("Reset() cleared = %d, want 1", cleared)
+	}
+	if !breaker.Allow("app", "main") {
+		t.Error("Allow() should be true after resetting the branch")
+	}
+	if breaker.Allow("app", "release") {
+		t.Error("Reset() of one branch must not clear another")
+	}
+
+	// Empty branch resets every branch of the repo
+	cleared, err = breaker.Reset("app", "")
+	if err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if cleared != 1 || !breaker.Allow("app", "release") {
+		t.Errorf("Reset() with empty branch cleared = %d, want release cleared", cleared)
+	}
+}
+
+func TestBranchBreakerSharedState(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// A reset from a separate process (another breaker on the same file) is seen by the watcher
+	watcher := newTestBreaker(t, 1)
+	resetter := &BranchBreaker{threshold: 1, cooldown: time.Minute, statePath: watcher.statePath, now: time.Now}
+
+	watcher.RecordResult("app", "main", false)
+	if resetter.Allow("app", "main") {
+		t.Fatal("Allow() should see suppression persisted by another breaker")
+	}
+
+	if _, err := resetter.Reset("app", "main"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if !watcher.Allow("app", "main") {
+		t.Error("Allow() should see a reset persisted by another breaker")
+	}
+}
+
+func TestBranchBreakerDisabled(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	breaker := newTestBreaker(t, 0)
+	for i := 0; i < 5; i++ {
+		breaker.RecordResult("app", "main", false)
+	}
+	if !breaker.Allow("app", "main") {
+		t.Error("Allow() should always be true when the breaker is disabled")
+	}
+}
+
+func TestMonitorAllowedTriggerSources(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{
+		Global: GlobalConfig{
+			BreakerThreshold: 1,
+			BreakerStateFile: filepath.Join(t.TempDir(), "breaker.json"),
+		},
+	}
+	monitor := NewMonitorService(config, nil)
+	repo := &RepositoryConfig{Name: "app"}
+
+	monitor.recordTriggerResults(context.Background(), []TriggerSource{{RepoName: "app", Branch: "feature"}}, false)
+
+	sources := monitor.allowedTriggerSources(repo, []string{"main", "feature"}, nil)
+	if len(sources) != 1 || sources[0].Branch != "main" {
+		t.Fatalf("allowedTriggerSources() = %+v, want only main", sources)
+	}
+
+	if _, err := monitor.ResetBreaker("app", "feature"); err != nil {
+		t.Fatalf("ResetBreaker() error = %v", err)
+	}
+	sources = monitor.allowedTriggerSources(repo, []string{"main", "feature"}, nil)
+	if len(sources) != 2 {
+		t.Errorf("allowedTriggerSources() after reset = %+v, want both branches", sources)
+	}
+}
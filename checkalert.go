@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Check alert events
+const (
+	checkAlertFailing   = "check_failing"
+	checkAlertRecovered = "check_recovered"
+)
+
+// CheckAlert announces a repository whose checks keep failing, or that recovered after alerting
+type CheckAlert struct {
+	Event       string `json:"event"` // check_failing or check_recovered
+	RepoName    string `json:"repo_name"`
+	DisplayName string `json:"display_name,omitempty"`
+	GroupName   string `json:"group_name,omitempty"`
+	Failures    int    `json:"consecutive_failures"` // Failed checks in a row, before the recovery for check_recovered
+	Error       string `json:"error,omitempty"`      // Latest check error; empty on recovery
+}
+
+// checkFailureTracker counts consecutive failed checks per repository
+type checkFailureTracker struct {
+	mu       sync.Mutex
+	failures map[string]int // repoName -> failed checks in a row
+}
+
+// newCheckFailureTracker creates a tracker with no failures recorded
+func newCheckFailureTracker() *checkFailureTracker {
+	return &checkFailureTracker{failures: make(map[string]int)}
+}
+
+// record counts a check outcome and returns the failures in a row before and after it
+func (t *checkFailureTracker) record(repoName string, failed bool) (before int, after int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	before = t.failures[repoName]
+	if failed {
+		t.failures[repoName] = before + 1
+	} else {
+		delete(t.failures, repoName)
+	}
+	return before, t.failures[repoName]
+}
+
+// recordCheckOutcome tracks a repository check and alerts when it crosses check_failure_threshold
+// The failing alert fires once when the threshold is reached; the recovery alert only follows a failing one.
+func (m *MonitorService) recordCheckOutcome(repo *RepositoryConfig, err error) {
+	before, after := m.checkFailures.record(repo.Name, err != nil)
+
+	threshold := m.currentConfig().Global.Notifications.CheckFailureThreshold
+	if threshold <= 0 || m.deployService == nil {
+		return
+	}
+
+	alert := &CheckAlert{
+		RepoName:    repo.Name,
+		DisplayName: repo.GetDisplayName(),
+		GroupName:   repo.Group,
+	}
+	switch {
+	case err != nil && after == threshold:
+		alert.Event = checkAlertFailing
+		alert.Failures = after
+		alert.Error = err.Error()
+		AppLogger.WarnS("Repository checks keep failing",
+			"repo", alert.DisplayName,
+			"consecutive_failures", after,
+			"error", err)
+	case err == nil && before >= threshold:
+		alert.Event = checkAlertRecovered
+		alert.Failures = before
+		AppLogger.InfoS("Repository checks recovered",
+			"repo", alert.DisplayName,
+			"consecutive_failures", before)
+	default:
+		return
+	}
+	m.deployService.notifyCheckAlert(alert)
+}
+
+// formatCheckAlertSlackMessage renders a check alert for Slack
+func formatCheckAlertSlackMessage(alert *CheckAlert) string {
+	if alert.Event == checkAlertRecovered {
+		return fmt.Sprintf(":white_check_mark: Repository checks recovered: *%s*\nAfter %d consecutive failures", alert.DisplayName, alert.Failures)
+	}
+	return fmt.Sprintf(":warning: Repository checks failing: *%s*\nConsecutive failures: %d\nError: %s", alert.DisplayName, alert.Failures, alert.Error)
+}
+
+// notifyCheckAlert delivers a check alert to the Slack webhook and to failure-notified targets in scope
+// Target templates describe deployment results, so targets get the default Slack text or the alert as JSON.
+func (d *DeployService) notifyCheckAlert(alert *CheckAlert) {
+	notifications := &d.currentConfig().Global.Notifications
+	text := formatCheckAlertSlackMessage(alert)
+
+	if notifications.SlackWebhookURL != "" {
+		d.postSlack(text, "repo", alert.RepoName)
+	}
+
+	for i := range notifications.Targets {
+		target := &notifications.Targets[i]
+		if (target.OnFailure != nil && !*target.OnFailure) || !target.inScope(alert.RepoName, alert.GroupName) {
+			continue
+		}
+
+		var payload interface{} = alert
+		if target.Type == notificationTypeSlack {
+			payload = slackMessage{Text: text}
+		}
+		body, err := json.Marshal(payload)
+		if err == nil {
+			err = d.postBody(target.URL, "application/json", body)
+		}
+		if err != nil {
+			AppLogger.WarnS("Failed to send check alert",
+				"repo", alert.RepoName,
+				"target", target.Type,
+				"error", err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckFailureAlerts(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	slackServer, slackTexts := newSlackTestServer(t, http.StatusOK)
+	alerts := make(chan CheckAlert, 4)
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var alert CheckAlert
+		if err := json.Unmarshal(body, &alert); err != nil {
+			t.Errorf("check alert body is not JSON: %v", err)
+		}
+		alerts <- alert
+	}))
+	t.Cleanup(webhookServer.Close)
+
+	config := &Config{
+		Global: GlobalConfig{
+			Notifications: NotificationsConfig{
+				SlackWebhookURL:       slackServer.URL,
+				CheckFailureThreshold: 2,
+				Targets: []NotificationTarget{
+					{Type: notificationTypeGenericWebhook, URL: webhookServer.URL},
+					{Type: notificationTypeGenericWebhook, URL: webhookServer.URL, Repositories: []string{"other-repo"}},
+				},
+			},
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name:    "flaky-repo",
+				Monitor: MonitorConfig{RepoURL: "https://github.com/owner/flaky", RepoType: "github", Branches: []string{"main"}},
+			},
+		},
+	}
+
+	failing := true
+	monitor := NewMonitorService(config, NewDeployService(config))
+	monitor.retry = RetryConfig{}
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if failing {
+			return stubResponse(http.StatusUnauthorized, `{"message":"Bad credentials"}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"m","author":{"name":"a"}}}`), nil
+	})})
+
+	tests := []struct {
+		name      string
+		failing   bool
+		wantEvent string // "" when no alert is expected
+	}{
+		{"first failure stays quiet", true, ""},
+		{"threshold reached", true, checkAlertFailing},
+		{"still failing does not repeat", true, ""},
+		{"recovery", false, checkAlertRecovered},
+		{"healthy again stays quiet", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing = tt.failing
+			err := monitor.CheckAllRepositories(context.Background())
+			if (err != nil) != tt.failing {
+				t.Fatalf("CheckAllRepositories() error = %v, want failure %v", err, tt.failing)
+			}
+
+			if tt.wantEvent == "" {
+				if len(slackTexts) != 0 || len(alerts) != 0 {
+					t.Errorf("got %d Slack and %d webhook alerts, want none", len(slackTexts), len(alerts))
+				}
+				return
+			}
+
+			// Delivery is synchronous, so the alerts have arrived once the check returns
+			if len(slackTexts) != 1 || len(alerts) != 1 {
+				t.Fatalf("got %d Slack and %d webhook alerts, want one each (the other repository's target is out of scope)", len(slackTexts), len(alerts))
+			}
+			text, alert := <-slackTexts, <-alerts
+			if alert.Event != tt.wantEvent || alert.RepoName != "flaky-repo" {
+				t.Errorf("webhook alert = %+v, want %s for flaky-repo", alert, tt.wantEvent)
+			}
+			if tt.wantEvent == checkAlertFailing {
+				if alert.Failures != 2 || !strings.Contains(alert.Error, "401") {
+					t.Errorf("failing alert = %+v, want 2 failures with the 401 error", alert)
+				}
+				if !strings.Contains(text, "Repository checks failing: *flaky-repo*") {
+					t.Errorf("slack text = %q, want the failing message", text)
+				}
+			} else {
+				if alert.Failures != 3 || alert.Error != "" {
+					t.Errorf("recovery alert = %+v, want 3 failures and no error", alert)
+				}
+				if !strings.Contains(text, "Repository checks recovered: *flaky-repo*") {
+					t.Errorf("slack text = %q, want the recovery message", text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultCommandDenylist rejects obviously destructive commands when strict_commands is enabled
+var defaultCommandDenylist = []string{
+	`\brm\s+(-[a-zA-Z]+\s+)*-[a-zA-Z]*[rR][a-zA-Z]*\s+(-[a-zA-Z]+\s+)*(/|/\*|~|\$HOME)(\s|;|&|\||$)`, // rm -rf / (or ~)
+	`:\(\)\s*\{`,                                // fork bomb
+	`\bmkfs(\.[a-z0-9]+)?\s`,                    // formatting a filesystem
+	`\bdd\b.*\bof=/dev/(sd|hd|nvme|xvd|vd)`,     // overwriting a device
+	`>\s*/dev/(sd|hd|nvme|xvd|vd)`,              // redirecting onto a disk
+	`\bchmod\s+(-[a-zA-Z]+\s+)*0?777\s+/(\s|$)`, // opening up the root filesystem
+}
+
+// unexpandedVariablePattern matches ${...} left in a command after config loading
+var unexpandedVariablePattern = regexp.MustCompile(`\$\{[^}\s]*\}?`)
+
+// getCommandDenylist gets the configured command denylist or uses the default
+func getCommandDenylist(config *Config) []string {
+	if config.Global.CommandDenylist != nil {
+		return config.Global.CommandDenylist
+	}
+	return defaultCommandDenylist
+}
+
+// compileCommandDenylist compiles the denylist used by strict_commands, reporting invalid patterns
+func compileCommandDenylist(config *Config) ([]*regexp.Regexp, ValidationErrors) {
+	var errs ValidationErrors
+	var denylist []*regexp.Regexp
+
+	for i, pattern := range getCommandDenylist(config) {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			errs.add(fmt.Sprintf("global.command_denylist[%d]", i), "invalid regex '%s': %v", pattern, err)
+			continue
+		}
+		denylist = append(denylist, compiled)
+	}
+
+	return denylist, errs
+}
+
+// commandPolicy restricts the deploy and rollback commands validation accepts
+type commandPolicy struct {
+	denylist        []*regexp.Regexp // Patterns rejected when strict_commands is enabled
+	allowedBinaries []string         // Programs commands may run; empty allows any
+}
+
+// newCommandPolicy builds the configured command policy, or nil when commands are unrestricted
+func newCommandPolicy(config *Config) (*commandPolicy, ValidationErrors) {
+	var errs ValidationErrors
+	policy := &commandPolicy{allowedBinaries: config.Global.AllowedCommandBinaries}
+
+	if config.Global.StrictCommands {
+		var denylistErrs ValidationErrors
+		policy.denylist, denylistErrs = compileCommandDenylist(config)
+		errs = append(errs, denylistErrs...)
+	}
+	for i, binary := range policy.allowedBinaries {
+		if strings.TrimSpace(binary) == "" || strings.ContainsAny(binary, " \t") {
+			errs.add(fmt.Sprintf("global.allowed_command_binaries[%d]", i), "must be a program name or path, got: %q", binary)
+		}
+	}
+
+	if policy.denylist == nil && len(policy.allowedBinaries) == 0 {
+		return nil, errs
+	}
+	return policy, errs
+}
+
+// commandChainOperators are shell constructs that start another program besides the first one
+var commandChainOperators = []string{";", "&", "|", "`", "$(", "<(", ">(", "\n"}
+
+// checkAllowedCommand verifies command is a single invocation of an allowed program
+// Chaining, pipes, background jobs and command substitution are rejected even inside quotes,
+// since telling them apart from quoted text needs a full shell parser. An empty allowlist allows anything.
+func checkAllowedCommand(command string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, operator := range commandChainOperators {
+		if strings.Contains(command, operator) {
+			return fmt.Errorf("contains %q, which could run a program outside allowed_command_binaries", strings.TrimSpace(operator))
+		}
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	binary := strings.Trim(fields[0], `"'`)
+	for _, name := range allowed {
+		if binary == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("runs %s, which is not in allowed_command_binaries %v", binary, allowed)
+}
+
+// validateCommandPolicy rejects commands outside the allowlist or matching a denylist pattern
+func validateCommandPolicy(commands []CommandSpec, context string, policy *commandPolicy) ValidationErrors {
+	var errs ValidationErrors
+	if policy == nil {
+		return errs
+	}
+
+	for i, cmd := range commands {
+		if err := checkAllowedCommand(cmd.Run, policy.allowedBinaries); err != nil {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("%s[%d].run", context, i),
+				Message: err.Error(),
+				Hint:    "run one allowed program per command, or add the program to global.allowed_command_binaries",
+			})
+			continue
+		}
+		for _, pattern := range policy.denylist {
+			if pattern.MatchString(cmd.Run) {
+				errs = append(errs, ValidationError{
+					Path:    fmt.Sprintf("%s[%d].run", context, i),
+					Message: fmt.Sprintf("matches denied pattern '%s': %s", pattern, cmd.Run),
+					Hint:    "remove or narrow the command, or adjust global.command_denylist if it is intended",
+				})
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// commandPolicyWarnings warns about commands still containing ${...} when strict_commands is enabled
+// Such references were escaped or expanded from another variable and reach the shell unexpanded.
+func commandPolicyWarnings(config *Config) []string {
+	if !config.Global.StrictCommands {
+		return nil
+	}
+
+	var warnings []string
+	for _, repo := range config.Repositories {
+		for _, list := range []struct {
+			field    string
+			commands []CommandSpec
+		}{
+			{"commands", repo.Deploy.Commands},
+			{"rollback_commands", repo.Deploy.RollbackCommands},
+		} {
+			for i, cmd := range list.commands {
+				if ref := unexpandedVariablePattern.FindString(cmd.Run); ref != "" {
+					warnings = append(warnings, fmt.Sprintf("repository '%s': deploy.%s[%d] contains unexpanded variable %s",
+						repo.Name, list.field, i, ref))
+				}
+			}
+		}
+	}
+	return warnings
+}
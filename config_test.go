@@ -1,9 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestExpandEnvVars(t *testing.T) {
@@ -43,6 +51,31 @@ func TestExpandEnvVars(t *testing.T) {
 			input:    "github: ${GITHUB_TOKEN}, test: $TEST_VAR",
 			expected: "github: github_token_123, test: test_value",
 		},
+		{
+			name:     "set variable ignores default",
+			input:    "token: ${GITHUB_TOKEN:-fallback}",
+			expected: "token: github_token_123",
+		},
+		{
+			name:     "unset variable uses default",
+			input:    "branch: ${QA_BRANCH_UNSET:-main}",
+			expected: "branch: main",
+		},
+		{
+			name:     "unset variable with empty default",
+			input:    "branch: ${QA_BRANCH_UNSET:-}",
+			expected: "branch: ",
+		},
+		{
+			name:     "unset variable without default",
+			input:    "branch: ${QA_BRANCH_UNSET}",
+			expected: "branch: ",
+		},
+		{
+			name:     "default is not expanded again",
+			input:    "value: ${QA_BRANCH_UNSET:-$TEST_VAR}",
+			expected: "value: $TEST_VAR",
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,6 +88,230 @@ func TestExpandEnvVars(t *testing.T) {
 	}
 }
 
+func TestLoadConfigEnvFile(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, ".env.staging")
+	if err := os.WriteFile(envFile, []byte("SENTRY_TEST_ENV_FILE_TOKEN=staging-token\n"), 0644); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	t.Cleanup(func() { os.Unsetenv("SENTRY_TEST_ENV_FILE_TOKEN") })
+
+	configPath := filepath.Join(dir, "sentry.yaml")
+	configContent := `
+polling_interval: 60
+repositories:
+  - name: "env-repo"
+    monitor:
+      repo_url: "https://github.com/test/repo"
+      branches: ["main"]
+      repo_type: "github"
+      auth:
+        token: "${SENTRY_TEST_ENV_FILE_TOKEN}"
+    deploy:
+      qa_repo_url: "https://gitlab.com/qa/repo"
+      qa_repo_branch: "main"
+      repo_type: "gitlab"
+      auth:
+        token: "$SENTRY_TEST_ENV_FILE_TOKEN"
+      project_name: "env-project"
+      commands:
+        - "echo test"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath, ConfigLoadOptions{EnvFile: envFile})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := config.Repositories[0].Monitor.Auth.Token; got != "staging-token" {
+		t.Errorf("monitor token = %q, want value from env file", got)
+	}
+	if got := config.Repositories[0].Deploy.Auth.Token; got != "staging-token" {
+		t.Errorf("deploy token = %q, want value from env file", got)
+	}
+
+	// A missing requested env file is reported but does not hide config errors
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml"), ConfigLoadOptions{EnvFile: filepath.Join(dir, ".env.missing")}); err == nil {
+		t.Error("LoadConfig() expected error for missing config file")
+	}
+}
+
+func TestExpandEnvVarsChecked(t *testing.T) {
+	os.Setenv("SENTRY_TEST_SET_VAR", "value")
+	os.Setenv("SENTRY_TEST_EMPTY_VAR", "")
+	defer os.Unsetenv("SENTRY_TEST_SET_VAR")
+	defer os.Unsetenv("SENTRY_TEST_EMPTY_VAR")
+
+	content := strings.Join([]string{
+		"branch: ${SENTRY_TEST_MISSING_DEFAULTED:-main}",
+		"token: ${SENTRY_TEST_MISSING_A}",
+		"user: $SENTRY_TEST_MISSING_B",
+		"again: ${SENTRY_TEST_MISSING_A}",
+		"set: ${SENTRY_TEST_SET_VAR}",
+		"empty: ${SENTRY_TEST_EMPTY_VAR}",
+		"defaulted: ${SENTRY_TEST_EMPTY_VAR:-fallback}",
+		"# slack: ${SENTRY_TEST_MISSING_COMMENT}",
+	}, "\n")
+
+	expanded, missing := expandEnvVarsChecked(content)
+
+	wantMissing := []string{"SENTRY_TEST_MISSING_A", "SENTRY_TEST_MISSING_B"}
+	if strings.Join(missing, ",") != strings.Join(wantMissing, ",") {
+		t.Errorf("expandEnvVarsChecked() missing = %v, want %v", missing, wantMissing)
+	}
+	if !strings.Contains(expanded, "set: value") || !strings.Contains(expanded, "token: \n") || !strings.Contains(expanded, "defaulted: fallback") {
+		t.Errorf("expandEnvVarsChecked() expanded = %q", expanded)
+	}
+}
+
+func TestLoadConfigUnsetEnvVars(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "sentry.yaml")
+	configContent := `
+polling_interval: 60
+repositories:
+  - name: "env-repo"
+    monitor:
+      repo_url: "https://github.com/test/repo"
+      branches: ["main"]
+      repo_type: "github"
+      auth:
+        token: "${SENTRY_TEST_UNSET_GITHUB_TOKEN}"
+    deploy:
+      qa_repo_url: "https://gitlab.com/qa/repo"
+      qa_repo_branch: "main"
+      repo_type: "gitlab"
+      auth:
+        token: "${SENTRY_TEST_UNSET_GITLAB_TOKEN}"
+      project_name: "env-project"
+      commands:
+        - "echo test"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Strict by default: every unset variable is listed in one error
+	_, err := LoadConfig(configPath, ConfigLoadOptions{})
+	if err == nil {
+		t.Fatal("LoadConfig() expected error for unset variables")
+	}
+	for _, want := range []string{"SENTRY_TEST_UNSET_GITHUB_TOKEN", "SENTRY_TEST_UNSET_GITLAB_TOKEN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig() error = %q, want it to name %s", err.Error(), want)
+		}
+	}
+
+	// Lenient mode keeps the old behavior: unset variables become empty and validation reports them
+	_, err = LoadConfig(configPath, ConfigLoadOptions{AllowUnsetEnv: true})
+	if err == nil || !strings.Contains(err.Error(), "repositories[0].monitor.auth.token: cannot be empty") {
+		t.Errorf("LoadConfig() lenient error = %v, want empty token validation error", err)
+	}
+}
+
+func TestInferRepoType(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		want    string
+	}{
+		{"https://github.com/owner/repo", "github"},
+		{"https://GitHub.com/owner/repo.git", "github"},
+		{"git@github.com:owner/repo.git", "github"},
+		{"https://github.company.com/owner/repo", ""}, // GitHub Enterprise needs repo_type and api_base_url
+		{"https://gitlab.com/group/project", "gitlab"},
+		{"https://gitlab-master.nvidia.com/qa/blueprint", "gitlab"},
+		{"ssh://git@gitlab.company.com:2222/group/project.git", "gitlab"},
+		{"https://codeberg.org/owner/repo", "gitea"},
+		{"https://gitea.company.com/owner/repo", "gitea"},
+		{"https://bitbucket.org/workspace/repo", "bitbucket"},
+		{"https://bitbucket.company.com/scm/proj/repo", ""},
+		{"https://git.company.com/owner/repo", ""},
+		{"/srv/git/qa.git", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoURL, func(t *testing.T) {
+			if got := inferRepoType(tt.repoURL); got != tt.want {
+				t.Errorf("inferRepoType(%q) = %q, want %q", tt.repoURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInfersRepoType(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	writeConfig := func(t *testing.T, monitorURL string, monitorType string) string {
+		configPath := filepath.Join(t.TempDir(), "sentry.yaml")
+		content := fmt.Sprintf(`
+polling_interval: 60
+repositories:
+  - name: "infer-repo"
+    monitor:
+      repo_url: %q
+      branches: ["main"]
+      repo_type: %q
+      auth:
+        token: "token"
+    deploy:
+      qa_repo_url: "https://gitlab.com/qa/repo"
+      qa_repo_branch: "main"
+      auth:
+        token: "token"
+      project_name: "infer-project"
+      commands:
+        - "echo test"
+`, monitorURL, monitorType)
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return configPath
+	}
+
+	tests := []struct {
+		name        string
+		monitorURL  string
+		monitorType string
+		wantMonitor string
+		wantErr     string
+	}{
+		{"github inferred", "https://github.com/owner/repo", "", "github", ""},
+		{"explicit type wins", "https://github.com/owner/repo", "git", "git", ""},
+		{"explicit type is validated", "https://github.com/owner/repo", "svn", "", "repositories[0].monitor.repo_type: must be"},
+		{"unknown host needs explicit type", "https://git.company.com/owner/repo", "", "",
+			`repositories[0].monitor.repo_type: cannot be inferred from repo_url "https://git.company.com/owner/repo"; please set repo_type`},
+		{"github enterprise host needs explicit type", "https://github.company.com/owner/repo", "", "",
+			`repositories[0].monitor.repo_type: cannot be inferred from repo_url "https://github.company.com/owner/repo"; please set repo_type`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := LoadConfig(writeConfig(t, tt.monitorURL, tt.monitorType), ConfigLoadOptions{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			repo := config.Repositories[0]
+			if repo.Monitor.RepoType != tt.wantMonitor {
+				t.Errorf("monitor repo_type = %q, want %q", repo.Monitor.RepoType, tt.wantMonitor)
+			}
+			if repo.Deploy.RepoType != "gitlab" {
+				t.Errorf("deploy repo_type = %q, want gitlab inferred from qa_repo_url", repo.Deploy.RepoType)
+			}
+		})
+	}
+}
+
 func TestValidateMonitorConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -76,6 +333,34 @@ func TestValidateMonitorConfig(t *testing.T) {
 			context: "test",
 			wantErr: false,
 		},
+		{
+			name: "valid branch regex pattern",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				Branches: []string{"main", "dev.*"},
+				RepoType: "github",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: false,
+		},
+		{
+			name: "invalid branch regex pattern",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				Branches: []string{"release-[0-9"},
+				RepoType: "github",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: true,
+		},
 		{
 			name: "empty repo URL",
 			monitor: MonitorConfig{
@@ -104,6 +389,34 @@ func TestValidateMonitorConfig(t *testing.T) {
 			context: "test",
 			wantErr: true,
 		},
+		{
+			name: "valid plain git repo type",
+			monitor: MonitorConfig{
+				RepoURL:  "https://git.internal.example.com/team/repo.git",
+				Branches: []string{"main"},
+				RepoType: "git",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: false,
+		},
+		{
+			name: "valid bitbucket repo type",
+			monitor: MonitorConfig{
+				RepoURL:  "https://bitbucket.org/workspace/repo",
+				Branches: []string{"main"},
+				RepoType: "bitbucket",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "app-password",
+				},
+			},
+			context: "test",
+			wantErr: false,
+		},
 		{
 			name: "invalid repo type",
 			monitor: MonitorConfig{
@@ -118,6 +431,49 @@ func TestValidateMonitorConfig(t *testing.T) {
 			context: "test",
 			wantErr: true,
 		},
+		{
+			name: "tags only on github",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				Tags:     []string{"v[0-9.]+"},
+				RepoType: "github",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: false,
+		},
+		{
+			name: "tags on unsupported provider",
+			monitor: MonitorConfig{
+				RepoURL:  "https://gitlab.com/owner/repo",
+				Branches: []string{"main"},
+				Tags:     []string{"v.*"},
+				RepoType: "gitlab",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: true,
+		},
+		{
+			name: "invalid tag regex pattern",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				Tags:     []string{"v[0-9"},
+				RepoType: "github",
+				Auth: AuthConfig{
+					Username: "user",
+					Token:    "token",
+				},
+			},
+			context: "test",
+			wantErr: true,
+		},
 		{
 			name: "empty token",
 			monitor: MonitorConfig{
@@ -132,6 +488,42 @@ func TestValidateMonitorConfig(t *testing.T) {
 			context: "test",
 			wantErr: true,
 		},
+		{
+			name: "github enterprise api base url",
+			monitor: MonitorConfig{
+				RepoURL:    "https://ghe.company.com/owner/repo",
+				Branches:   []string{"main"},
+				RepoType:   "github",
+				APIBaseURL: "https://ghe.company.com/api/v3",
+				Auth:       AuthConfig{Token: "token"},
+			},
+			context: "test",
+			wantErr: false,
+		},
+		{
+			name: "api base url without scheme",
+			monitor: MonitorConfig{
+				RepoURL:    "https://ghe.company.com/owner/repo",
+				Branches:   []string{"main"},
+				RepoType:   "github",
+				APIBaseURL: "ghe.company.com/api/v3",
+				Auth:       AuthConfig{Token: "token"},
+			},
+			context: "test",
+			wantErr: true,
+		},
+		{
+			name: "api base url on unsupported provider",
+			monitor: MonitorConfig{
+				RepoURL:    "https://gitea.example.com/owner/repo",
+				Branches:   []string{"main"},
+				RepoType:   "gitea",
+				APIBaseURL: "https://gitea.example.com/api/v1",
+				Auth:       AuthConfig{Token: "token"},
+			},
+			context: "test",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -177,7 +569,7 @@ func TestValidateConfig(t *testing.T) {
 						Token:    "token",
 					},
 					ProjectName: "test",
-					Commands:    []string{"echo test"},
+					Commands:    []CommandSpec{{Run: "echo test"}},
 				},
 			},
 		},
@@ -224,7 +616,7 @@ func TestValidateConfig(t *testing.T) {
 								Token:    "token",
 							},
 							ProjectName: "test",
-							Commands:    []string{"echo test"},
+							Commands:    []CommandSpec{{Run: "echo test"}},
 						},
 					},
 				},
@@ -265,7 +657,7 @@ func TestValidateConfig(t *testing.T) {
 								Token:    "token",
 							},
 							ProjectName: "test",
-							Commands:    []string{"echo test"},
+							Commands:    []CommandSpec{{Run: "echo test"}},
 						},
 					},
 				},
@@ -284,6 +676,261 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestValidateConfigCollectsAllErrors(t *testing.T) {
+	config := &Config{
+		PollingInterval: 30,
+		Repositories: []RepositoryConfig{
+			{
+				Name: "repo-a",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/test/repo-a",
+					RepoType: "svn",
+					Branches: []string{"main"},
+				},
+				Deploy: DeployConfig{
+					QARepoURL:    "https://github.com/test/qa",
+					QARepoBranch: "main",
+					RepoType:     "github",
+					Auth:         AuthConfig{Token: "token"},
+					ProjectName:  "Invalid_Name",
+					Commands:     []CommandSpec{{Run: "echo ok"}, {Run: " "}, {Run: "echo", Dir: "../escape", Shell: "/bin/bash -e"}},
+				},
+				Group:        "missing-group",
+				PollInterval: 30,
+			},
+		},
+		Groups: map[string]GroupConfig{
+			"bad-group": {ExecutionStrategy: "random", MaxParallel: 1, GlobalTimeout: 60},
+		},
+	}
+
+	err := validateConfig(config)
+	if err == nil {
+		t.Fatal("validateConfig() expected errors, got nil")
+	}
+
+	var validationErrs ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("validateConfig() error type = %T, want ValidationErrors", err)
+	}
+
+	wantPaths := []string{
+		"polling_interval",
+		"repositories[0].poll_interval",
+		"repositories[0].monitor.repo_type",
+		"repositories[0].monitor.auth.token",
+		"repositories[0].deploy.project_name",
+		"repositories[0].deploy.commands[1].run",
+		"repositories[0].deploy.commands[2].dir",
+		"repositories[0].deploy.commands[2].shell",
+		"repositories[0].group",
+		"groups.bad-group.execution_strategy",
+	}
+
+	gotPaths := make([]string, len(validationErrs))
+	for i, validationErr := range validationErrs {
+		gotPaths[i] = validationErr.Path
+	}
+	if strings.Join(gotPaths, ",") != strings.Join(wantPaths, ",") {
+		t.Errorf("validateConfig() paths = %v, want %v", gotPaths, wantPaths)
+	}
+
+	if !strings.Contains(err.Error(), "repositories[0].monitor.auth.token: cannot be empty") {
+		t.Errorf("validateConfig() error string = %q, want field path and message", err.Error())
+	}
+}
+
+func TestValidateGroupMaxParallel(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	members := []string{"repo-a", "repo-b"}
+	tests := []struct {
+		name        string
+		strategy    string
+		maxParallel int
+		wantErr     string
+	}{
+		{"parallel group running members together", "parallel", 2, ""},
+		{"parallel group limited to one", "parallel", 1, "deploys the members of a parallel group one at a time"},
+		{"sequential group", "sequential", 1, ""},
+		{"zero", "parallel", 0, "must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := GroupConfig{ExecutionStrategy: tt.strategy, MaxParallel: tt.maxParallel, GlobalTimeout: 60}
+			errs := validateGroupConfig(&group, "pipelines", members)
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("validateGroupConfig() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Path != "groups.pipelines.max_parallel" || !strings.Contains(errs[0].Message, tt.wantErr) {
+				t.Errorf("validateGroupConfig() = %v, want groups.pipelines.max_parallel containing %q", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGroupOrder(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	members := []string{"base", "dependent"}
+	tests := []struct {
+		name        string
+		strategy    string
+		order       []string
+		wantErrs    int
+		wantMessage string
+	}{
+		{"unset", "sequential", nil, 0, ""},
+		{"every member once", "sequential", []string{"dependent", "base"}, 0, ""},
+		{"missing member", "sequential", []string{"base"}, 1, "is missing group member dependent"},
+		{"unknown repository", "sequential", []string{"base", "dependent", "other"}, 1, "other, which is not a member"},
+		{"duplicate", "sequential", []string{"base", "base", "dependent"}, 1, "lists base more than once"},
+		{"parallel group", "parallel", []string{"base", "dependent"}, 1, "only applies to sequential groups"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := GroupConfig{ExecutionStrategy: tt.strategy, MaxParallel: 2, GlobalTimeout: 60, Order: tt.order}
+			errs := validateGroupConfig(&group, "ordered", members)
+			if len(errs) != tt.wantErrs {
+				t.Fatalf("validateGroupConfig() = %v, want %d errors", errs, tt.wantErrs)
+			}
+			if tt.wantErrs > 0 {
+				if errs[0].Path != "groups.ordered.order" || !strings.Contains(errs[0].Message, tt.wantMessage) {
+					t.Errorf("validateGroupConfig() error = %v, want groups.ordered.order containing %q", errs[0], tt.wantMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfigRetrySettings(t *testing.T) {
+	negative := -1
+	zero := 0
+
+	tests := []struct {
+		name      string
+		global    GlobalConfig
+		wantPaths []string
+	}{
+		{name: "unset", global: GlobalConfig{}},
+		{name: "zero retries", global: GlobalConfig{MaxRetries: &zero, RetryDelay: &zero}},
+		{name: "negative values", global: GlobalConfig{MaxRetries: &negative, RetryDelay: &negative}, wantPaths: []string{"global.max_retries", "global.retry_delay"}},
+		{name: "custom user agent", global: GlobalConfig{UserAgent: "Sentry-qa-team"}},
+		{name: "git binary on PATH", global: GlobalConfig{GitBinary: "git"}},
+		{name: "missing git binary", global: GlobalConfig{GitBinary: "/nonexistent/git"}, wantPaths: []string{"global.git_binary"}},
+		{name: "negative orphan temp max age", global: GlobalConfig{OrphanTempMaxAge: -1}, wantPaths: []string{"global.orphan_temp_max_age"}},
+		{name: "negative deploy lock wait", global: GlobalConfig{DeployLockWait: -1}, wantPaths: []string{"global.deploy_lock_wait"}},
+		{name: "negative config check interval", global: GlobalConfig{ConfigCheckInterval: -1}, wantPaths: []string{"global.config_check_interval"}},
+		{name: "proxy without scheme", global: GlobalConfig{Proxy: "proxy.corp:3128"}, wantPaths: []string{"global.proxy"}},
+		{name: "no_proxy without proxy", global: GlobalConfig{NoProxy: []string{".corp.example"}}, wantPaths: []string{"global.no_proxy"}},
+		{name: "missing ca cert file", global: GlobalConfig{CACertFile: "/nonexistent/ca.pem"}, wantPaths: []string{"global.ca_cert_file"}},
+		{name: "negative check failure threshold", global: GlobalConfig{Notifications: NotificationsConfig{CheckFailureThreshold: -1}}, wantPaths: []string{"global.notifications.check_failure_threshold"}},
+		{name: "negative repo deploy timeout", global: GlobalConfig{RepoDeployTimeout: -1}, wantPaths: []string{"global.repo_deploy_timeout"}},
+		{name: "multi-line user agent", global: GlobalConfig{UserAgent: "Sentry\r\nX-Injected: 1"}, wantPaths: []string{"global.user_agent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				PollingInterval: 300,
+				Global:          tt.global,
+				Repositories: []RepositoryConfig{
+					{
+						Name: "repo-a",
+						Monitor: MonitorConfig{
+							RepoURL:  "https://github.com/test/repo-a",
+							RepoType: "github",
+							Branches: []string{"main"},
+							Auth:     AuthConfig{Token: "token"},
+						},
+						Deploy: DeployConfig{
+							QARepoURL:    "https://github.com/test/qa",
+							QARepoBranch: "main",
+							RepoType:     "github",
+							Auth:         AuthConfig{Token: "token"},
+							ProjectName:  "repo-a",
+							Commands:     []CommandSpec{{Run: "echo ok"}},
+						},
+					},
+				},
+			}
+
+			var gotPaths []string
+			var validationErrs ValidationErrors
+			if err := validateConfig(config); errors.As(err, &validationErrs) {
+				for _, validationErr := range validationErrs {
+					gotPaths = append(gotPaths, validationErr.Path)
+				}
+			}
+			if strings.Join(gotPaths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validateConfig() paths = %v, want %v", gotPaths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       ValidationErrors
+		wantValid  bool
+		wantErrors int
+	}{
+		{
+			name:       "valid config",
+			errs:       nil,
+			wantValid:  true,
+			wantErrors: 0,
+		},
+		{
+			name: "multiple errors",
+			errs: ValidationErrors{
+				{Path: "polling_interval", Message: "must be at least 60 seconds"},
+				{Path: "repositories[0].deploy.qa_repo_url", Message: "cannot be empty"},
+			},
+			wantValid:  false,
+			wantErrors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeValidationReport(&buf, tt.errs, nil); err != nil {
+				t.Fatalf("writeValidationReport() error = %v", err)
+			}
+
+			var report struct {
+				Valid  bool `json:"valid"`
+				Errors []struct {
+					Path    string `json:"path"`
+					Message string `json:"message"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatalf("report is not valid JSON: %v\n%s", err, buf.String())
+			}
+
+			if report.Valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v", report.Valid, tt.wantValid)
+			}
+			if report.Errors == nil || len(report.Errors) != tt.wantErrors {
+				t.Errorf("errors = %v, want %d entries (never null)", report.Errors, tt.wantErrors)
+			}
+			if tt.wantErrors > 0 && report.Errors[1].Path != "repositories[0].deploy.qa_repo_url" {
+				t.Errorf("errors[1].path = %q", report.Errors[1].Path)
+			}
+		})
+	}
+}
+
 func TestGetConfigExample(t *testing.T) {
 	example := GetConfigExample()
 	if len(example) == 0 {
@@ -356,3 +1003,168 @@ func TestIsValidK8sName(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupTimeoutWarnings(t *testing.T) {
+	newConfig := func(strategy string, globalTimeout int) *Config {
+		return &Config{
+			Groups: map[string]GroupConfig{
+				"test-group": {
+					ExecutionStrategy: strategy,
+					MaxParallel:       2,
+					GlobalTimeout:     globalTimeout,
+				},
+			},
+			Repositories: []RepositoryConfig{
+				{
+					Name:  "repo-a",
+					Group: "test-group",
+					Deploy: DeployConfig{
+						Commands: []CommandSpec{
+							{Run: "kubectl apply -f ."},
+							{Run: "kubectl wait --for=condition=Ready pipeline/a --timeout=60s"},
+						},
+					},
+				},
+				{
+					Name:  "repo-b",
+					Group: "test-group",
+					Deploy: DeployConfig{
+						Commands: []CommandSpec{{Run: "kubectl wait --for=condition=Ready pipeline/b --timeout=2m"}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		config       *Config
+		wantWarnings int
+		wantContains string
+	}{
+		{
+			name:         "too small timeout",
+			config:       newConfig("parallel", 5),
+			wantWarnings: 2,
+			wantContains: "below the recommended minimum",
+		},
+		{
+			name:         "parallel covers slowest member",
+			config:       newConfig("parallel", 120),
+			wantWarnings: 0,
+		},
+		{
+			name:         "sequential needs sum of members",
+			config:       newConfig("sequential", 120),
+			wantWarnings: 1,
+			wantContains: "shorter than the 3m0s",
+		},
+		{
+			name:         "sequential with enough time",
+			config:       newConfig("sequential", 900),
+			wantWarnings: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := configWarnings(tt.config)
+			if len(warnings) != tt.wantWarnings {
+				t.Fatalf("configWarnings() returned %d warnings, want %d: %v", len(warnings), tt.wantWarnings, warnings)
+			}
+			if tt.wantContains != "" && !strings.Contains(strings.Join(warnings, "\n"), tt.wantContains) {
+				t.Errorf("configWarnings() = %v, want a warning containing %q", warnings, tt.wantContains)
+			}
+		})
+	}
+}
+
+func TestEstimateCommandsDuration(t *testing.T) {
+	flagged := []CommandSpec{
+		{Run: "cd .tekton/rag"},
+		{Run: "kubectl wait --for=condition=Ready pipeline/rag-build --timeout=60s"},
+		{Run: "kubectl rollout status deploy/rag --timeout 5m"},
+		{Run: "helm upgrade --install rag . --timeout=30"},
+	}
+
+	tests := []struct {
+		name   string
+		deploy DeployConfig
+		want   time.Duration
+	}{
+		{"timeout flags", DeployConfig{Commands: flagged}, 60*time.Second + 5*time.Minute + 30*time.Second},
+		{"flag beyond the default command timeout", DeployConfig{Commands: []CommandSpec{{Run: "kubectl wait --timeout=1h"}}}, defaultCommandTimeout * time.Second},
+		{"command timeout", DeployConfig{Commands: []CommandSpec{{Run: "make deploy", Timeout: 900}, {Run: "cd ."}}}, 900 * time.Second},
+		{"deploy command_timeout", DeployConfig{CommandTimeout: 600, Commands: []CommandSpec{{Run: "make deploy"}, {Run: "make verify", Timeout: 120}}}, 720 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateCommandsDuration(&tt.deploy); got != tt.want {
+				t.Errorf("estimateCommandsDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommandTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		deploy   DeployConfig
+		spec     CommandSpec
+		expected time.Duration
+	}{
+		{name: "default", expected: 5 * time.Minute},
+		{name: "deploy-wide", deploy: DeployConfig{CommandTimeout: 30}, expected: 30 * time.Second},
+		{name: "command override", deploy: DeployConfig{CommandTimeout: 30}, spec: CommandSpec{Timeout: 600}, expected: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getCommandTimeout(&tt.deploy, &tt.spec); got != tt.expected {
+				t.Errorf("getCommandTimeout() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommandSpecUnmarshalYAML(t *testing.T) {
+	content := `
+commands:
+  - "kubectl apply -f ."
+  - run: "kubectl apply -f -"
+    stdin: |
+      apiVersion: v1
+      kind: ConfigMap
+  - run: "kubectl apply -f ."
+    dir: ".tekton/app"
+    shell: "/bin/bash"
+`
+
+	var deploy DeployConfig
+	if err := yaml.Unmarshal([]byte(content), &deploy); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	if len(deploy.Commands) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(deploy.Commands))
+	}
+
+	if deploy.Commands[0].Run != "kubectl apply -f ." || deploy.Commands[0].Stdin != "" {
+		t.Errorf("plain string command = %+v", deploy.Commands[0])
+	}
+
+	if deploy.Commands[1].Run != "kubectl apply -f -" {
+		t.Errorf("structured command run = %v", deploy.Commands[1].Run)
+	}
+	if !strings.Contains(deploy.Commands[1].Stdin, "kind: ConfigMap") {
+		t.Errorf("structured command stdin = %v", deploy.Commands[1].Stdin)
+	}
+
+	if deploy.Commands[0].Dir != "" || deploy.Commands[0].ShellPath() != "/bin/sh" {
+		t.Errorf("plain string command dir/shell = %q/%q, want defaults", deploy.Commands[0].Dir, deploy.Commands[0].ShellPath())
+	}
+	if deploy.Commands[2].Dir != ".tekton/app" || deploy.Commands[2].ShellPath() != "/bin/bash" {
+		t.Errorf("structured command dir/shell = %q/%q", deploy.Commands[2].Dir, deploy.Commands[2].ShellPath())
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// deployCooldown tracks the last successful deployment of each repository
+type deployCooldown struct {
+	mu       sync.Mutex
+	period   time.Duration
+	deployed map[string]time.Time // repoName -> when its last successful deployment finished
+	now      func() time.Time
+}
+
+// newDeployCooldown creates a tracker that skips deployments within period of the last success
+func newDeployCooldown(period time.Duration) *deployCooldown {
+	return &deployCooldown{
+		period:   period,
+		deployed: make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// getDeployCooldown gets the minimum time between deployments of one repository (0 disables)
+func getDeployCooldown(config *Config) time.Duration {
+	return time.Duration(config.Global.DeployCooldown) * time.Second
+}
+
+// remaining returns how long until repoName may deploy again, 0 when it may deploy now
+func (c *deployCooldown) remaining(repoName string) time.Duration {
+	if c.period <= 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, ok := c.deployed[repoName]
+	if !ok {
+		return 0
+	}
+	if wait := c.period - c.now().Sub(last); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// recordSuccess starts the cooldown of repoName
+func (c *deployCooldown) recordSuccess(repoName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deployed[repoName] = c.now()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDeployRepositoryCooldown checks a repository deployed within deploy_cooldown is skipped until it elapses
+func TestDeployRepositoryCooldown(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "echo run >> " + runs}})
+	config.Global.DeployCooldown = 60
+
+	service := NewDeployService(config)
+	now := time.Now()
+	service.cooldown.now = func() time.Time { return now }
+
+	countRuns := func() int {
+		data, err := os.ReadFile(runs)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(data), "run")
+	}
+
+	steps := []struct {
+		name        string
+		advance     time.Duration
+		wantSkipped bool
+		wantRuns    int
+	}{
+		{"first deployment runs", 0, false, 1},
+		{"deployment within cooldown is skipped", 30 * time.Second, true, 1},
+		{"deployment after cooldown runs", 31 * time.Second, false, 2},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		result := service.deployRepository("drain-repo", context.Background(), nil)
+		if !result.Success {
+			t.Fatalf("%s: deployRepository() error = %s", step.name, result.Error)
+		}
+		if result.Skipped != step.wantSkipped {
+			t.Errorf("%s: skipped = %v, want %v", step.name, result.Skipped, step.wantSkipped)
+		}
+		if got := countRuns(); got != step.wantRuns {
+			t.Errorf("%s: commands ran %d times, want %d", step.name, got, step.wantRuns)
+		}
+	}
+}
+
+// TestDeployIndividualCooldownReportsSuccess checks a skipped deployment lets the monitor record the commit
+func TestDeployIndividualCooldownReportsSuccess(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+	config.Global.DeployCooldown = 3600
+	service := NewDeployService(config)
+	repo := &config.Repositories[0]
+
+	if err := service.DeployIndividual(context.Background(), repo, nil); err != nil {
+		t.Fatalf("DeployIndividual() error = %v", err)
+	}
+	if err := service.DeployIndividual(context.Background(), repo, nil); err != nil {
+		t.Fatalf("DeployIndividual() during cooldown error = %v, want nil", err)
+	}
+	if got := len(service.RecentDeployments()); got != 1 {
+		t.Errorf("history has %d entries, want 1 (skipped deployments are not recorded)", got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitCredentials returns the HTTPS username and password for auth on a repoType host
+// A configured username is used with the token as its password, which also covers hosts that take
+// a real account password. Without one, the provider's convention for bare tokens applies.
+func gitCredentials(repoType string, auth AuthConfig) (string, string) {
+	if auth.Username != "" {
+		return auth.Username, auth.Token
+	}
+	switch repoType {
+	case "github":
+		return auth.Token, "x-oauth-basic"
+	case "gitlab":
+		return "oauth2", auth.Token
+	case "bitbucket":
+		return "x-token-auth", auth.Token
+	default:
+		return "", auth.Token
+	}
+}
+
+// hasTokenUsername reports whether repoType hosts accept a bare token without a configured username
+func hasTokenUsername(repoType string) bool {
+	switch repoType {
+	case "github", "gitlab", "bitbucket":
+		return true
+	}
+	return false
+}
+
+// authenticatedURL embeds the credentials for auth on a repoType host into an HTTPS repository URL
+// Credentials are escaped, so passwords may contain characters such as '@' or ':'.
+func authenticatedURL(repoURL string, repoType string, auth AuthConfig) string {
+	rest, ok := strings.CutPrefix(repoURL, "https://")
+	if !ok {
+		return repoURL
+	}
+	username, password := gitCredentials(repoType, auth)
+	return fmt.Sprintf("https://%s@%s", url.UserPassword(username, password).String(), rest)
+}
+
+// registerAuthSecrets adds the credentials of auth to the log secret registry
+// The token is also masked as authenticatedURL escapes it, since git repeats the remote URL in its errors,
+// whether it is sent as a password or as the username of a bare-token convention.
+func registerAuthSecrets(auth AuthConfig) {
+	logSecrets.Register(auth.Token, auth.RefreshToken, auth.ClientSecret,
+		url.User(auth.Token).String(),
+		strings.TrimPrefix(url.UserPassword("", auth.Token).String(), ":"))
+}
+
+// setBitbucketAuth authenticates a Bitbucket API request: app passwords use basic auth with the
+// account username, while access tokens (no username) are sent as bearer tokens
+func setBitbucketAuth(req *http.Request, auth AuthConfig) {
+	if auth.Username == "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.Token))
+		return
+	}
+	req.SetBasicAuth(gitCredentials("bitbucket", auth))
+}
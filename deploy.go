@@ -1,29 +1,86 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 )
 
 // DeployService handles Tekton pipeline deployment
+// Deployments run concurrently (parallel groups, individual deploys, the status endpoint), so every
+// mutable field is guarded by its own lock; config, metrics and resultStore are only set before use.
 type DeployService struct {
-	config *Config
+	config        atomic.Pointer[Config] // Swapped on reload
+	resultStore   *ResultStore           // Optional SQLite deploy history (nil when disabled)
+	webhookClient *http.Client           // Shared client for webhooks, chat notifications and pipeline triggers
+	metrics       *Metrics               // Optional Prometheus metrics (nil when disabled)
+	shutdownGrace time.Duration          // How long in-flight commands may run after shutdown is requested
+	inflight      int                    // Deployments currently running
+	inflightMu    sync.Mutex             // Protects inflight and idle
+	idle          chan struct{}          // Closed when inflight drops to zero (nil when nobody waits)
+	history       *deploymentHistory     // Recent finalized deployment results
+	cooldown      *deployCooldown        // Last successful deployment per repository
+	kubectl       kubectlRunner          // Runs kubectl for the kube context guard and PipelineRun verification
+	retry         RetryConfig            // Retry behavior for transient QA repository clone failures
+
+	pipelineRunPollInterval time.Duration // How often PipelineRun verification checks the run status
 }
 
 // DeployResult represents the result of a deployment operation
 type DeployResult struct {
 	RepoName    string   `json:"repo_name"`
+	DisplayName string   `json:"display_name,omitempty"`
+	GroupName   string   `json:"group_name,omitempty"`
 	ClonePath   string   `json:"clone_path"`
 	CommandsRun []string `json:"commands_run"`
-	Success     bool     `json:"success"`
-	Error       string   `json:"error,omitempty"`
-	Duration    string   `json:"duration"`
+	RollbackRun []string `json:"rollback_run,omitempty"` // Rollback commands run after a failed command
+
+	CommandOutputs []CommandOutput `json:"command_outputs,omitempty"` // Output of each command run, in order
+
+	ValidationOutputs []CommandOutput `json:"validation_outputs,omitempty"` // Output of each pre_apply_validate dry run, in order
+
+	PipelineID  int64  `json:"pipeline_id,omitempty"`  // Pipeline created in gitlab_pipeline mode
+	PipelineURL string `json:"pipeline_url,omitempty"` // Web URL of that pipeline
+
+	PipelineRun string `json:"pipeline_run,omitempty"` // Tekton PipelineRun checked by verify_pipeline_run
+
+	CloneAttempts int `json:"clone_attempts,omitempty"` // git clone runs, more than 1 when transient failures were retried
+
+	Trigger *DeployTrigger `json:"trigger,omitempty"` // Monitored change that caused the deployment (nil for manual triggers)
+
+	RollbackError string `json:"rollback_error,omitempty"` // Rollback failures; never replaces Error
+	Success       bool   `json:"success"`
+	Skipped       bool   `json:"skipped,omitempty"` // Not run because the repository deployed within deploy_cooldown
+	Error         string `json:"error,omitempty"`
+	Duration      string `json:"duration"`
+}
+
+// CommandOutput is the captured output of one deployment command
+type CommandOutput struct {
+	Command  string `json:"command"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"` // -1 when the command was killed or did not exit normally
+}
+
+// combined returns stdout followed by stderr, for error messages and logs
+func (o CommandOutput) combined() string {
+	return o.Stdout + o.Stderr
 }
 
 // GroupDeployResult represents the result of a group deployment
@@ -37,15 +94,106 @@ type GroupDeployResult struct {
 
 // NewDeployService creates a new deploy service instance
 func NewDeployService(config *Config) *DeployService {
-	return &DeployService{
-		config: config,
+	d := &DeployService{
+		webhookClient: &http.Client{
+			Timeout:   getWebhookTimeout(config),
+			Transport: newHTTPTransport(config),
+		},
+		shutdownGrace: getShutdownGracePeriod(config),
+		history:       newDeploymentHistory(getHistorySize(config)),
+		cooldown:      newDeployCooldown(getDeployCooldown(config)),
+		kubectl:       execKubectl,
+		retry:         getRetryConfig(config),
+
+		pipelineRunPollInterval: defaultPipelineRunPollInterval,
+	}
+	d.config.Store(config)
+	return d
+}
+
+// currentConfig returns the configuration in effect, which a reload may replace at any time
+func (d *DeployService) currentConfig() *Config {
+	return d.config.Load()
+}
+
+// DeployTrigger is the monitored change that triggered a deployment
+type DeployTrigger struct {
+	Branch string `json:"branch,omitempty"` // Empty for tag triggers
+	CommitInfo
+}
+
+// deployTrigger returns the change that caused result's deployment, or the zero trigger for manual ones
+func (r *DeployResult) deployTrigger() DeployTrigger {
+	if r.Trigger == nil {
+		return DeployTrigger{}
+	}
+	return *r.Trigger
+}
+
+// SetMetrics enables Prometheus instrumentation of deployments
+func (d *DeployService) SetMetrics(metrics *Metrics) {
+	d.metrics = metrics
+}
+
+// EnableResultStore opens the SQLite result store so deploy results are persisted
+func (d *DeployService) EnableResultStore(dbPath string) error {
+	store, err := OpenResultStore(dbPath)
+	if err != nil {
+		return err
 	}
+	d.resultStore = store
+	return nil
+}
+
+// Close releases resources held by the deploy service
+func (d *DeployService) Close() error {
+	if d.resultStore != nil {
+		return d.resultStore.Close()
+	}
+	return nil
+}
+
+// RecentFailures returns failed repository deployments recorded since the given time
+func (d *DeployService) RecentFailures(since time.Time) ([]DeployRecord, error) {
+	if d.resultStore == nil {
+		return nil, fmt.Errorf("result store not configured (set global.db_path)")
+	}
+	return d.resultStore.RecentFailures(since)
+}
+
+// GroupFailures returns failed repository deployments for a group recorded since the given time
+func (d *DeployService) GroupFailures(groupName string, since time.Time) ([]DeployRecord, error) {
+	if d.resultStore == nil {
+		return nil, fmt.Errorf("result store not configured (set global.db_path)")
+	}
+	return d.resultStore.GroupFailures(groupName, since)
 }
 
 // DeployGroup deploys a group of repositories with specified strategy
-func (d *DeployService) DeployGroup(groupName string, repoNames []string, groupConfig *GroupConfig) error {
+// triggers holds the change of each member that changed; the others (and manual deployments) have none.
+// Cancelling ctx stops new work; running commands get the shutdown grace period to finish.
+func (d *DeployService) DeployGroup(ctx context.Context, groupName string, repoNames []string, groupConfig *GroupConfig, triggers map[string]*DeployTrigger) error {
+	_, err := d.deployGroup(ctx, groupName, repoNames, groupConfig, triggers)
+	return err
+}
+
+// deployGroup deploys a group like DeployGroup and also returns the finalized group result
+func (d *DeployService) deployGroup(ctx context.Context, groupName string, repoNames []string, groupConfig *GroupConfig, triggers map[string]*DeployTrigger) (*GroupDeployResult, error) {
 	startTime := time.Now()
 
+	unlock, err := d.lockDeployments()
+	if err != nil {
+		AppLogger.LogGroupDeploymentFailure(groupName, err)
+		return nil, fmt.Errorf("group deployment not started: %w", err)
+	}
+	defer unlock()
+
+	d.beginDeployment()
+	defer d.endDeployment()
+
+	ctx, cancel := drainContext(ctx, d.shutdownGrace)
+	defer cancel()
+
 	AppLogger.InfoS("Starting group deployment",
 		"group", groupName,
 		"strategy", groupConfig.ExecutionStrategy,
@@ -58,16 +206,18 @@ func (d *DeployService) DeployGroup(groupName string, repoNames []string, groupC
 		Strategy:  groupConfig.ExecutionStrategy,
 	}
 
-	var err error
 	if groupConfig.ExecutionStrategy == "parallel" {
-		err = d.deployGroupParallel(repoNames, groupConfig, groupResult)
+		err = d.deployGroupParallel(ctx, repoNames, groupConfig, triggers, groupResult)
 	} else {
-		err = d.deployGroupSequential(repoNames, groupConfig, groupResult)
+		err = d.deployGroupSequential(ctx, repoNames, groupConfig, triggers, groupResult)
 	}
 
 	groupResult.TotalTime = time.Since(startTime).String()
 	groupResult.Success = err == nil
 
+	d.recordGroupResult(groupResult, len(repoNames))
+	d.recordGroupHistory(groupResult)
+
 	// Log overall result
 	if groupResult.Success {
 		AppLogger.LogGroupDeploymentSuccess(groupName, len(repoNames), groupResult.TotalTime)
@@ -75,12 +225,14 @@ func (d *DeployService) DeployGroup(groupName string, repoNames []string, groupC
 		AppLogger.LogGroupDeploymentFailure(groupName, err)
 	}
 
-	return err
+	d.notifyGroupResult(groupResult)
+
+	return groupResult, err
 }
 
 // deployGroupParallel deploys repositories in parallel
-func (d *DeployService) deployGroupParallel(repoNames []string, groupConfig *GroupConfig, result *GroupDeployResult) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(groupConfig.GlobalTimeout)*time.Second)
+func (d *DeployService) deployGroupParallel(ctx context.Context, repoNames []string, groupConfig *GroupConfig, triggers map[string]*DeployTrigger, result *GroupDeployResult) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(groupConfig.GlobalTimeout)*time.Second)
 	defer cancel()
 
 	// Create semaphore to limit concurrent deployments
@@ -94,33 +246,19 @@ func (d *DeployService) deployGroupParallel(repoNames []string, groupConfig *Gro
 		go func(rn string) {
 			defer wg.Done()
 
-			// Acquire semaphore
-			select {
-			case semaphore <- struct{}{}:
-				defer func() { <-semaphore }()
-			case <-ctx.Done():
-				mu.Lock()
-				if firstError == nil {
-					firstError = fmt.Errorf("deployment timeout reached")
-				}
-				result.Results[rn] = &DeployResult{
-					RepoName: rn,
-					Success:  false,
-					Error:    "timeout",
-				}
-				mu.Unlock()
-				return
-			}
-
-			// Deploy the repository
-			repoResult := d.deployRepository(rn, ctx)
+			// Each goroutine produces exactly one result and records it under the lock
+			repoResult, timedOut := d.deployWithSemaphore(ctx, semaphore, rn, triggers[rn])
 
 			mu.Lock()
+			defer mu.Unlock()
 			result.Results[rn] = repoResult
 			if !repoResult.Success && firstError == nil && !groupConfig.ContinueOnError {
-				firstError = fmt.Errorf("deployment failed for %s: %s", rn, repoResult.Error)
+				if timedOut {
+					firstError = fmt.Errorf("deployment timeout reached")
+				} else {
+					firstError = fmt.Errorf("deployment failed for %s: %s", rn, repoResult.Error)
+				}
 			}
-			mu.Unlock()
 		}(repoName)
 	}
 
@@ -131,10 +269,16 @@ func (d *DeployService) deployGroupParallel(repoNames []string, groupConfig *Gro
 		return firstError
 	}
 
-	// Check if any deployments failed
+	// Check if any deployments failed, in a stable order
+	names := make([]string, 0, len(result.Results))
+	for repoName := range result.Results {
+		names = append(names, repoName)
+	}
+	sort.Strings(names)
+
 	var failures []string
-	for repoName, res := range result.Results {
-		if !res.Success {
+	for _, repoName := range names {
+		if res := result.Results[repoName]; !res.Success {
 			failures = append(failures, fmt.Sprintf("%s: %s", repoName, res.Error))
 		}
 	}
@@ -146,13 +290,46 @@ func (d *DeployService) deployGroupParallel(repoNames []string, groupConfig *Gro
 	return nil
 }
 
+// deployWithSemaphore waits for a deployment slot and deploys the repository,
+// returning a timeout result if the group deadline passes first
+func (d *DeployService) deployWithSemaphore(ctx context.Context, semaphore chan struct{}, repoName string, trigger *DeployTrigger) (*DeployResult, bool) {
+	select {
+	case semaphore <- struct{}{}:
+		defer func() { <-semaphore }()
+	case <-ctx.Done():
+		return timeoutDeployResult(repoName), true
+	}
+
+	// A slot and the deadline can become ready together; never start work after the deadline
+	if ctx.Err() != nil {
+		return timeoutDeployResult(repoName), true
+	}
+
+	return d.deployRepository(repoName, ctx, trigger), false
+}
+
+// timeoutDeployResult builds the result for a repository that never started before the group timeout
+func timeoutDeployResult(repoName string) *DeployResult {
+	return &DeployResult{
+		RepoName:    repoName,
+		CommandsRun: []string{},
+		Success:     false,
+		Error:       "timeout",
+		Duration:    "0s",
+	}
+}
+
 // deployGroupSequential deploys repositories sequentially
-func (d *DeployService) deployGroupSequential(repoNames []string, groupConfig *GroupConfig, result *GroupDeployResult) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(groupConfig.GlobalTimeout)*time.Second)
+func (d *DeployService) deployGroupSequential(ctx context.Context, repoNames []string, groupConfig *GroupConfig, triggers map[string]*DeployTrigger, result *GroupDeployResult) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(groupConfig.GlobalTimeout)*time.Second)
 	defer cancel()
 
-	for _, repoName := range repoNames {
-		repoResult := d.deployRepository(repoName, ctx)
+	for _, repoName := range sequentialDeployOrder(d.currentConfig(), repoNames, groupConfig) {
+		if shuttingDown(ctx) {
+			return fmt.Errorf("group deployment interrupted before %s: %w", repoName, ErrShuttingDown)
+		}
+
+		repoResult := d.deployRepository(repoName, ctx, triggers[repoName])
 		result.Results[repoName] = repoResult
 
 		if !repoResult.Success {
@@ -175,22 +352,73 @@ func (d *DeployService) deployGroupSequential(repoNames []string, groupConfig *G
 	return nil
 }
 
+// sequentialDeployOrder sorts repoNames by the group's order, or by declaration order in config when unset
+// Names found in neither keep their relative order after the others.
+func sequentialDeployOrder(config *Config, repoNames []string, groupConfig *GroupConfig) []string {
+	rank := make(map[string]int)
+	if len(groupConfig.Order) > 0 {
+		for i, name := range groupConfig.Order {
+			rank[name] = i
+		}
+	} else {
+		for i, repo := range config.Repositories {
+			rank[repo.Name] = i
+		}
+	}
+	position := func(name string) int {
+		if i, ok := rank[name]; ok {
+			return i
+		}
+		return len(rank)
+	}
+
+	ordered := slices.Clone(repoNames)
+	slices.SortStableFunc(ordered, func(a, b string) int { return position(a) - position(b) })
+	return ordered
+}
+
 // DeployIndividual deploys a single repository
-func (d *DeployService) DeployIndividual(repoConfig *RepositoryConfig) error {
-	ctx := context.Background()
-	result := d.deployRepository(repoConfig.Name, ctx)
+// trigger is the monitored change being deployed, nil for manual deployments.
+// Cancelling ctx stops new work; running commands get the shutdown grace period to finish.
+func (d *DeployService) DeployIndividual(ctx context.Context, repoConfig *RepositoryConfig, trigger *DeployTrigger) error {
+	_, err := d.deployIndividual(ctx, repoConfig, trigger)
+	return err
+}
+
+// deployIndividual deploys a repository like DeployIndividual and also returns the finalized result
+func (d *DeployService) deployIndividual(ctx context.Context, repoConfig *RepositoryConfig, trigger *DeployTrigger) (*DeployResult, error) {
+	unlock, err := d.lockDeployments()
+	if err != nil {
+		AppLogger.LogDeploymentFailure(repoConfig.GetDisplayName(), err)
+		return nil, fmt.Errorf("deployment not started: %w", err)
+	}
+	defer unlock()
+
+	d.beginDeployment()
+	defer d.endDeployment()
+
+	ctx, cancel := drainContext(ctx, d.shutdownGrace)
+	defer cancel()
+
+	result := d.deployRepository(repoConfig.Name, ctx, trigger)
+	if result.Skipped {
+		return result, nil
+	}
+	d.recordHistory(result)
+	d.notifyDeployResult(result)
 
 	if result.Success {
-		AppLogger.LogDeploymentSuccess(repoConfig.Name, len(result.CommandsRun))
-		return nil
+		AppLogger.LogDeploymentSuccess(repoConfig.GetDisplayName(), len(result.CommandsRun))
+		return result, nil
 	} else {
-		AppLogger.LogDeploymentFailure(repoConfig.Name, fmt.Errorf(result.Error))
-		return fmt.Errorf("deployment failed: %s", result.Error)
+		AppLogger.LogDeploymentFailure(repoConfig.GetDisplayName(), fmt.Errorf(result.Error))
+		return result, fmt.Errorf("deployment failed: %s", result.Error)
 	}
 }
 
 // deployRepository performs the actual deployment for a single repository
-func (d *DeployService) deployRepository(repoName string, ctx context.Context) *DeployResult {
+// A trigger routes the deployment through branch_map and is reported with the result.
+func (d *DeployService) deployRepository(repoName string, ctx context.Context, trigger *DeployTrigger) *DeployResult {
 	startTime := time.Now()
 	result := &DeployResult{
 		RepoName:    repoName,
@@ -198,11 +426,27 @@ func (d *DeployService) deployRepository(repoName string, ctx context.Context) *
 		Success:     false,
 	}
 
+	// Skip without recording anything when the repository deployed too recently
+	if wait := d.cooldown.remaining(repoName); wait > 0 {
+		AppLogger.InfoS("Skipping deployment during cooldown",
+			"repo", repoName,
+			"remaining", wait.Round(time.Second).String())
+		result.Success = true
+		result.Skipped = true
+		result.Duration = time.Since(startTime).String()
+		return result
+	}
+
+	// Persist and count the final result regardless of which path returns it
+	defer d.recordDeployResult(result)
+	defer d.metrics.RecordDeployment(result)
+
 	// Find repository configuration
 	var repoConfig *RepositoryConfig
-	for _, repo := range d.config.Repositories {
-		if repo.Name == repoName {
-			repoConfig = &repo
+	config := d.currentConfig()
+	for i := range config.Repositories {
+		if config.Repositories[i].Name == repoName {
+			repoConfig = &config.Repositories[i]
 			break
 		}
 	}
@@ -213,10 +457,56 @@ func (d *DeployService) deployRepository(repoName string, ctx context.Context) *
 		return result
 	}
 
-	AppLogger.InfoS("Starting repository deployment",
-		"repo", repoName,
-		"qa_repo", repoConfig.Deploy.QARepoURL,
-		"project", repoConfig.Deploy.ProjectName)
+	result.DisplayName = repoConfig.GetDisplayName()
+	result.GroupName = repoConfig.Group
+	if trigger != nil {
+		result.Trigger = trigger
+		repoConfig = routeQABranch(repoConfig, trigger.Branch)
+	}
+
+	// Notify the repository webhook and notification targets once the result is final
+	defer d.notifyTargets(result)
+	defer d.notifyDeployWebhook(repoConfig, result)
+
+	// Bound the whole deployment; rollback keeps ctx so it can still run once the deadline passed
+	timeout := getRepoDeployTimeout(config)
+	deployCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		// Only this budget is named; a group's global_timeout expiring first reports as before
+		if !result.Success && errors.Is(deployCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			result.Error = fmt.Sprintf("deployment timed out after %s (repo_deploy_timeout): %s", timeout, result.Error)
+		}
+	}()
+
+	if result.Trigger != nil {
+		AppLogger.InfoS("Starting repository deployment",
+			"repo", result.DisplayName,
+			"qa_repo", repoConfig.Deploy.QARepoURL,
+			"project", repoConfig.Deploy.ProjectName,
+			"branch", result.Trigger.Branch,
+			"sha", shortSHA(result.Trigger.SHA),
+			"author", result.Trigger.Author)
+	} else {
+		AppLogger.InfoS("Starting repository deployment",
+			"repo", result.DisplayName,
+			"qa_repo", repoConfig.Deploy.QARepoURL,
+			"project", repoConfig.Deploy.ProjectName)
+	}
+
+	// Hand the deployment to a GitLab pipeline instead of cloning and running commands
+	if getDeployMode(&repoConfig.Deploy) == deployModeGitLabPipeline {
+		if shuttingDown(ctx) {
+			result.Error = fmt.Sprintf("deployment not started: %v", ErrShuttingDown)
+		} else if err := d.triggerGitLabPipeline(deployCtx, repoConfig, result); err != nil {
+			result.Error = fmt.Sprintf("failed to trigger GitLab pipeline: %v", err)
+		} else {
+			result.Success = true
+			d.cooldown.recordSuccess(repoName)
+		}
+		result.Duration = time.Since(startTime).String()
+		return result
+	}
 
 	// Create temporary directory for cloning
 	tmpDir, err := d.createTempDirectory(repoName)
@@ -239,142 +529,588 @@ func (d *DeployService) deployRepository(repoName string, ctx context.Context) *
 	}()
 
 	// Clone QA repository
-	if err := d.cloneQARepository(repoConfig, tmpDir, ctx); err != nil {
+	if shuttingDown(ctx) {
+		result.Error = fmt.Sprintf("deployment not started: %v", ErrShuttingDown)
+		result.Duration = time.Since(startTime).String()
+		return result
+	}
+	attempts, err := d.cloneWithRetry(repoConfig, tmpDir, deployCtx)
+	result.CloneAttempts = attempts
+	if err != nil {
 		result.Error = fmt.Sprintf("failed to clone QA repository: %v", err)
 		result.Duration = time.Since(startTime).String()
 		return result
 	}
 
-	// Execute deployment commands
-	if err := d.executeDeploymentCommands(repoConfig, tmpDir, result, ctx); err != nil {
+	// Fail fast before any command can reach the wrong cluster
+	if err := d.prepareKubeconfig(deployCtx, repoConfig, tmpDir); err != nil {
+		result.Error = fmt.Sprintf("kube context guard failed: %v", err)
+		result.Duration = time.Since(startTime).String()
+		return result
+	}
+
+	templateData := newCommandTemplateData(repoConfig, result.deployTrigger())
+
+	// Substitute ${key} placeholders in manifests before any command applies them
+	if len(repoConfig.Deploy.Substitutions) > 0 {
+		if err := applySubstitutions(repoConfig, tmpDir, templateData); err != nil {
+			result.Error = fmt.Sprintf("failed to substitute manifest variables: %v", err)
+			result.Duration = time.Since(startTime).String()
+			return result
+		}
+	}
+
+	// Reject invalid manifests before anything is applied; there is nothing to roll back yet
+	if repoConfig.Deploy.PreApplyValidate {
+		if err := d.preApplyValidate(deployCtx, repoConfig, tmpDir, result); err != nil {
+			result.Error = fmt.Sprintf("pre-apply validation failed: %v", err)
+			result.Duration = time.Since(startTime).String()
+			return result
+		}
+	}
+
+	// Execute deployment commands, undoing a partial apply when one fails
+	commandsStart := time.Now()
+	if err := d.executeDeploymentCommands(repoConfig, tmpDir, result, deployCtx); err != nil {
 		result.Error = fmt.Sprintf("failed to execute commands: %v", err)
+		d.runRollbackCommands(repoConfig, tmpDir, result, ctx)
+		result.Duration = time.Since(startTime).String()
+		return result
+	}
+
+	// Confirm the Tekton PipelineRun the commands started actually succeeded
+	if err := d.verifyPipelineRun(deployCtx, repoConfig, tmpDir, commandsStart, result); err != nil {
+		result.Error = fmt.Sprintf("pipeline run verification failed: %v", err)
 		result.Duration = time.Since(startTime).String()
 		return result
 	}
 
 	result.Success = true
 	result.Duration = time.Since(startTime).String()
+	d.cooldown.recordSuccess(repoName)
 
 	AppLogger.InfoS("Repository deployment completed",
-		"repo", repoName,
+		"repo", result.DisplayName,
 		"duration", result.Duration,
 		"commands_executed", len(result.CommandsRun))
 
 	return result
 }
 
-// createTempDirectory creates a temporary directory for repository cloning
+// recordDeployResult persists a finalized repository deploy result when a store is configured
+func (d *DeployService) recordDeployResult(result *DeployResult) {
+	if d.resultStore == nil {
+		return
+	}
+
+	record := DeployRecord{
+		RepoName:  result.RepoName,
+		GroupName: result.GroupName,
+		Success:   result.Success,
+		Duration:  result.Duration,
+		Error:     result.Error,
+	}
+	if result.Trigger != nil {
+		record.CommitSHA = result.Trigger.SHA
+	}
+	if err := d.resultStore.RecordDeploy(record); err != nil {
+		AppLogger.WarnS("Failed to persist deploy result",
+			"repo", result.RepoName,
+			"error", err)
+	}
+}
+
+// recordGroupResult persists a finalized group deploy result when a store is configured
+func (d *DeployService) recordGroupResult(result *GroupDeployResult, repoCount int) {
+	if d.resultStore == nil {
+		return
+	}
+
+	record := GroupDeployRecord{
+		GroupName: result.GroupName,
+		Strategy:  result.Strategy,
+		Success:   result.Success,
+		TotalTime: result.TotalTime,
+		RepoCount: repoCount,
+	}
+	if err := d.resultStore.RecordGroupDeploy(record); err != nil {
+		AppLogger.WarnS("Failed to persist group deploy result",
+			"group", result.GroupName,
+			"error", err)
+	}
+}
+
+// createTempDirectory creates a new temporary directory for repository cloning
+// Each call gets its own "sentry-<repo>-<random digits>" directory, so concurrent deploys of
+// one repository never share or remove each other's workspace.
 func (d *DeployService) createTempDirectory(repoName string) (string, error) {
 	baseDir := d.getTempDir()
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create base temp directory: %w", err)
 	}
 
-	tmpDir := filepath.Join(baseDir, fmt.Sprintf("sentry-%s-%d", repoName, time.Now().Unix()))
-
-	// Remove existing directory if it exists
-	if _, err := os.Stat(tmpDir); err == nil {
-		if err := os.RemoveAll(tmpDir); err != nil {
-			return "", fmt.Errorf("failed to remove existing temp directory: %w", err)
-		}
-	}
-
-	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+	tmpDir, err := os.MkdirTemp(baseDir, fmt.Sprintf("sentry-%s-", repoName))
+	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
 
 	return tmpDir, nil
 }
 
-// cloneQARepository clones the QA repository
+// cloneWithRetry clones the QA repository, retrying transient failures (network errors and
+// timeouts) with the configured backoff; auth, missing branch and unknown failures fail at once.
+// It returns the number of clone attempts made.
+func (d *DeployService) cloneWithRetry(repoConfig *RepositoryConfig, destDir string, ctx context.Context) (int, error) {
+	retryConfig := d.retry
+
+	attempt := 0
+	for {
+		attempt++
+		err := d.cloneQARepository(repoConfig, destDir, ctx)
+		if err == nil {
+			return attempt, nil
+		}
+		if !errors.Is(err, ErrNetwork) || attempt > retryConfig.MaxRetries || ctx.Err() != nil {
+			if attempt > 1 {
+				return attempt, fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return attempt, err
+		}
+
+		delay := retryConfig.Backoff(attempt)
+		AppLogger.WarnS("Retrying QA repository clone",
+			"repo", repoConfig.GetDisplayName(),
+			"attempt", attempt,
+			"max_retries", retryConfig.MaxRetries,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(delay):
+		}
+
+		// A failed clone may leave a partial checkout that the next clone would refuse
+		if err := os.RemoveAll(destDir); err != nil {
+			return attempt, fmt.Errorf("failed to remove partial clone: %w", err)
+		}
+	}
+}
+
+// cloneQARepository clones the QA repository once
+// Failures are categorized from git's output; transient ones match ErrNetwork.
 func (d *DeployService) cloneQARepository(repoConfig *RepositoryConfig, destDir string, ctx context.Context) error {
+	timeout := getDeployTimeout(d.currentConfig())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	AppLogger.InfoS("Cloning QA repository",
-		"repo", repoConfig.Deploy.QARepoURL,
+		"repo", redactCredentials(repoConfig.Deploy.QARepoURL),
 		"branch", repoConfig.Deploy.QARepoBranch,
 		"dest", destDir)
 
+	auth, err := resolveAuth(ctx, d.webhookClient, repoConfig.Deploy.Auth, repoConfig.Deploy.QARepoURL, "")
+	if err != nil {
+		return err
+	}
+
+	gitBinary := getGitBinary(d.currentConfig())
 	var cmd *exec.Cmd
-	auth := repoConfig.Deploy.Auth
 
 	switch repoConfig.Deploy.RepoType {
 	case "github":
 		// For GitHub, use HTTPS with token authentication
-		cloneURL := strings.Replace(repoConfig.Deploy.QARepoURL, "https://", fmt.Sprintf("https://%s:%s@", auth.Username, auth.Token), 1)
-		cmd = exec.CommandContext(ctx, "git", "clone", "--branch", repoConfig.Deploy.QARepoBranch, "--single-branch", cloneURL, destDir)
+		cloneURL := authenticatedURL(repoConfig.Deploy.QARepoURL, repoConfig.Deploy.RepoType, auth)
+		cmd = exec.CommandContext(ctx, gitBinary, cloneArgs(&repoConfig.Deploy, cloneURL, destDir)...)
 
 	case "gitlab":
 		// For GitLab, use HTTPS with token authentication
-		cloneURL := strings.Replace(repoConfig.Deploy.QARepoURL, "https://", fmt.Sprintf("https://%s:%s@", auth.Username, auth.Token), 1)
-		cmd = exec.CommandContext(ctx, "git", "clone", "--branch", repoConfig.Deploy.QARepoBranch, "--single-branch", cloneURL, destDir)
+		cloneURL := authenticatedURL(repoConfig.Deploy.QARepoURL, repoConfig.Deploy.RepoType, auth)
+		cmd = exec.CommandContext(ctx, gitBinary, cloneArgs(&repoConfig.Deploy, cloneURL, destDir)...)
 
 	case "gitea":
 		// For Gitea, use HTTPS with token authentication
-		cloneURL := strings.Replace(repoConfig.Deploy.QARepoURL, "https://", fmt.Sprintf("https://%s:%s@", auth.Username, auth.Token), 1)
-		cmd = exec.CommandContext(ctx, "git", "clone", "--branch", repoConfig.Deploy.QARepoBranch, "--single-branch", cloneURL, destDir)
+		cloneURL := authenticatedURL(repoConfig.Deploy.QARepoURL, repoConfig.Deploy.RepoType, auth)
+		cmd = exec.CommandContext(ctx, gitBinary, cloneArgs(&repoConfig.Deploy, cloneURL, destDir)...)
+
+	case "bitbucket":
+		// For Bitbucket, use HTTPS with app-password authentication
+		cloneURL := authenticatedURL(repoConfig.Deploy.QARepoURL, repoConfig.Deploy.RepoType, auth)
+		cmd = exec.CommandContext(ctx, gitBinary, cloneArgs(&repoConfig.Deploy, cloneURL, destDir)...)
+
+	case "git":
+		// For plain git hosts, clone over HTTPS with the configured credentials
+		cloneURL := authenticatedURL(repoConfig.Deploy.QARepoURL, repoConfig.Deploy.RepoType, auth)
+		cmd = exec.CommandContext(ctx, gitBinary, cloneArgs(&repoConfig.Deploy, cloneURL, destDir)...)
 
 	default:
 		return fmt.Errorf("unsupported repository type: %s", repoConfig.Deploy.RepoType)
 	}
 
-	// Set environment variables to avoid interactive prompts
-	cmd.Env = append(os.Environ(),
-		"GIT_TERMINAL_PROMPT=0",
-		"GIT_ASKPASS=true")
+	// Avoid interactive prompts and trust the configured CA bundle
+	cmd.Env = gitCommandEnv(d.currentConfig())
+	cmd.WaitDelay = commandWaitDelay
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git clone failed: %w, output: %s", err, string(output))
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &networkError{err: fmt.Errorf("git clone timed out after %s (deploy_timeout)", timeout)}
+		}
+		// Git echoes the remote URL on failure, so scrub credentials before the error is logged
+		cloneErr := fmt.Errorf("git clone failed: %w, output: %s", err, redactCredentials(string(output), auth.Token))
+		switch category := classifyGitOutput(string(output)); category {
+		case nil:
+			return cloneErr
+		case ErrNetwork:
+			return &networkError{err: cloneErr}
+		default:
+			return fmt.Errorf("%w: %w", category, cloneErr)
+		}
 	}
 
-	AppLogger.InfoS("QA repository cloned successfully", "repo", repoConfig.Name)
+	// Drop the credentials git stored in .git/config so deploy commands cannot print them
+	resetRemote := exec.CommandContext(ctx, gitBinary, "-C", destDir, "remote", "set-url", "origin", repoConfig.Deploy.QARepoURL)
+	if output, err := resetRemote.CombinedOutput(); err != nil {
+		AppLogger.WarnS("Failed to remove credentials from cloned remote",
+			"repo", repoConfig.GetDisplayName(),
+			"error", redactCredentials(fmt.Sprintf("%v: %s", err, output), auth.Token))
+	}
+
+	AppLogger.InfoS("QA repository cloned successfully", "repo", repoConfig.GetDisplayName())
 	return nil
 }
 
+// urlCredentialsPattern matches the "user:password@" part of a URL
+var urlCredentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]*):[^/\s@]*@`)
+
+// redactCredentials masks URL passwords ("user:token@" becomes "user:***@") and any given
+// secrets wherever they appear in text such as git output or error messages
+func redactCredentials(text string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, "***")
+		}
+	}
+	return urlCredentialsPattern.ReplaceAllString(text, "${1}:***@")
+}
+
+// commandTemplateData is the data available to command templates at deploy time
+type commandTemplateData struct {
+	RepoName    string
+	DisplayName string
+	ProjectName string
+	CommitSHA   string // Monitored commit that triggered the deployment (empty for manual triggers)
+	Branch      string // Branch of that commit (empty for tag and manual triggers)
+}
+
+// newCommandTemplateData builds template data for a repository deployment
+func newCommandTemplateData(repoConfig *RepositoryConfig, trigger DeployTrigger) commandTemplateData {
+	return commandTemplateData{
+		RepoName:    repoConfig.Name,
+		DisplayName: repoConfig.GetDisplayName(),
+		ProjectName: repoConfig.Deploy.ProjectName,
+		CommitSHA:   trigger.SHA,
+		Branch:      trigger.Branch,
+	}
+}
+
+// renderCommandTemplate expands Go template placeholders such as {{.RepoName}} in command content
+func renderCommandTemplate(content string, data commandTemplateData) (string, error) {
+	tmpl, err := template.New("command").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// commandStrings returns the run strings of a command list for logging
+func commandStrings(commands []CommandSpec) []string {
+	runs := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		runs = append(runs, cmd.Run)
+	}
+	return runs
+}
+
 // executeDeploymentCommands executes the configured deployment commands
 func (d *DeployService) executeDeploymentCommands(repoConfig *RepositoryConfig, workDir string, result *DeployResult, ctx context.Context) error {
 	AppLogger.InfoS("Executing deployment commands",
-		"repo", repoConfig.Name,
-		"commands", repoConfig.Deploy.Commands)
+		"repo", repoConfig.GetDisplayName(),
+		"commands", commandStrings(repoConfig.Deploy.Commands))
+
+	templateData := newCommandTemplateData(repoConfig, result.deployTrigger())
+	allowed := d.currentConfig().Global.AllowedCommandBinaries
+
+	for i, spec := range repoConfig.Deploy.Commands {
+		cmdStr := spec.Run
+
+		// Let the running command finish on shutdown, but do not start the next one
+		if shuttingDown(ctx) {
+			return fmt.Errorf("step %d not started: %w", i+1, ErrShuttingDown)
+		}
+
+		// Validation already enforces the allowlist; recheck in case the config bypassed it
+		if err := checkAllowedCommand(cmdStr, allowed); err != nil {
+			return fmt.Errorf("step %d not started: command not allowed: %w", i+1, err)
+		}
 
-	for i, cmdStr := range repoConfig.Deploy.Commands {
 		AppLogger.InfoS("Executing command",
-			"repo", repoConfig.Name,
+			"repo", repoConfig.GetDisplayName(),
 			"step", i+1,
 			"command", cmdStr)
 
-		// Execute command with timeout
-		cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-		cmd := exec.CommandContext(cmdCtx, "/bin/sh", "-c", cmdStr)
-		cmd.Dir = workDir
-
-		// Set environment variables
-		cmd.Env = append(os.Environ(),
-			fmt.Sprintf("SENTRY_REPO=%s", repoConfig.Name),
-			fmt.Sprintf("SENTRY_PROJECT=%s", repoConfig.Deploy.ProjectName))
-
-		output, err := cmd.CombinedOutput()
-		cancel()
+		output, ran, err := runDeploymentCommand(ctx, repoConfig, workDir, &spec, templateData)
+		if !ran {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
 
 		result.CommandsRun = append(result.CommandsRun, cmdStr)
+		result.CommandOutputs = append(result.CommandOutputs, output)
 
 		if err != nil {
 			AppLogger.ErrorS("Command execution failed",
-				"repo", repoConfig.Name,
+				"repo", repoConfig.GetDisplayName(),
 				"step", i+1,
 				"command", cmdStr,
 				"error", err,
-				"output", string(output))
-			return fmt.Errorf("command failed (step %d): %s, error: %w, output: %s", i+1, cmdStr, err, string(output))
+				"output", output.combined())
+			return fmt.Errorf("command failed (step %d): %s, error: %w, output: %s", i+1, cmdStr, err, output.combined())
 		}
 
 		AppLogger.InfoS("Command executed successfully",
-			"repo", repoConfig.Name,
+			"repo", repoConfig.GetDisplayName(),
 			"step", i+1,
-			"output_size", len(output))
+			"output_size", len(output.Stdout)+len(output.Stderr))
 	}
 
 	return nil
 }
 
+// defaultCloneDepth is how many commits of the QA branch are cloned when clone_depth is unset
+const defaultCloneDepth = 1
+
+// getCloneDepth gets the QA repository clone depth, where 0 means full history
+func getCloneDepth(deploy *DeployConfig) int {
+	if deploy.CloneDepth != nil {
+		return *deploy.CloneDepth
+	}
+	return defaultCloneDepth
+}
+
+// cloneArgs builds the git clone arguments for the QA branch, shallow unless clone_depth is 0
+func cloneArgs(deploy *DeployConfig, cloneURL string, destDir string) []string {
+	args := []string{"clone", "--branch", deploy.QARepoBranch, "--single-branch"}
+	if depth := getCloneDepth(deploy); depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	args = append(args, deploy.CloneArgs...)
+	return append(args, cloneURL, destDir)
+}
+
+// runDeploymentCommand runs one command in the cloned QA repository under its timeout
+// ran is false when the command could not be prepared and never started.
+func runDeploymentCommand(ctx context.Context, repoConfig *RepositoryConfig, workDir string, spec *CommandSpec, templateData commandTemplateData) (output CommandOutput, ran bool, err error) {
+	output = CommandOutput{Command: spec.Run, ExitCode: -1}
+
+	cmdDir, err := commandDir(workDir, spec.Dir)
+	if err != nil {
+		return output, false, fmt.Errorf("command dir: %w", err)
+	}
+
+	// Feed expanded stdin content (e.g. a generated manifest for "kubectl apply -f -")
+	var stdin string
+	if spec.Stdin != "" {
+		if stdin, err = renderCommandTemplate(spec.Stdin, templateData); err != nil {
+			return output, false, fmt.Errorf("command stdin: %w", err)
+		}
+	}
+
+	// Execute command with timeout
+	timeout := getCommandTimeout(&repoConfig.Deploy, spec)
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd, err := newDeploymentCmd(cmdCtx, repoConfig, spec, workDir, cmdDir, templateData)
+	if err != nil {
+		return output, false, err
+	}
+	cmd.WaitDelay = commandWaitDelay
+	if spec.Stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+
+	// Keep only the tail of chatty commands so a verbose apply cannot exhaust memory
+	limit := getMaxOutputBytes(&repoConfig.Deploy)
+	stdout, stderr := newTailBuffer(limit), newTailBuffer(limit)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	output.Stdout = stdout.String()
+	output.Stderr = stderr.String()
+	if cmd.ProcessState != nil {
+		output.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s: %w", context.DeadlineExceeded, timeout, err)
+	}
+	return output, true, err
+}
+
+// runnerWorkDir is where the cloned QA repository is mounted inside a runner container
+const runnerWorkDir = "/work"
+
+// newDeploymentCmd builds the process for one command, on the host shell or inside deploy.runner_image
+func newDeploymentCmd(ctx context.Context, repoConfig *RepositoryConfig, spec *CommandSpec, workDir string, cmdDir string, data commandTemplateData) (*exec.Cmd, error) {
+	env := deploymentEnv(&repoConfig.Deploy, data)
+
+	image := repoConfig.Deploy.RunnerImage
+	if image == "" {
+		cmd := exec.CommandContext(ctx, spec.ShellPath(), "-c", spec.Run)
+		cmd.Dir = cmdDir
+		cmd.Env = append(os.Environ(), append(env, kubeGuardEnv(&repoConfig.Deploy, hostKubeconfigPath(workDir))...)...)
+		return cmd, nil
+	}
+
+	// Mount the clone and run the command from the same relative directory inside the container
+	relDir, err := filepath.Rel(workDir, cmdDir)
+	if err != nil {
+		return nil, fmt.Errorf("command dir: %w", err)
+	}
+	// A named container can be stopped on timeout; killing the docker CLI alone leaves it running
+	container := "sentry-" + newRequestID()
+	args := []string{"run", "--rm", "-i", "--init", "--name", container,
+		"-v", workDir + ":" + runnerWorkDir,
+		"-w", path.Join(runnerWorkDir, filepath.ToSlash(relDir))}
+	env = append(env, kubeGuardEnv(&repoConfig.Deploy, path.Join(runnerWorkDir, guardedKubeconfig))...)
+	for _, variable := range env {
+		args = append(args, "-e", variable)
+	}
+	args = append(args, image, spec.ShellPath(), "-c", spec.Run)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Dir = workDir
+	cmd.Cancel = func() error {
+		killRunnerContainer(container)
+		return cmd.Process.Kill()
+	}
+	return cmd, nil
+}
+
+// runnerKillTimeout bounds the docker kill issued when a runner_image command is cancelled
+const runnerKillTimeout = 30 * time.Second
+
+// killRunnerContainer kills a runner_image container whose command timed out or was cancelled
+// A container that already exited is not an error; other failures are logged since it may still be running.
+func killRunnerContainer(container string) {
+	ctx, cancel := context.WithTimeout(context.Background(), runnerKillTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "kill", container).CombinedOutput()
+	if err != nil && !strings.Contains(string(output), "No such container") {
+		AppLogger.WarnS("Failed to kill runner container",
+			"container", container,
+			"error", err,
+			"output", strings.TrimSpace(string(output)))
+	}
+}
+
+// deploymentEnv returns the variables every command gets: SENTRY_* context, then deploy.env in key order
+func deploymentEnv(deploy *DeployConfig, data commandTemplateData) []string {
+	env := []string{
+		fmt.Sprintf("SENTRY_REPO=%s", data.RepoName),
+		fmt.Sprintf("SENTRY_PROJECT=%s", data.ProjectName),
+		fmt.Sprintf("SENTRY_COMMIT_SHA=%s", data.CommitSHA),
+		fmt.Sprintf("SENTRY_BRANCH=%s", data.Branch),
+	}
+
+	keys := make([]string, 0, len(deploy.Env))
+	for key := range deploy.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", key, deploy.Env[key]))
+	}
+	return env
+}
+
+// runRollbackCommands runs the repository's rollback commands after a failed deployment
+// Every rollback command is attempted; failures are logged and recorded without replacing the deploy error.
+func (d *DeployService) runRollbackCommands(repoConfig *RepositoryConfig, workDir string, result *DeployResult, ctx context.Context) {
+	if len(repoConfig.Deploy.RollbackCommands) == 0 {
+		return
+	}
+	if shuttingDown(ctx) {
+		AppLogger.WarnS("Skipping rollback during shutdown", "repo", repoConfig.GetDisplayName())
+		return
+	}
+
+	AppLogger.WarnS("Running rollback commands",
+		"repo", repoConfig.GetDisplayName(),
+		"commands", commandStrings(repoConfig.Deploy.RollbackCommands))
+
+	templateData := newCommandTemplateData(repoConfig, result.deployTrigger())
+	allowed := d.currentConfig().Global.AllowedCommandBinaries
+
+	var failures []string
+	for i, spec := range repoConfig.Deploy.RollbackCommands {
+		if err := checkAllowedCommand(spec.Run, allowed); err != nil {
+			AppLogger.ErrorS("Rollback command not allowed",
+				"repo", repoConfig.GetDisplayName(),
+				"step", i+1,
+				"command", spec.Run,
+				"error", err)
+			failures = append(failures, fmt.Sprintf("rollback step %d: command not allowed: %v", i+1, err))
+			continue
+		}
+
+		output, ran, err := runDeploymentCommand(ctx, repoConfig, workDir, &spec, templateData)
+		if ran {
+			result.RollbackRun = append(result.RollbackRun, spec.Run)
+		}
+		if err != nil {
+			AppLogger.ErrorS("Rollback command failed",
+				"repo", repoConfig.GetDisplayName(),
+				"step", i+1,
+				"command", spec.Run,
+				"error", err,
+				"output", output.combined())
+			failures = append(failures, fmt.Sprintf("rollback step %d: %v", i+1, err))
+		}
+	}
+
+	result.RollbackError = strings.Join(failures, "; ")
+}
+
+// commandWaitDelay bounds how long a killed command's children may hold its output open
+const commandWaitDelay = 2 * time.Second
+
+// commandDir resolves a command's working directory inside the cloned QA repository
+func commandDir(workDir string, dir string) (string, error) {
+	if dir == "" {
+		return workDir, nil
+	}
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("%s is not a relative path inside the QA repository", dir)
+	}
+
+	path := filepath.Join(workDir, dir)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", dir)
+	}
+	return path, nil
+}
+
 // cleanupTempDirectory removes the temporary directory
 func (d *DeployService) cleanupTempDirectory(tmpDir string) error {
 	if tmpDir == "" || tmpDir == "/" {
@@ -385,15 +1121,76 @@ func (d *DeployService) cleanupTempDirectory(tmpDir string) error {
 	return os.RemoveAll(tmpDir)
 }
 
+// tempDirPattern matches the directory names created by createTempDirectory
+var tempDirPattern = regexp.MustCompile(`^sentry-.+-[0-9]+$`)
+
+// CleanupStale removes leftover temp directories from previous runs (e.g. after a crash)
+// Only Sentry's own directories older than the configured age are removed
+func (d *DeployService) CleanupStale() (int, error) {
+	if !d.shouldCleanup() {
+		return 0, nil
+	}
+
+	baseDir := d.getTempDir()
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-d.getOrphanTempMaxAge())
+	removed := 0
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !tempDirPattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(baseDir, entry.Name())
+		if err := d.cleanupTempDirectory(path); err != nil {
+			AppLogger.WarnS("Failed to remove orphaned temp directory",
+				"path", path,
+				"error", err)
+			continue
+		}
+
+		AppLogger.InfoS("Removed orphaned temp directory",
+			"path", path,
+			"modified", info.ModTime().Format(time.RFC3339))
+		removed++
+	}
+
+	if removed > 0 {
+		AppLogger.InfoS("Orphaned temp directory sweep completed", "removed", removed)
+	}
+
+	return removed, nil
+}
+
+// getOrphanTempMaxAge returns how old a temp directory must be before it is considered orphaned
+func (d *DeployService) getOrphanTempMaxAge() time.Duration {
+	if maxAge := d.currentConfig().Global.OrphanTempMaxAge; maxAge > 0 {
+		return time.Duration(maxAge) * time.Second
+	}
+	return 24 * time.Hour
+}
+
 // getTempDir returns the configured temp directory or default
 func (d *DeployService) getTempDir() string {
-	if d.config.Global.TmpDir != "" {
-		return d.config.Global.TmpDir
+	if tmpDir := d.currentConfig().Global.TmpDir; tmpDir != "" {
+		return tmpDir
 	}
 	return "/tmp/sentry"
 }
 
 // shouldCleanup returns whether to cleanup temp directories
 func (d *DeployService) shouldCleanup() bool {
-	return d.config.Global.Cleanup
+	return d.currentConfig().Global.Cleanup
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deployLockFileName is the lock file in tmp_dir that Sentry processes take while deploying
+const deployLockFileName = ".sentry-deploy.lock"
+
+// deployLockPollInterval is how often a waiting process retries a held deploy lock
+const deployLockPollInterval = 100 * time.Millisecond
+
+// ErrDeployLocked reports a deployment not started because another Sentry process is deploying
+var ErrDeployLocked = errors.New("another sentry process is deploying")
+
+// heldLock is a deploy lock file held by this process and the number of deployments sharing it
+type heldLock struct {
+	file    *os.File
+	holders int
+	ready   chan struct{} // Closed once the first holder took the flock or gave up
+	err     error         // Why the first holder gave up, set before ready is closed
+}
+
+// deployLock serializes deployments across processes with a flock on a file in tmp_dir
+// Deployments within one process share the lock: the first takes it and the last releases it.
+// The operating system drops the flock when the process exits, including on a signal.
+type deployLock struct {
+	mu   sync.Mutex
+	held map[string]*heldLock // Lock file path -> lock, including one still being acquired
+}
+
+// deployLocks is shared by every DeployService of the process
+var deployLocks = &deployLock{held: make(map[string]*heldLock)}
+
+// acquire takes the lock at path, retrying for up to wait while another process holds it
+// The mutex only guards held; the flock is polled without it, so other paths and releases are not
+// blocked. Deployments arriving while the lock is being acquired share the outcome of that attempt.
+func (l *deployLock) acquire(path string, wait time.Duration) error {
+	l.mu.Lock()
+	if lock, ok := l.held[path]; ok {
+		lock.holders++
+		l.mu.Unlock()
+		<-lock.ready
+		return lock.err
+	}
+	lock := &heldLock{holders: 1, ready: make(chan struct{})}
+	l.held[path] = lock
+	l.mu.Unlock()
+
+	file, err := lockFile(path, wait)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		delete(l.held, path)
+	}
+	lock.file, lock.err = file, err
+	close(lock.ready)
+	return err
+}
+
+// lockFile opens and flocks the lock file at path, retrying for up to wait while another process holds it
+func lockFile(path string, wait time.Duration) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create deploy lock directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open deploy lock: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w (lock %s held by pid %s); retry once it finishes or raise global.deploy_lock_wait",
+				ErrDeployLocked, path, lockHolder(path))
+		}
+		time.Sleep(deployLockPollInterval)
+	}
+
+	// Record the holder so a blocked process can name it
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	return file, nil
+}
+
+// release drops one deployment's share of the lock at path, unlocking it after the last
+func (l *deployLock) release(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, ok := l.held[path]
+	if !ok {
+		return
+	}
+	lock.holders--
+	if lock.holders > 0 {
+		return
+	}
+
+	delete(l.held, path)
+	if err := unlockFile(lock.file); err != nil {
+		AppLogger.WarnS("Failed to release deploy lock", "path", path, "error", err)
+	}
+	lock.file.Close()
+}
+
+// lockHolder returns the pid recorded in a deploy lock file, or "unknown"
+func lockHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return pid
+	}
+	return "unknown"
+}
+
+// getDeployLockWait gets how long a deployment waits for another process's deploy lock (default 0, fail at once)
+func getDeployLockWait(config *Config) time.Duration {
+	return time.Duration(config.Global.DeployLockWait) * time.Second
+}
+
+// lockDeployments takes the deploy lock in tmp_dir for one deployment and returns its release function
+func (d *DeployService) lockDeployments() (func(), error) {
+	path := filepath.Join(d.getTempDir(), deployLockFileName)
+	if err := deployLocks.acquire(path, getDeployLockWait(d.currentConfig())); err != nil {
+		return nil, err
+	}
+	return func() { deployLocks.release(path) }, nil
+}
@@ -0,0 +1,181 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// holdDeployLock flocks path through its own file, as another sentry process would
+func holdDeployLock(t *testing.T, path string) *os.File {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("failed to open lock file: %v", err)
+	}
+	if locked, err := tryLockFile(file); err != nil || !locked {
+		t.Fatalf("tryLockFile() = %v, %v, want the lock", locked, err)
+	}
+	file.WriteAt([]byte("4242"), 0)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestDeployLockAcquire(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name         string
+		wait         time.Duration
+		releaseAfter time.Duration // 0 keeps the other process's lock held
+		wantErr      bool
+	}{
+		{name: "fails fast while held", wait: 0, wantErr: true},
+		{name: "gives up after waiting", wait: 300 * time.Millisecond, wantErr: true},
+		{name: "blocks until released", wait: 5 * time.Second, releaseAfter: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), deployLockFileName)
+			other := holdDeployLock(t, path)
+			if tt.releaseAfter > 0 {
+				// Wait for the release before the cleanup closes the file under it
+				released := make(chan struct{})
+				time.AfterFunc(tt.releaseAfter, func() {
+					unlockFile(other)
+					close(released)
+				})
+				t.Cleanup(func() { <-released })
+			}
+
+			locks := &deployLock{held: make(map[string]*heldLock)}
+			start := time.Now()
+			err := locks.acquire(path, tt.wait)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("acquire() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrDeployLocked) || !strings.Contains(err.Error(), "pid 4242") {
+					t.Errorf("acquire() error = %v, want ErrDeployLocked naming the holder", err)
+				}
+				if elapsed := time.Since(start); elapsed < tt.wait {
+					t.Errorf("acquire() gave up after %v, want at least %v", elapsed, tt.wait)
+				}
+				return
+			}
+			defer locks.release(path)
+			if got := lockHolder(path); got != strconv.Itoa(os.Getpid()) {
+				t.Errorf("lock holder = %s, want this process", got)
+			}
+		})
+	}
+}
+
+func TestDeployLockSharedWithinProcess(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	path := filepath.Join(t.TempDir(), deployLockFileName)
+	locks := &deployLock{held: make(map[string]*heldLock)}
+
+	for i := 0; i < 2; i++ {
+		if err := locks.acquire(path, 0); err != nil {
+			t.Fatalf("acquire() %d error = %v, want deployments of one process to share the lock", i+1, err)
+		}
+	}
+
+	outsider, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatalf("failed to open lock file: %v", err)
+	}
+	defer outsider.Close()
+
+	locks.release(path)
+	if locked, _ := tryLockFile(outsider); locked {
+		t.Fatal("lock released while a deployment still holds it")
+	}
+	locks.release(path)
+	if locked, _ := tryLockFile(outsider); !locked {
+		t.Fatal("lock still held after the last deployment released it")
+	}
+}
+
+func TestDeployLockWaitDoesNotBlockOthers(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	dir := t.TempDir()
+	busy, free := filepath.Join(dir, "busy", deployLockFileName), filepath.Join(dir, "free", deployLockFileName)
+	if err := os.MkdirAll(filepath.Dir(busy), 0755); err != nil {
+		t.Fatalf("failed to create lock directory: %v", err)
+	}
+	other := holdDeployLock(t, busy)
+	locks := &deployLock{held: make(map[string]*heldLock)}
+
+	// Two deployments wait for the busy lock, which the first polls while the other shares its attempt
+	waited := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { waited <- locks.acquire(busy, 5*time.Second) }()
+	}
+
+	// Meanwhile a lock elsewhere is taken and released without waiting for the poll
+	time.Sleep(2 * deployLockPollInterval)
+	done := make(chan error, 1)
+	go func() {
+		err := locks.acquire(free, 0)
+		if err == nil {
+			locks.release(free)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("acquire(free) error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire(free) blocked behind a wait for another lock")
+	}
+
+	unlockFile(other)
+	for i := 0; i < 2; i++ {
+		if err := <-waited; err != nil {
+			t.Fatalf("acquire(busy) error = %v, want the lock once released", err)
+		}
+	}
+	if got := locks.held[busy].holders; got != 2 {
+		t.Errorf("busy lock holders = %d, want both waiting deployments", got)
+	}
+	locks.release(busy)
+	locks.release(busy)
+	if _, ok := locks.held[busy]; ok {
+		t.Error("busy lock still held after both deployments released it")
+	}
+}
+
+func TestDeployIndividualFailsWhileAnotherProcessDeploys(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "touch " + marker}})
+	holdDeployLock(t, filepath.Join(config.Global.TmpDir, deployLockFileName))
+	service := NewDeployService(config)
+
+	err := service.DeployIndividual(context.Background(), &config.Repositories[0], nil)
+	if !errors.Is(err, ErrDeployLocked) {
+		t.Fatalf("DeployIndividual() error = %v, want ErrDeployLocked", err)
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("deployment commands ran while another process held the lock")
+	}
+}
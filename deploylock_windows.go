@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// tryLockFile always succeeds: flock is not available, so deployments are not guarded on Windows
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile is a no-op on Windows
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// minGitVersion is the oldest git release Sentry is tested against
+var minGitVersion = [2]int{2, 18}
+
+// gitVersionPattern extracts the numeric version from "git --version" output
+var gitVersionPattern = regexp.MustCompile(`git version (\d+)\.(\d+)`)
+
+// shellBuiltins are command words that do not need to exist on PATH
+var shellBuiltins = map[string]bool{
+	"cd": true, "echo": true, "export": true, "set": true, "test": true, "[": true,
+	"true": true, "false": true, "exit": true, "source": true, ".": true,
+}
+
+// DoctorCheck is the outcome of a single environment check
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// DoctorReport is the machine-readable result of the doctor action
+type DoctorReport struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []DoctorCheck `json:"checks"`
+}
+
+// passCheck builds a passing check
+func passCheck(name string, format string, args ...interface{}) DoctorCheck {
+	return DoctorCheck{Name: name, Passed: true, Detail: fmt.Sprintf(format, args...)}
+}
+
+// failCheck builds a failing check
+func failCheck(name string, format string, args ...interface{}) DoctorCheck {
+	return DoctorCheck{Name: name, Passed: false, Detail: fmt.Sprintf(format, args...)}
+}
+
+// RunDoctor runs every non-destructive environment check and returns all results
+// A nil config (with configErr set) still runs the checks that do not depend on it.
+func RunDoctor(config *Config, configErr error) []DoctorCheck {
+	var checks []DoctorCheck
+
+	if configErr != nil {
+		checks = append(checks, failCheck("config", "%v", configErr))
+	} else {
+		checks = append(checks, passCheck("config", "configuration parsed and validated"))
+	}
+
+	checks = append(checks, checkGitVersion(getGitBinary(config)))
+
+	if config == nil {
+		return checks
+	}
+
+	checks = append(checks, checkTmpDirWritable(config))
+	checks = append(checks, checkCommandBinaries(config)...)
+
+	monitorService := NewMonitorService(config, nil)
+	for _, repo := range config.Repositories {
+		checks = append(checks, checkMonitorAccess(monitorService, &repo)...)
+		checks = append(checks, checkQARepoAccess(monitorService, &repo))
+	}
+
+	return checks
+}
+
+// checkGitVersion verifies gitBinary is installed and recent enough
+func checkGitVersion(gitBinary string) DoctorCheck {
+	output, err := exec.Command(gitBinary, "--version").Output()
+	if err != nil {
+		return failCheck("git", "%s is not installed or not on PATH: %v", gitBinary, err)
+	}
+
+	major, minor, err := parseGitVersion(string(output))
+	if err != nil {
+		return failCheck("git", "%v", err)
+	}
+
+	if major < minGitVersion[0] || (major == minGitVersion[0] && minor < minGitVersion[1]) {
+		return failCheck("git", "git %d.%d is older than the required %d.%d", major, minor, minGitVersion[0], minGitVersion[1])
+	}
+	return passCheck("git", "git %d.%d", major, minor)
+}
+
+// parseGitVersion extracts the major and minor version from "git --version" output
+func parseGitVersion(output string) (int, int, error) {
+	match := gitVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, 0, fmt.Errorf("unrecognized git version output: %q", strings.TrimSpace(output))
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return major, minor, nil
+}
+
+// checkTmpDirWritable verifies the configured tmp_dir can be created and written to
+func checkTmpDirWritable(config *Config) DoctorCheck {
+	tmpDir := config.Global.TmpDir
+	if tmpDir == "" {
+		tmpDir = "/tmp/sentry"
+	}
+
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return failCheck("tmp_dir", "cannot create %s: %v", tmpDir, err)
+	}
+
+	probe, err := os.CreateTemp(tmpDir, "doctor-*")
+	if err != nil {
+		return failCheck("tmp_dir", "%s is not writable: %v", tmpDir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return passCheck("tmp_dir", "%s is writable", tmpDir)
+}
+
+// checkCommandBinaries verifies the shell and every program invoked by deploy commands exist
+func checkCommandBinaries(config *Config) []DoctorCheck {
+	binaries := map[string]bool{"/bin/sh": true}
+	for _, repo := range config.Repositories {
+		for _, cmd := range repo.Deploy.Commands {
+			binaries[cmd.ShellPath()] = true
+			if binary := commandBinary(cmd.Run); binary != "" {
+				binaries[binary] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(binaries))
+	for name := range binaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]DoctorCheck, 0, len(names))
+	for _, name := range names {
+		checkName := fmt.Sprintf("binary %s", name)
+		if path, err := exec.LookPath(name); err != nil {
+			checks = append(checks, failCheck(checkName, "not found on PATH"))
+		} else {
+			checks = append(checks, passCheck(checkName, "%s", path))
+		}
+	}
+	return checks
+}
+
+// commandBinary returns the program a shell command line invokes, or "" for builtins
+func commandBinary(command string) string {
+	fields := strings.Fields(command)
+	for _, field := range fields {
+		// Skip leading VAR=value assignments
+		if strings.Contains(field, "=") && !strings.HasPrefix(field, "=") {
+			continue
+		}
+		if shellBuiltins[field] {
+			return ""
+		}
+		return field
+	}
+	return ""
+}
+
+// checkMonitorAccess verifies each monitored branch is reachable with the configured token
+func checkMonitorAccess(monitorService *MonitorService, repo *RepositoryConfig) []DoctorCheck {
+	checks := make([]DoctorCheck, 0, len(repo.Monitor.Branches))
+	for _, branch := range repo.Monitor.Branches {
+		name := fmt.Sprintf("monitor %s:%s", repo.GetDisplayName(), branch)
+		commit, err := monitorService.GetLatestCommit(&repo.Monitor, branch)
+		if err != nil {
+			checks = append(checks, failCheck(name, "%v", err))
+			continue
+		}
+		checks = append(checks, passCheck(name, "%s API reachable, token has read access (latest %s)",
+			repo.Monitor.RepoType, commit.SHA))
+	}
+	return checks
+}
+
+// checkQARepoAccess verifies the QA repository branch is cloneable without cloning it
+func checkQARepoAccess(monitorService *MonitorService, repo *RepositoryConfig) DoctorCheck {
+	name := fmt.Sprintf("qa repo %s", repo.GetDisplayName())
+
+	// Clones always go through git, so probe with git ls-remote regardless of provider
+	qaRepo := &MonitorConfig{
+		RepoURL:  repo.Deploy.QARepoURL,
+		RepoType: "git",
+		Auth:     repo.Deploy.Auth,
+	}
+	if _, err := monitorService.getGitLatestCommit(qaRepo, repo.Deploy.QARepoBranch); err != nil {
+		return failCheck(name, "%v", err)
+	}
+	return passCheck(name, "branch %s is cloneable", repo.Deploy.QARepoBranch)
+}
+
+// writeDoctorReport prints doctor results as text or JSON
+func writeDoctorReport(w io.Writer, checks []DoctorCheck, output string) error {
+	healthy := true
+	for _, check := range checks {
+		if !check.Passed {
+			healthy = false
+		}
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(DoctorReport{Healthy: healthy, Checks: checks})
+	}
+
+	for _, check := range checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		if _, err := fmt.Fprintf(w, "[%s] %-40s %s\n", status, check.Name, check.Detail); err != nil {
+			return err
+		}
+	}
+
+	summary := "All checks passed"
+	if !healthy {
+		summary = "Some checks failed"
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", summary)
+	return err
+}
+
+// runDoctor runs the doctor checks, prints the report and fails when any check failed
+func runDoctor(config *Config, configErr error, output string) error {
+	checks := RunDoctor(config, configErr)
+	if err := writeDoctorReport(os.Stdout, checks, output); err != nil {
+		return fmt.Errorf("failed to write doctor report: %w", err)
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if !check.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d doctor check(s) failed", failed)
+	}
+	return nil
+}
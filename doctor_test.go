@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	tests := []struct {
+		output    string
+		wantMajor int
+		wantMinor int
+		wantErr   bool
+	}{
+		{output: "git version 2.39.2\n", wantMajor: 2, wantMinor: 39},
+		{output: "git version 2.37.1 (Apple Git-137.1)", wantMajor: 2, wantMinor: 37},
+		{output: "git version 2.40.0.windows.1", wantMajor: 2, wantMinor: 40},
+		{output: "not git", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			major, minor, err := parseGitVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGitVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if major != tt.wantMajor || minor != tt.wantMinor {
+				t.Errorf("parseGitVersion() = %d.%d, want %d.%d", major, minor, tt.wantMajor, tt.wantMinor)
+			}
+		})
+	}
+}
+
+func TestCommandBinary(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{command: "kubectl apply -f .", want: "kubectl"},
+		{command: "cd .tekton/rag", want: ""},
+		{command: "KUBECONFIG=/tmp/kc kubectl get pods", want: "kubectl"},
+		{command: "   ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := commandBinary(tt.command); got != tt.want {
+				t.Errorf("commandBinary(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTmpDirWritable(t *testing.T) {
+	writable := &Config{Global: GlobalConfig{TmpDir: filepath.Join(t.TempDir(), "sentry")}}
+	if check := checkTmpDirWritable(writable); !check.Passed {
+		t.Errorf("checkTmpDirWritable() = %+v, want pass", check)
+	}
+
+	// A path below a regular file can never be created
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write blocker file: %v", err)
+	}
+	blocked := &Config{Global: GlobalConfig{TmpDir: filepath.Join(blocker, "sentry")}}
+	if check := checkTmpDirWritable(blocked); check.Passed {
+		t.Errorf("checkTmpDirWritable() = %+v, want fail", check)
+	}
+}
+
+func TestCheckCommandBinaries(t *testing.T) {
+	config := &Config{
+		Repositories: []RepositoryConfig{
+			{
+				Deploy: DeployConfig{
+					Commands: []CommandSpec{
+						{Run: "cd manifests"},
+						{Run: "sh -c true"},
+						{Run: "sentry-doctor-missing-binary apply"},
+					},
+				},
+			},
+		},
+	}
+
+	results := make(map[string]bool)
+	for _, check := range checkCommandBinaries(config) {
+		results[check.Name] = check.Passed
+	}
+
+	want := map[string]bool{
+		"binary /bin/sh":                      true,
+		"binary sh":                           true,
+		"binary sentry-doctor-missing-binary": false,
+	}
+	if len(results) != len(want) {
+		t.Errorf("checkCommandBinaries() = %v, want %v", results, want)
+	}
+	for name, passed := range want {
+		if got, ok := results[name]; !ok || got != passed {
+			t.Errorf("check %q passed = %v (present %v), want %v", name, got, ok, passed)
+		}
+	}
+}
+
+func TestRunDoctorWithoutConfig(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	checks := RunDoctor(nil, errors.New("config validation failed: polling_interval: must be positive"))
+
+	if len(checks) < 2 {
+		t.Fatalf("RunDoctor() returned %d checks, want config and git checks", len(checks))
+	}
+	if checks[0].Name != "config" || checks[0].Passed {
+		t.Errorf("config check = %+v, want failure", checks[0])
+	}
+	if checks[1].Name != "git" {
+		t.Errorf("second check = %q, want git to run despite the config failure", checks[1].Name)
+	}
+}
+
+func TestRunDoctorIndependentChecks(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	qaRepo := newLocalQARepo(t, map[string]string{"deploy.yaml": "kind: Pipeline\n"})
+
+	noRetries := 0
+	config := &Config{
+		Global: GlobalConfig{TmpDir: t.TempDir(), Timeout: 10, MaxRetries: &noRetries},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "broken",
+				Monitor: MonitorConfig{
+					RepoURL:  filepath.Join(t.TempDir(), "missing"),
+					RepoType: "git",
+					Branches: []string{"main"},
+				},
+				Deploy: DeployConfig{
+					QARepoURL:    qaRepo,
+					QARepoBranch: "main",
+					Commands:     []CommandSpec{{Run: "true"}},
+				},
+			},
+		},
+	}
+
+	checks := RunDoctor(config, nil)
+
+	status := make(map[string]bool)
+	for _, check := range checks {
+		status[check.Name] = check.Passed
+	}
+
+	if passed, ok := status["monitor broken:main"]; !ok || passed {
+		t.Errorf("monitor check should fail for a missing repository, got %v (present %v)", passed, ok)
+	}
+	// The failed monitor check must not prevent the QA repo check
+	if passed, ok := status["qa repo broken"]; !ok || !passed {
+		t.Errorf("qa repo check should pass for a local repository, got %v (present %v)", passed, ok)
+	}
+	if !status["tmp_dir"] {
+		t.Error("tmp_dir check should pass")
+	}
+
+	var buf bytes.Buffer
+	if err := writeDoctorReport(&buf, checks, "json"); err != nil {
+		t.Fatalf("writeDoctorReport() error = %v", err)
+	}
+	var report DoctorReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("doctor report is not valid JSON: %v", err)
+	}
+	if report.Healthy || len(report.Checks) != len(checks) {
+		t.Errorf("report healthy = %v with %d checks, want unhealthy with %d", report.Healthy, len(report.Checks), len(checks))
+	}
+
+	buf.Reset()
+	if err := writeDoctorReport(&buf, checks, "text"); err != nil {
+		t.Fatalf("writeDoctorReport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "[FAIL] monitor broken:main") || !strings.Contains(buf.String(), "Some checks failed") {
+		t.Errorf("text report missing failure lines:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultShutdownGracePeriod is how long (seconds) in-flight deployments may keep running after shutdown is requested
+const defaultShutdownGracePeriod = 120
+
+// ErrShuttingDown reports deployment work that was not started because shutdown was requested
+var ErrShuttingDown = errors.New("shutdown requested")
+
+// shutdownKey stores the shutdown signal of a drain context
+type shutdownKey struct{}
+
+// drainContext returns a context for running deployments under ctx.
+// Cancelling ctx does not interrupt a running command: the returned context is only
+// cancelled once grace has elapsed after ctx is done. Deployment steps check
+// shuttingDown before starting so no new work begins during the drain.
+func drainContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, cancel)
+	})
+
+	drainCtx = context.WithValue(drainCtx, shutdownKey{}, ctx.Done())
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// shuttingDown reports whether shutdown was requested for a context derived from drainContext
+func shuttingDown(ctx context.Context) bool {
+	done, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// getShutdownGracePeriod gets the shutdown grace period from global config or uses default
+func getShutdownGracePeriod(config *Config) time.Duration {
+	if config.Global.ShutdownGracePeriod > 0 {
+		return time.Duration(config.Global.ShutdownGracePeriod) * time.Second
+	}
+	return defaultShutdownGracePeriod * time.Second
+}
+
+// beginDeployment marks a deployment as in flight
+func (d *DeployService) beginDeployment() {
+	d.inflightMu.Lock()
+	defer d.inflightMu.Unlock()
+	d.inflight++
+}
+
+// endDeployment marks an in-flight deployment as finished, waking waiters when none remain
+func (d *DeployService) endDeployment() {
+	d.inflightMu.Lock()
+	defer d.inflightMu.Unlock()
+	d.inflight--
+	if d.inflight == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// WaitForDeployments waits until no deployment is in flight, returning false if timeout passes first
+func (d *DeployService) WaitForDeployments(timeout time.Duration) bool {
+	d.inflightMu.Lock()
+	if d.inflight == 0 {
+		d.inflightMu.Unlock()
+		return true
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.inflightMu.Unlock()
+
+	select {
+	case <-idle:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
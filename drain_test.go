@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrainContext(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := drainContext(parent, 100*time.Millisecond)
+	defer cancel()
+
+	if shuttingDown(ctx) {
+		t.Fatal("shuttingDown() = true before the parent is cancelled")
+	}
+
+	cancelParent()
+
+	if !shuttingDown(ctx) {
+		t.Error("shuttingDown() = false after the parent is cancelled")
+	}
+	if ctx.Err() != nil {
+		t.Error("drain context cancelled immediately, want it to survive the grace period")
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain context was not cancelled after the grace period")
+	}
+
+	// Contexts without a drain parent are never shutting down
+	if shuttingDown(context.Background()) {
+		t.Error("shuttingDown(context.Background()) = true")
+	}
+}
+
+// newDrainTestConfig returns a config deploying a local QA repository with the given commands
+func newDrainTestConfig(t *testing.T, commands []CommandSpec) *Config {
+	qaRepo := newLocalQARepo(t, map[string]string{"deploy.yaml": "kind: Pipeline\n"})
+	return &Config{
+		Global: GlobalConfig{
+			TmpDir:  t.TempDir(),
+			Cleanup: true,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "drain-repo",
+				Deploy: DeployConfig{
+					QARepoURL:    qaRepo,
+					QARepoBranch: "main",
+					RepoType:     "git",
+					ProjectName:  "drain",
+					Commands:     commands,
+				},
+			},
+		},
+	}
+}
+
+// waitForFile polls until path exists
+func waitForFile(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", path)
+}
+
+// assertTempDirsCleaned fails if any deployment temp directory remains under tmpDir
+func assertTempDirsCleaned(t *testing.T, tmpDir string) {
+	t.Helper()
+	leftovers, _ := filepath.Glob(filepath.Join(tmpDir, "sentry-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temp directories not cleaned up: %v", leftovers)
+	}
+}
+
+func TestDeployIndividualDrainsOnShutdown(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	markers := t.TempDir()
+	started := filepath.Join(markers, "started")
+	config := newDrainTestConfig(t, []CommandSpec{
+		{Run: "touch " + started + " && sleep 0.5 && touch " + filepath.Join(markers, "finished")},
+		{Run: "touch " + filepath.Join(markers, "second")},
+	})
+	service := NewDeployService(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- service.DeployIndividual(ctx, &config.Repositories[0], nil)
+	}()
+
+	// Request shutdown while the first command is running
+	waitForFile(t, started)
+	cancel()
+
+	var err error
+	select {
+	case err = <-errChan:
+	case <-time.After(10 * time.Second):
+		t.Fatal("DeployIndividual() did not return after shutdown")
+	}
+
+	if err == nil || !strings.Contains(err.Error(), ErrShuttingDown.Error()) {
+		t.Errorf("DeployIndividual() error = %v, want %q", err, ErrShuttingDown)
+	}
+	if _, statErr := os.Stat(filepath.Join(markers, "finished")); statErr != nil {
+		t.Error("running command was interrupted, want it to finish within the grace period")
+	}
+	if _, statErr := os.Stat(filepath.Join(markers, "second")); statErr == nil {
+		t.Error("next command started after shutdown was requested")
+	}
+	assertTempDirsCleaned(t, config.Global.TmpDir)
+}
+
+func TestDeployIndividualGracePeriodExpires(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	markers := t.TempDir()
+	started := filepath.Join(markers, "started")
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "touch " + started + " && sleep 30"}})
+	service := NewDeployService(config)
+	service.shutdownGrace = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- service.DeployIndividual(ctx, &config.Repositories[0], nil)
+	}()
+
+	waitForFile(t, started)
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Error("DeployIndividual() error = nil, want killed command failure")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("command was not killed after the grace period")
+	}
+	assertTempDirsCleaned(t, config.Global.TmpDir)
+}
+
+func TestDeployGroupSequentialStopsOnShutdown(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	service := NewDeployService(&Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	groupConfig := GroupConfig{ExecutionStrategy: "sequential", GlobalTimeout: 60, ContinueOnError: true}
+	err := service.DeployGroup(ctx, "drain-group", []string{"repo-a", "repo-b"}, &groupConfig, nil)
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("DeployGroup() error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestWaitForDeployments(t *testing.T) {
+	service := NewDeployService(&Config{})
+
+	if !service.WaitForDeployments(10 * time.Millisecond) {
+		t.Error("WaitForDeployments() = false with nothing in flight")
+	}
+
+	service.beginDeployment()
+	if service.WaitForDeployments(10 * time.Millisecond) {
+		t.Error("WaitForDeployments() = true while a deployment is in flight")
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		service.endDeployment()
+	}()
+	if !service.WaitForDeployments(5 * time.Second) {
+		t.Error("WaitForDeployments() = false after the deployment finished")
+	}
+}
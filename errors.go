@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Error categories for repository access failures, matched with errors.Is
+var (
+	ErrAuth           = errors.New("authentication failed")
+	ErrNetwork        = errors.New("network unreachable")
+	ErrBranchNotFound = errors.New("branch not found")
+	ErrRepoNotFound   = errors.New("repository not found or not accessible")
+)
+
+// ErrEmptyRepository reports a repository without any commits yet, e.g. one freshly created
+// Monitoring treats it as "nothing to deploy" rather than a failure.
+var ErrEmptyRepository = errors.New("repository has no commits")
+
+// Process exit codes reported by the validate action
+const (
+	exitCodeFailure        = 1
+	exitCodeAuth           = 3
+	exitCodeNetwork        = 4
+	exitCodeBranchNotFound = 5
+	exitCodeRepoNotFound   = 6
+)
+
+// APIError is a non-success HTTP response from a provider API
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RequestID  string // X-Request-ID sent with the request, when Sentry set one
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s API error (status %d, request %s): %s", e.Provider, e.StatusCode, e.RequestID, e.Body)
+	}
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Is classifies the response so callers can match ErrAuth, ErrBranchNotFound, ErrRepoNotFound or ErrEmptyRepository
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrBranchNotFound, ErrRepoNotFound:
+		return e.notFound() == target
+	case ErrEmptyRepository:
+		return e.emptyRepository()
+	}
+	return false
+}
+
+// emptyRepository reports whether the response is GitHub's or Gitea's 409 "Git Repository is empty."
+func (e *APIError) emptyRepository() bool {
+	if e.StatusCode != http.StatusConflict {
+		return false
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	return json.Unmarshal([]byte(e.Body), &body) == nil && strings.Contains(strings.ToLower(body.Message), "repository is empty")
+}
+
+// notFound tells which resource a response reports missing: ErrRepoNotFound, ErrBranchNotFound or nil
+// GitHub answers "Not Found" for a missing repository (or one the token cannot see) and
+// "No commit found for ..." for a missing ref; other 404s are treated as a missing branch.
+func (e *APIError) notFound() error {
+	if e.Provider == "gitHub" {
+		var body struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(e.Body), &body) == nil {
+			switch {
+			case strings.HasPrefix(body.Message, "No commit found"):
+				return ErrBranchNotFound
+			case e.StatusCode == http.StatusNotFound && body.Message == "Not Found":
+				return ErrRepoNotFound
+			}
+		}
+	}
+	if e.StatusCode == http.StatusNotFound {
+		return ErrBranchNotFound
+	}
+	return nil
+}
+
+// isClientError reports whether err is a 4xx API response, which retrying will not fix
+func isClientError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// networkError marks a transport failure while keeping the original message
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *networkError) Unwrap() []error {
+	return []error{e.err, ErrNetwork}
+}
+
+// classifyTransportError marks HTTP transport failures (DNS, refused connections, timeouts) as ErrNetwork
+func classifyTransportError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.Is(err, ErrNetwork) {
+		return &networkError{err: err}
+	}
+	return err
+}
+
+// gitOutputPatterns maps lowercase fragments of git error output to a failure category
+// Permanent causes come first: "RPC failed; HTTP 403" is an auth failure, not a flaky network.
+var gitOutputPatterns = []struct {
+	fragment string
+	category error
+}{
+	{"authentication failed", ErrAuth},
+	{"could not read username", ErrAuth},
+	{"could not read password", ErrAuth},
+	{"permission denied", ErrAuth},
+	{"returned error: 401", ErrAuth},
+	{"returned error: 403", ErrAuth},
+	{"not found in upstream", ErrBranchNotFound},
+	{"repository not found", ErrRepoNotFound},
+	{"returned error: 404", ErrRepoNotFound},
+	{"does not appear to be a git repository", ErrRepoNotFound},
+	{"could not resolve host", ErrNetwork},
+	{"temporary failure in name resolution", ErrNetwork},
+	{"failed to connect", ErrNetwork},
+	{"connection refused", ErrNetwork},
+	{"connection reset", ErrNetwork},
+	{"connection timed out", ErrNetwork},
+	{"operation timed out", ErrNetwork},
+	{"network is unreachable", ErrNetwork},
+	{"the remote end hung up unexpectedly", ErrNetwork},
+	{"early eof", ErrNetwork},
+	{"rpc failed", ErrNetwork},
+	{"tls handshake", ErrNetwork},
+	{"gnutls_handshake", ErrNetwork},
+	{"returned error: 5", ErrNetwork},
+}
+
+// classifyGitOutput returns the failure category git's error output describes, or nil when unknown
+func classifyGitOutput(output string) error {
+	output = strings.ToLower(output)
+	for _, pattern := range gitOutputPatterns {
+		if strings.Contains(output, pattern.fragment) {
+			return pattern.category
+		}
+	}
+	return nil
+}
+
+// validateExitCode maps a validate failure to a process exit code by error category
+func validateExitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return exitCodeAuth
+	case errors.Is(err, ErrNetwork):
+		return exitCodeNetwork
+	case errors.Is(err, ErrBranchNotFound):
+		return exitCodeBranchNotFound
+	case errors.Is(err, ErrRepoNotFound):
+		return exitCodeRepoNotFound
+	default:
+		return exitCodeFailure
+	}
+}
+
+// accessProblemHint suggests a fix for a repository connectivity failure, or "" when the cause is unknown
+func accessProblemHint(err error) string {
+	switch {
+	case errors.Is(err, ErrAuth):
+		return "check that the token is valid and has read access to the repository"
+	case errors.Is(err, ErrNetwork):
+		return "check the host in the repository URL and that it is reachable from here"
+	case errors.Is(err, ErrBranchNotFound):
+		return "check the branch name exists in the repository"
+	case errors.Is(err, ErrRepoNotFound):
+		return "check the repository URL, and that the token can see the repository (private repositories report Not Found without access)"
+	}
+	return ""
+}
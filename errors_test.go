@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestGetLatestCommitErrorCategories(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		status   int
+		expected error
+	}{
+		{name: "401 is auth", status: http.StatusUnauthorized, expected: ErrAuth},
+		{name: "403 is auth", status: http.StatusForbidden, expected: ErrAuth},
+		{name: "404 is branch not found", status: http.StatusNotFound, expected: ErrBranchNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				return stubResponse(tt.status, `{"message":"denied"}`), nil
+			})})
+
+			_, err := monitor.GetLatestCommit(&MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				RepoType: "github",
+				Auth:     AuthConfig{Token: "token"},
+			}, "main")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("GetLatestCommit() error = %v, want errors.Is %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGitHubNotFoundClassification(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+		exitCode int
+	}{
+		{
+			name:     "missing repository",
+			status:   http.StatusNotFound,
+			body:     `{"message":"Not Found","documentation_url":"https://docs.github.com/rest/commits/commits#get-a-commit"}`,
+			expected: ErrRepoNotFound,
+			exitCode: exitCodeRepoNotFound,
+		},
+		{
+			name:     "missing branch",
+			status:   http.StatusNotFound,
+			body:     `{"message":"No commit found for the ref main"}`,
+			expected: ErrBranchNotFound,
+			exitCode: exitCodeBranchNotFound,
+		},
+		{
+			name:     "missing branch as unprocessable entity",
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"message":"No commit found for SHA: main"}`,
+			expected: ErrBranchNotFound,
+			exitCode: exitCodeBranchNotFound,
+		},
+		{
+			name:     "unrecognized 404 body",
+			status:   http.StatusNotFound,
+			body:     `<html>not json</html>`,
+			expected: ErrBranchNotFound,
+			exitCode: exitCodeBranchNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				return stubResponse(tt.status, tt.body), nil
+			})})
+
+			_, err := monitor.GetLatestCommit(&MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				RepoType: "github",
+				Auth:     AuthConfig{Token: "token"},
+			}, "main")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("GetLatestCommit() error = %v, want errors.Is %v", err, tt.expected)
+			}
+			for _, other := range []error{ErrAuth, ErrNetwork, ErrBranchNotFound, ErrRepoNotFound} {
+				if other != tt.expected && errors.Is(err, other) {
+					t.Errorf("GetLatestCommit() error = %v, also matched %v", err, other)
+				}
+			}
+			if got := validateExitCode(err); got != tt.exitCode {
+				t.Errorf("validateExitCode() = %d, want %d", got, tt.exitCode)
+			}
+			if accessProblemHint(err) == "" {
+				t.Error("accessProblemHint() is empty, want a hint for the category")
+			}
+		})
+	}
+}
+
+func TestGetLatestCommitConnectionRefused(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// Reserve a port and close it so connections are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	noRetries := 0
+	monitor := NewMonitorService(&Config{Global: GlobalConfig{MaxRetries: &noRetries}}, nil)
+
+	_, err = monitor.GetLatestCommit(&MonitorConfig{
+		RepoURL:  fmt.Sprintf("http://%s/owner/repo", addr),
+		RepoType: "gitea",
+		Auth:     AuthConfig{Token: "token"},
+	}, "main")
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("GetLatestCommit() error = %v, want errors.Is ErrNetwork", err)
+	}
+	if errors.Is(err, ErrAuth) || errors.Is(err, ErrBranchNotFound) {
+		t.Errorf("GetLatestCommit() error = %v, matched more than one category", err)
+	}
+}
+
+func TestClassifyGitOutput(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected error
+	}{
+		{"fatal: unable to access 'https://gitlab.internal/qa/': Could not resolve host: gitlab.internal", ErrNetwork},
+		{"fatal: unable to access 'https://gitlab.internal/qa/': Failed to connect to gitlab.internal port 443: Connection refused", ErrNetwork},
+		{"error: RPC failed; curl 56 GnuTLS recv error (-9)\nfatal: early EOF", ErrNetwork},
+		{"fatal: unable to access 'https://gitlab.internal/qa/': The requested URL returned error: 503", ErrNetwork},
+		{"error: RPC failed; HTTP 403 curl 22 The requested URL returned error: 403", ErrAuth},
+		{"fatal: Authentication failed for 'https://gitlab.internal/qa/'", ErrAuth},
+		{"fatal: could not read Username for 'https://github.com': terminal prompts disabled", ErrAuth},
+		{"warning: Could not find remote branch release\nfatal: Remote branch release not found in upstream origin", ErrBranchNotFound},
+		{"remote: Repository not found.\nfatal: repository 'https://github.com/org/qa/' not found", ErrRepoNotFound},
+		{"fatal: destination path 'qa' already exists and is not an empty directory.", nil},
+	}
+
+	for _, tt := range tests {
+		if got := classifyGitOutput(tt.output); got != tt.expected {
+			t.Errorf("classifyGitOutput(%q) = %v, want %v", tt.output, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateExitCode(t *testing.T) {
+	apiErr := func(status int) error {
+		return fmt.Errorf("failed to access repository: %w", &APIError{Provider: "gitHub", StatusCode: status})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "unauthorized", err: apiErr(http.StatusUnauthorized), expected: exitCodeAuth},
+		{name: "forbidden", err: apiErr(http.StatusForbidden), expected: exitCodeAuth},
+		{name: "not found", err: apiErr(http.StatusNotFound), expected: exitCodeBranchNotFound},
+		{name: "missing repository", err: fmt.Errorf("%w: owner/repo", ErrRepoNotFound), expected: exitCodeRepoNotFound},
+		{name: "ls-remote missing branch", err: fmt.Errorf("%w: main not in git ls-remote output", ErrBranchNotFound), expected: exitCodeBranchNotFound},
+		{name: "network", err: classifyTransportError(fmt.Errorf("hTTP request failed: %w", &url.Error{Op: "Get", URL: "https://api.github.com", Err: errors.New("connection refused")})), expected: exitCodeNetwork},
+		{name: "server error", err: apiErr(http.StatusInternalServerError), expected: exitCodeFailure},
+		{name: "joined keeps category", err: errors.Join(errors.New("config problem"), apiErr(http.StatusNotFound)), expected: exitCodeBranchNotFound},
+		{name: "uncategorized", err: errors.New("boom"), expected: exitCodeFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateExitCode(tt.err); got != tt.expected {
+				t.Errorf("validateExitCode(%v) = %d, want %d", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auth types accepted in AuthConfig.Type
+const (
+	authTypeToken     = "token"
+	authTypeGitHubApp = "github_app"
+)
+
+// githubAppJWTLifetime is how long the app JWT is valid; GitHub rejects anything over 10 minutes
+const githubAppJWTLifetime = 9 * time.Minute
+
+// githubAppClockSkew backdates the JWT issue time to tolerate clock drift with GitHub
+const githubAppClockSkew = time.Minute
+
+// installationTokenRefreshMargin renews a cached installation token this long before it expires
+const installationTokenRefreshMargin = 5 * time.Minute
+
+// githubAppTokens is shared by monitoring and deployment so both reuse one token per installation
+var githubAppTokens = newGitHubAppTokenSource()
+
+// installationToken is a GitHub App installation access token
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// githubAppTokenSource mints and caches GitHub App installation tokens
+type githubAppTokenSource struct {
+	mu     sync.Mutex
+	tokens map[string]installationToken // "<api base>|<app id>|<installation id>" -> token
+	now    func() time.Time             // Replaceable in tests
+}
+
+// newGitHubAppTokenSource creates an empty token cache
+func newGitHubAppTokenSource() *githubAppTokenSource {
+	return &githubAppTokenSource{
+		tokens: make(map[string]installationToken),
+		now:    time.Now,
+	}
+}
+
+// Token returns a valid installation token for auth, minting a new one when none is cached or
+// the cached one is about to expire
+func (s *githubAppTokenSource) Token(ctx context.Context, client *http.Client, auth *AuthConfig, apiBaseURL string) (string, error) {
+	key := fmt.Sprintf("%s|%d|%d", apiBaseURL, auth.AppID, auth.InstallationID)
+
+	// Held while minting so concurrent checks of one installation exchange only once
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.tokens[key]; ok && s.now().Add(installationTokenRefreshMargin).Before(cached.ExpiresAt) {
+		return cached.Token, nil
+	}
+
+	token, err := s.mint(ctx, client, auth, apiBaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+	s.tokens[key] = *token
+
+	// Keep the new token out of log output like configured ones
+	logSecrets.Register(token.Token)
+
+	AppLogger.DebugS("Minted GitHub App installation token",
+		"app_id", auth.AppID,
+		"installation_id", auth.InstallationID,
+		"expires_at", token.ExpiresAt)
+	return token.Token, nil
+}
+
+// mint signs an app JWT and exchanges it for an installation token
+func (s *githubAppTokenSource) mint(ctx context.Context, client *http.Client, auth *AuthConfig, apiBaseURL string) (*installationToken, error) {
+	key, err := loadGitHubAppPrivateKey(auth.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	jwt, err := signGitHubAppJWT(auth.AppID, key, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeURL := fmt.Sprintf("%s/app/installations/%d/access_tokens", apiBaseURL, auth.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", exchangeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, classifyTransportError(fmt.Errorf("hTTP request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		return nil, &APIError{Provider: "gitHub", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var token installationToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if token.Token == "" {
+		return nil, errors.New("response has no token")
+	}
+	return &token, nil
+}
+
+// signGitHubAppJWT builds the RS256 JWT a GitHub App authenticates with
+func signGitHubAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-githubAppClockSkew).Unix(),
+		"exp": now.Add(githubAppJWTLifetime).Unix(),
+		"iss": fmt.Sprint(appID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// loadGitHubAppPrivateKey reads a PEM RSA private key in PKCS#1 (as GitHub issues them) or PKCS#8 form
+func loadGitHubAppPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key %s is not PEM encoded", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key %s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// githubAppAPIBaseURL returns the REST API endpoint installation tokens are minted at
+// apiBaseURL wins when set; otherwise github.com uses the public API and other hosts GitHub Enterprise's /api/v3.
+func githubAppAPIBaseURL(repoURL string, apiBaseURL string) string {
+	if apiBaseURL != "" {
+		return strings.TrimSuffix(apiBaseURL, "/")
+	}
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Host == "" || strings.EqualFold(parsed.Hostname(), "github.com") {
+		return defaultGitHubAPIBaseURL
+	}
+	return fmt.Sprintf("%s://%s/api/v3", parsed.Scheme, parsed.Host)
+}
+
+// resolveAuth returns auth ready for use: GitHub App credentials are replaced by an installation
+// token, which git accepts with the x-access-token username, and OAuth credentials by a current
+// access token; other auth is returned unchanged
+func resolveAuth(ctx context.Context, client *http.Client, auth AuthConfig, repoURL string, apiBaseURL string) (AuthConfig, error) {
+	switch auth.Type {
+	case authTypeGitHubApp:
+		token, err := githubAppTokens.Token(ctx, client, &auth, githubAppAPIBaseURL(repoURL, apiBaseURL))
+		if err != nil {
+			return AuthConfig{}, err
+		}
+		return AuthConfig{Username: "x-access-token", Token: token}, nil
+	case authTypeOAuth:
+		token, err := oauthTokens.Token(ctx, client, &auth)
+		if err != nil {
+			return AuthConfig{}, err
+		}
+		return AuthConfig{Username: auth.Username, Token: token}, nil
+	}
+	return auth, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import "strings"
+
+// What happens to a grouped repository's changes on branches outside group_trigger_branches
+const (
+	groupTriggerFallbackIgnore     = "ignore"
+	groupTriggerFallbackIndividual = "individual"
+)
+
+// getGroupTriggerFallback returns the repository's group_trigger_fallback, defaulting to ignore
+func getGroupTriggerFallback(repo *RepositoryConfig) string {
+	if repo.GroupTriggerFallback == "" {
+		return groupTriggerFallbackIgnore
+	}
+	return repo.GroupTriggerFallback
+}
+
+// escalatesToGroup reports whether a change on ref deploys the repository's whole group
+// Without group_trigger_branches every change does; otherwise only branches matching an entry, never tags.
+func escalatesToGroup(repo *RepositoryConfig, ref string) bool {
+	if len(repo.GroupTriggerBranches) == 0 {
+		return true
+	}
+	if strings.HasPrefix(ref, tagRefPrefix) {
+		return false
+	}
+	for _, configured := range repo.GroupTriggerBranches {
+		if matchesBranch(configured, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitGroupTriggerRefs separates a grouped repository's changed refs into those escalating to a group
+// deployment and the rest, which deploy the repository alone or are dropped per group_trigger_fallback
+func splitGroupTriggerRefs(repo *RepositoryConfig, refs []string) (groupRefs []string, individualRefs []string) {
+	for _, ref := range refs {
+		if escalatesToGroup(repo, ref) {
+			groupRefs = append(groupRefs, ref)
+			continue
+		}
+		if getGroupTriggerFallback(repo) == groupTriggerFallbackIndividual {
+			individualRefs = append(individualRefs, ref)
+			continue
+		}
+		AppLogger.InfoS("Ignoring change outside group_trigger_branches",
+			"repo", repo.GetDisplayName(),
+			"group", repo.Group,
+			"ref", ref)
+	}
+	return groupRefs, individualRefs
+}
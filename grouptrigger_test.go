@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEscalatesToGroup(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		branches []string
+		ref      string
+		want     bool
+	}{
+		{"unscoped branch", nil, "dev-1", true},
+		{"unscoped tag", nil, tagRef("v.*"), true},
+		{"listed branch", []string{"main", "release/.*"}, "main", true},
+		{"matching pattern", []string{"main", "release/.*"}, "release/1.0", true},
+		{"unlisted branch", []string{"main", "release/.*"}, "dev-1", false},
+		{"pattern matches whole name", []string{"main"}, "main-old", false},
+		{"tags never escalate once scoped", []string{".*"}, tagRef("v.*"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &RepositoryConfig{Name: "web-a", Group: "web", GroupTriggerBranches: tt.branches}
+			if got := escalatesToGroup(repo, tt.ref); got != tt.want {
+				t.Errorf("escalatesToGroup(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployChangesGroupTriggerBranches(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		fallback string
+		changes  map[string][]string // repo -> changed branches
+		deployed map[string]int      // repo -> deployments
+	}{
+		{
+			name:     "listed branch deploys the group",
+			changes:  map[string][]string{"web-a": {"main"}},
+			deployed: map[string]int{"web-a": 1, "web-b": 1},
+		},
+		{
+			name:     "unlisted branch is ignored by default",
+			changes:  map[string][]string{"web-a": {"dev-1"}},
+			deployed: map[string]int{},
+		},
+		{
+			name:     "unlisted branch deploys the repository alone",
+			fallback: groupTriggerFallbackIndividual,
+			changes:  map[string][]string{"web-a": {"dev-1"}},
+			deployed: map[string]int{"web-a": 1},
+		},
+		{
+			name:     "member deploying alone is covered by its group deployment",
+			fallback: groupTriggerFallbackIndividual,
+			changes:  map[string][]string{"web-a": {"dev-1"}, "web-b": {"dev-1"}},
+			deployed: map[string]int{"web-a": 1, "web-b": 1},
+		},
+		{
+			name:     "unscoped member still escalates",
+			changes:  map[string][]string{"web-b": {"dev-1"}},
+			deployed: map[string]int{"web-a": 1, "web-b": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, markers := newTriggerTestApp(t, "", "")
+			for i := range app.config.Repositories {
+				repo := &app.config.Repositories[i]
+				repo.Deploy.Commands = []CommandSpec{{Run: "echo deployed >> " + filepath.Join(markers, repo.Name)}}
+				if repo.Name == "web-a" {
+					repo.GroupTriggerBranches = []string{"main"}
+					repo.GroupTriggerFallback = tt.fallback
+				}
+			}
+
+			var changes []repoChange
+			for i := range app.config.Repositories {
+				repo := &app.config.Repositories[i]
+				if refs, ok := tt.changes[repo.Name]; ok {
+					changes = append(changes, repoChange{repo: repo, refs: refs})
+				}
+			}
+			if failures := app.monitorService.deployChanges(context.Background(), changes); len(failures) > 0 {
+				t.Fatalf("deployChanges() failures = %v", failures)
+			}
+
+			for _, repo := range []string{"solo", "web-a", "web-b"} {
+				data, _ := os.ReadFile(filepath.Join(markers, repo))
+				if got := strings.Count(string(data), "deployed"); got != tt.deployed[repo] {
+					t.Errorf("%s deployed %d times, want %d", repo, got, tt.deployed[repo])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGroupTriggerBranches(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		group     string
+		branches  []string
+		fallback  string
+		wantPaths []string
+	}{
+		{"valid", "web", []string{"main", "release/.*"}, groupTriggerFallbackIndividual, nil},
+		{"without group", "", []string{"main"}, "", []string{"repo.group_trigger_branches"}},
+		{"invalid pattern", "web", []string{"release/("}, "", []string{"repo.group_trigger_branches[0]"}},
+		{"unknown fallback", "web", []string{"main"}, "group", []string{"repo.group_trigger_fallback"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &RepositoryConfig{Name: "web-a", Group: tt.group, GroupTriggerBranches: tt.branches, GroupTriggerFallback: tt.fallback}
+			var paths []string
+			for _, err := range validateRepositoryConfig(repo, "repo", nil) {
+				if strings.Contains(err.Path, "group_trigger") {
+					paths = append(paths, err.Path)
+				}
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validation paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestDeployChangesPassesEachMemberItsOwnTrigger(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app, markers := newTriggerTestApp(t, "", "")
+	for i := range app.config.Repositories {
+		repo := &app.config.Repositories[i]
+		repo.Deploy.Commands = []CommandSpec{{Run: `echo "sha=$SENTRY_COMMIT_SHA branch=$SENTRY_BRANCH" >> ` + filepath.Join(markers, repo.Name)}}
+	}
+	monitor := app.monitorService
+	webA, webB := &app.config.Repositories[1], &app.config.Repositories[2]
+
+	// web-b changes first; in the next cycle only web-a does, and web-b must not redeploy its old change
+	cycles := []struct {
+		repo *RepositoryConfig
+		sha  string
+	}{{webB, "bbb111"}, {webA, "aaa222"}}
+	for _, cycle := range cycles {
+		monitor.setPendingTrigger(cycle.repo.Name, "main", DeployTrigger{Branch: "main", CommitInfo: CommitInfo{SHA: cycle.sha}})
+		if failures := monitor.deployChanges(context.Background(), []repoChange{{repo: cycle.repo, refs: []string{"main"}}}); len(failures) > 0 {
+			t.Fatalf("deployChanges() failures = %v", failures)
+		}
+	}
+
+	want := map[string]string{
+		"web-a": "sha= branch=\nsha=aaa222 branch=main\n",
+		"web-b": "sha=bbb111 branch=main\nsha= branch=\n",
+	}
+	for repo, wantEnv := range want {
+		data, _ := os.ReadFile(filepath.Join(markers, repo))
+		if string(data) != wantEnv {
+			t.Errorf("%s deployments saw %q, want %q", repo, data, wantEnv)
+		}
+	}
+}
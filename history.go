@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is how many deployment results are kept in memory when unset
+const defaultHistorySize = 50
+
+// DeploymentHistoryEntry is one finalized deployment kept in the in-memory history
+// Exactly one of Repository and Group is set.
+type DeploymentHistoryEntry struct {
+	FinishedAt    time.Time          `json:"finished_at"`
+	TriggerCommit string             `json:"trigger_commit,omitempty"`
+	Repository    *DeployResult      `json:"repository,omitempty"`
+	Group         *GroupDeployResult `json:"group,omitempty"`
+}
+
+// deploymentHistory is a bounded ring buffer of recent deployment results
+type deploymentHistory struct {
+	mu      sync.Mutex
+	entries []DeploymentHistoryEntry
+	next    int  // Slot the next entry is written to
+	full    bool // Whether the buffer has wrapped around
+}
+
+// newDeploymentHistory creates a history holding at most size entries
+func newDeploymentHistory(size int) *deploymentHistory {
+	return &deploymentHistory{entries: make([]DeploymentHistoryEntry, size)}
+}
+
+// getHistorySize gets the deployment history size from global config or uses default
+func getHistorySize(config *Config) int {
+	if config.Global.HistorySize > 0 {
+		return config.Global.HistorySize
+	}
+	return defaultHistorySize
+}
+
+// add records an entry, overwriting the oldest one once the buffer is full
+func (h *deploymentHistory) add(entry DeploymentHistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// recent returns the recorded entries, newest first
+func (h *deploymentHistory) recent() []DeploymentHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+
+	recent := make([]DeploymentHistoryEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		recent = append(recent, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+	return recent
+}
+
+// recordHistory keeps a finalized individual deployment in the in-memory history
+func (d *DeployService) recordHistory(result *DeployResult) {
+	d.history.add(DeploymentHistoryEntry{
+		FinishedAt:    time.Now(),
+		TriggerCommit: result.deployTrigger().SHA,
+		Repository:    result,
+	})
+}
+
+// recordGroupHistory keeps a finalized group deployment in the in-memory history
+func (d *DeployService) recordGroupHistory(result *GroupDeployResult) {
+	d.history.add(DeploymentHistoryEntry{
+		FinishedAt: time.Now(),
+		Group:      result,
+	})
+}
+
+// RecentDeployments returns the most recent deployment results, newest first
+func (d *DeployService) RecentDeployments() []DeploymentHistoryEntry {
+	return d.history.recent()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeploymentHistoryWraparound(t *testing.T) {
+	history := newDeploymentHistory(3)
+
+	if got := history.recent(); len(got) != 0 {
+		t.Fatalf("recent() on empty history = %v, want none", got)
+	}
+
+	tests := []struct {
+		added    int
+		expected []string
+	}{
+		{added: 2, expected: []string{"repo-2", "repo-1"}},
+		{added: 3, expected: []string{"repo-3", "repo-2", "repo-1"}},
+		{added: 4, expected: []string{"repo-4", "repo-3", "repo-2"}},
+		{added: 7, expected: []string{"repo-7", "repo-6", "repo-5"}},
+	}
+
+	added := 0
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d added", tt.added), func(t *testing.T) {
+			for ; added < tt.added; added++ {
+				history.add(DeploymentHistoryEntry{Repository: &DeployResult{RepoName: fmt.Sprintf("repo-%d", added+1)}})
+			}
+
+			recent := history.recent()
+			if len(recent) != len(tt.expected) {
+				t.Fatalf("len(recent()) = %d, want %d", len(recent), len(tt.expected))
+			}
+			for i, name := range tt.expected {
+				if recent[i].Repository.RepoName != name {
+					t.Errorf("recent()[%d] = %s, want %s", i, recent[i].Repository.RepoName, name)
+				}
+			}
+		})
+	}
+}
+
+func TestGetHistorySize(t *testing.T) {
+	if got := getHistorySize(&Config{}); got != defaultHistorySize {
+		t.Errorf("getHistorySize() = %d, want default %d", got, defaultHistorySize)
+	}
+	if got := getHistorySize(&Config{Global: GlobalConfig{HistorySize: 5}}); got != 5 {
+		t.Errorf("getHistorySize() = %d, want 5", got)
+	}
+}
+
+func TestRecentDeploymentsStatusEndpoint(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newSlackTestConfig(t, NotificationsConfig{})
+	config.Global.HistorySize = 2
+	service := NewDeployService(config)
+	trigger := &DeployTrigger{Branch: "main", CommitInfo: CommitInfo{SHA: "abc123"}}
+
+	// Record a group deployment followed by two individual ones; only the last two fit
+	groupConfig := config.Groups["slack-group"]
+	service.DeployGroup(context.Background(), "slack-group", []string{"slack-repo"}, &groupConfig, nil)
+	service.DeployIndividual(context.Background(), &config.Repositories[0], trigger)
+	service.DeployIndividual(context.Background(), &config.Repositories[0], trigger)
+
+	server := httptest.NewServer(NewStatusServer(":0", nil, service).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var status statusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode /status: %v", err)
+	}
+	if len(status.RecentDeployments) != 2 {
+		t.Fatalf("len(recent_deployments) = %d, want 2", len(status.RecentDeployments))
+	}
+	for i, entry := range status.RecentDeployments {
+		if entry.Repository == nil || entry.Group != nil {
+			t.Fatalf("recent_deployments[%d] = %+v, want an individual deployment", i, entry)
+		}
+		if entry.Repository.Success || entry.TriggerCommit != "abc123" || entry.FinishedAt.IsZero() {
+			t.Errorf("recent_deployments[%d] = %+v, want failed deploy of abc123 with finish time", i, entry)
+		}
+	}
+}
+
+func TestRecentDeploymentsRecordsGroups(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newSlackTestConfig(t, NotificationsConfig{})
+	service := NewDeployService(config)
+
+	groupConfig := config.Groups["slack-group"]
+	service.DeployGroup(context.Background(), "slack-group", []string{"slack-repo"}, &groupConfig, nil)
+
+	recent := service.RecentDeployments()
+	if len(recent) != 1 || recent[0].Group == nil {
+		t.Fatalf("RecentDeployments() = %+v, want one group entry", recent)
+	}
+	if recent[0].Group.GroupName != "slack-group" || recent[0].Group.Results["slack-repo"] == nil {
+		t.Errorf("group entry = %+v, want slack-group with slack-repo result", recent[0].Group)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// initOutputStdout is the -output value that makes init print the example instead of writing -config
+const initOutputStdout = "-"
+
+// initConfigAction scaffolds a configuration from GetConfigExample
+// The example is written to -config, which must not exist unless -force is given, or printed to w with -output=-.
+func initConfigAction(appConfig *AppConfig, w io.Writer) error {
+	example := GetConfigExample()
+	if appConfig.Output == initOutputStdout {
+		_, err := io.WriteString(w, example)
+		return err
+	}
+
+	// Refuse to clobber an existing config without checking first and racing another writer
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if appConfig.Force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(appConfig.ConfigPath, flags, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists; use -force to overwrite it", appConfig.ConfigPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+
+	if _, err := io.WriteString(file, example); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Fprintf(w, "Wrote example configuration to %s; edit it, then run sentry -action=validate -config=%s\n", appConfig.ConfigPath, appConfig.ConfigPath)
+	return nil
+}
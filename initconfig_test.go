@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitConfigAction(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	const existing = "polling_interval: 300\n"
+
+	tests := []struct {
+		name        string
+		existing    bool
+		force       bool
+		output      string
+		wantErr     string
+		wantFile    string // expected config file content afterwards, "" when it must not exist
+		wantStdout  string
+		wantExample bool // stdout is the example itself
+	}{
+		{name: "writes a new file", wantFile: GetConfigExample(), wantStdout: "Wrote example configuration"},
+		{name: "refuses to overwrite", existing: true, wantErr: "already exists; use -force", wantFile: existing},
+		{name: "force overwrites", existing: true, force: true, wantFile: GetConfigExample(), wantStdout: "Wrote example configuration"},
+		{name: "prints to stdout", output: "-", wantExample: true},
+		{name: "prints to stdout leaving an existing file", existing: true, output: "-", wantFile: existing, wantExample: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "sentry.yaml")
+			if tt.existing {
+				if err := os.WriteFile(configPath, []byte(existing), 0600); err != nil {
+					t.Fatalf("failed to write existing config: %v", err)
+				}
+			}
+
+			var stdout bytes.Buffer
+			err := initConfigAction(&AppConfig{ConfigPath: configPath, Force: tt.force, Output: tt.output}, &stdout)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("initConfigAction() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("initConfigAction() error = %v", err)
+			}
+
+			data, readErr := os.ReadFile(configPath)
+			if tt.wantFile == "" {
+				if readErr == nil {
+					t.Errorf("config file was written, want stdout only")
+				}
+			} else if string(data) != tt.wantFile {
+				t.Errorf("config file = %.60q..., want %.60q...", data, tt.wantFile)
+			}
+
+			if tt.wantExample && stdout.String() != GetConfigExample() {
+				t.Errorf("stdout = %.60q..., want the config example", stdout.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantStdout)
+			}
+		})
+	}
+}
@@ -46,7 +46,7 @@ global:
 	tmpFile.Close()
 
 	// Load the configuration
-	config, err := LoadConfig(tmpFile.Name())
+	config, err := LoadConfig(tmpFile.Name(), ConfigLoadOptions{})
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
@@ -95,7 +95,7 @@ func TestServiceInitialization(t *testing.T) {
 						Token:    "token",
 					},
 					ProjectName: "test",
-					Commands:    []string{"echo test"},
+					Commands:    []CommandSpec{{Run: "echo test"}},
 				},
 			},
 		},
@@ -181,7 +181,7 @@ global:
 	tmpFile.Close()
 
 	// Load the configuration
-	config, err := LoadConfig(tmpFile.Name())
+	config, err := LoadConfig(tmpFile.Name(), ConfigLoadOptions{})
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
@@ -229,7 +229,7 @@ func TestConfigValidation(t *testing.T) {
 								Token:    "token",
 							},
 							ProjectName: "test",
-							Commands:    []string{"echo test"},
+							Commands:    []CommandSpec{{Run: "echo test"}},
 						},
 					},
 				},
@@ -261,7 +261,7 @@ func TestConfigValidation(t *testing.T) {
 								Token:    "token",
 							},
 							ProjectName: "test",
-							Commands:    []string{"echo test"},
+							Commands:    []CommandSpec{{Run: "echo test"}},
 						},
 					},
 				},
@@ -322,7 +322,7 @@ func TestGroupConfiguration(t *testing.T) {
 						Token:    "token",
 					},
 					ProjectName: "project1",
-					Commands:    []string{"echo 'deploy repo1'"},
+					Commands:    []CommandSpec{{Run: "echo 'deploy repo1'"}},
 				},
 			},
 			{
@@ -346,7 +346,7 @@ func TestGroupConfiguration(t *testing.T) {
 						Token:    "token",
 					},
 					ProjectName: "project2",
-					Commands:    []string{"echo 'deploy repo2'"},
+					Commands:    []CommandSpec{{Run: "echo 'deploy repo2'"}},
 				},
 			},
 		},
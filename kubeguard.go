@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// guardedKubeconfig is where the per-deployment kubeconfig is written, relative to the QA clone
+const guardedKubeconfig = ".sentry/kubeconfig"
+
+// kubectlRunner runs kubectl with args and returns its stdout; tests replace it to avoid a cluster
+type kubectlRunner func(ctx context.Context, args ...string) ([]byte, error)
+
+// execKubectl runs the kubectl binary on PATH against the ambient kubeconfig
+func execKubectl(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("kubectl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// usesKubeGuard reports whether deployment commands are pinned to a kube context or namespace
+func usesKubeGuard(deploy *DeployConfig) bool {
+	return deploy.KubeContext != "" || deploy.KubeNamespace != ""
+}
+
+// prepareKubeconfig checks the configured kube context exists and writes a kubeconfig pinned to it
+// The kubeconfig holds only that context, with kube_namespace as its default namespace, so commands
+// cannot reach another cluster and the user's kubeconfig is never modified.
+func (d *DeployService) prepareKubeconfig(ctx context.Context, repoConfig *RepositoryConfig, workDir string) error {
+	deploy := &repoConfig.Deploy
+	if !usesKubeGuard(deploy) {
+		return nil
+	}
+
+	viewArgs := []string{"config", "view", "--minify", "--flatten"}
+	if deploy.KubeContext != "" {
+		contexts, err := d.kubectl(ctx, "config", "get-contexts", "-o", "name")
+		if err != nil {
+			return fmt.Errorf("failed to list kube contexts: %w", err)
+		}
+		if !slices.Contains(strings.Fields(string(contexts)), deploy.KubeContext) {
+			return fmt.Errorf("kube context %q not found in kubeconfig", deploy.KubeContext)
+		}
+		viewArgs = append(viewArgs, "--context", deploy.KubeContext)
+	}
+
+	kubeconfig, err := d.kubectl(ctx, viewArgs...)
+	if err != nil {
+		return fmt.Errorf("failed to read kube context: %w", err)
+	}
+
+	kubeconfigPath := hostKubeconfigPath(workDir)
+	if err := os.MkdirAll(filepath.Dir(kubeconfigPath), 0700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := os.WriteFile(kubeconfigPath, kubeconfig, 0600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	if deploy.KubeNamespace != "" {
+		if _, err := d.kubectl(ctx, "--kubeconfig", kubeconfigPath, "config", "set-context", "--current", "--namespace", deploy.KubeNamespace); err != nil {
+			return fmt.Errorf("failed to set kube namespace: %w", err)
+		}
+	}
+
+	AppLogger.InfoS("Pinned deployment kube context",
+		"repo", repoConfig.GetDisplayName(),
+		"context", deploy.KubeContext,
+		"namespace", deploy.KubeNamespace)
+	return nil
+}
+
+// kubeGuardEnv returns the variables pointing commands at the guarded kubeconfig
+// kubeconfigPath is the kubeconfig as the command sees it, on the host or inside a runner container.
+func kubeGuardEnv(deploy *DeployConfig, kubeconfigPath string) []string {
+	if !usesKubeGuard(deploy) {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("KUBECONFIG=%s", kubeconfigPath),
+		fmt.Sprintf("SENTRY_KUBE_CONTEXT=%s", deploy.KubeContext),
+		fmt.Sprintf("SENTRY_KUBE_NAMESPACE=%s", deploy.KubeNamespace),
+	}
+}
+
+// hostKubeconfigPath returns the guarded kubeconfig location inside a QA clone on the host
+func hostKubeconfigPath(workDir string) string {
+	return filepath.Join(workDir, filepath.FromSlash(guardedKubeconfig))
+}
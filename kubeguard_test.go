@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubKubectl records kubectl invocations and answers them from a kubeconfig holding contexts
+func stubKubectl(calls *[]string, contexts ...string) kubectlRunner {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		*calls = append(*calls, strings.Join(args, " "))
+		switch {
+		case len(args) >= 2 && args[1] == "get-contexts":
+			return []byte(strings.Join(contexts, "\n") + "\n"), nil
+		case len(args) >= 2 && args[1] == "view":
+			return []byte("apiVersion: v1\nkind: Config\n"), nil
+		case len(args) >= 4 && args[3] == "set-context":
+			return nil, nil
+		}
+		return nil, errors.New("unexpected kubectl call")
+	}
+}
+
+func TestDeployRepositoryKubeGuard(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		context   string
+		namespace string
+		contexts  []string
+		wantCalls []string
+		wantError string
+	}{
+		{
+			name:      "context and namespace",
+			context:   "qa-cluster",
+			namespace: "tekton-pipelines",
+			contexts:  []string{"prod-cluster", "qa-cluster"},
+			wantCalls: []string{
+				"config get-contexts -o name",
+				"config view --minify --flatten --context qa-cluster",
+				"--kubeconfig <kubeconfig> config set-context --current --namespace tekton-pipelines",
+			},
+		},
+		{
+			name:      "namespace only pins the current context",
+			namespace: "tekton-pipelines",
+			wantCalls: []string{
+				"config view --minify --flatten",
+				"--kubeconfig <kubeconfig> config set-context --current --namespace tekton-pipelines",
+			},
+		},
+		{
+			name:      "missing context fails before commands",
+			context:   "qa-cluster",
+			contexts:  []string{"prod-cluster"},
+			wantCalls: []string{"config get-contexts -o name"},
+			wantError: `kube context guard failed: kube context "qa-cluster" not found in kubeconfig`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markers := t.TempDir()
+			config := newDrainTestConfig(t, []CommandSpec{
+				{Run: `test -f "$KUBECONFIG" && echo "$SENTRY_KUBE_CONTEXT/$SENTRY_KUBE_NAMESPACE" > ` + filepath.Join(markers, "env")},
+			})
+			config.Repositories[0].Deploy.KubeContext = tt.context
+			config.Repositories[0].Deploy.KubeNamespace = tt.namespace
+
+			var calls []string
+			service := NewDeployService(config)
+			service.kubectl = stubKubectl(&calls, tt.contexts...)
+
+			result := service.deployRepository("drain-repo", context.Background(), nil)
+
+			kubeconfig := hostKubeconfigPath(result.ClonePath)
+			for i := range calls {
+				calls[i] = strings.ReplaceAll(calls[i], kubeconfig, "<kubeconfig>")
+			}
+			if strings.Join(calls, "\n") != strings.Join(tt.wantCalls, "\n") {
+				t.Errorf("kubectl calls = %q, want %q", calls, tt.wantCalls)
+			}
+
+			env, err := os.ReadFile(filepath.Join(markers, "env"))
+			if tt.wantError != "" {
+				if result.Success || result.Error != tt.wantError {
+					t.Errorf("result = success %v error %q, want %q", result.Success, result.Error, tt.wantError)
+				}
+				if len(result.CommandsRun) != 0 || err == nil {
+					t.Errorf("commands ran despite the failed guard: %v", result.CommandsRun)
+				}
+				return
+			}
+
+			if !result.Success {
+				t.Fatalf("deployRepository() error = %s", result.Error)
+			}
+			if err != nil {
+				t.Fatalf("command did not see the guarded kubeconfig: %v", err)
+			}
+			if got, want := strings.TrimSpace(string(env)), tt.context+"/"+tt.namespace; got != want {
+				t.Errorf("command env = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewDeploymentCmdKubeGuardEnv(t *testing.T) {
+	workDir := filepath.Join(t.TempDir(), "clone")
+	spec := CommandSpec{Run: "kubectl apply -f ."}
+
+	tests := []struct {
+		name    string
+		deploy  DeployConfig
+		want    []string
+		wantNot string
+	}{
+		{
+			name:   "host shell",
+			deploy: DeployConfig{KubeContext: "qa", KubeNamespace: "ci"},
+			want: []string{
+				"KUBECONFIG=" + filepath.Join(workDir, ".sentry", "kubeconfig"),
+				"SENTRY_KUBE_CONTEXT=qa",
+				"SENTRY_KUBE_NAMESPACE=ci",
+			},
+		},
+		{
+			name:   "runner image",
+			deploy: DeployConfig{KubeContext: "qa", RunnerImage: "bitnami/kubectl:1.29"},
+			want:   []string{"-e", "KUBECONFIG=/work/.sentry/kubeconfig", "-e", "SENTRY_KUBE_CONTEXT=qa"},
+		},
+		{
+			name:    "unguarded",
+			deploy:  DeployConfig{},
+			wantNot: "SENTRY_KUBE_CONTEXT=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &RepositoryConfig{Name: "app", Deploy: tt.deploy}
+
+			cmd, err := newDeploymentCmd(context.Background(), repo, &spec, workDir, workDir, newCommandTemplateData(repo, DeployTrigger{}))
+			if err != nil {
+				t.Fatalf("newDeploymentCmd() error = %v", err)
+			}
+
+			got := strings.Join(append(cmd.Args, cmd.Env...), "|")
+			if !strings.Contains(got, strings.Join(tt.want, "|")) {
+				t.Errorf("command = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.wantNot != "" && strings.Contains(got, tt.wantNot) {
+				t.Errorf("command = %q, want no %q", got, tt.wantNot)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// recommendedPollingInterval is the shortest polling interval (seconds) -strict accepts without a warning
+const recommendedPollingInterval = 120
+
+// validationHints suggests a fix for a problem, keyed by the field name at the end of its path
+var validationHints = map[string]string{
+	"polling_interval":         "use 60 seconds or more, e.g. polling_interval: 300",
+	"poll_interval":            "use 60 seconds or more, or remove it to inherit polling_interval",
+	"kube_context":             "use the deploy's commands mode, or remove kube_context and kube_namespace",
+	"kube_namespace":           "use lowercase letters, digits and '-', starting and ending with a letter or digit",
+	"command_denylist":         "use a valid Go regular expression",
+	"webhook_secret":           "remove it, or switch the repository to repo_type github or gitlab",
+	"repositories":             "add at least one entry under repositories:",
+	"name":                     "give every repository a unique, non-empty name",
+	"repo_url":                 "set the repository's web URL, e.g. https://github.com/owner/repo",
+	"qa_repo_url":              "set the URL of the QA repository holding the Tekton manifests",
+	"qa_repo_branch":           "set the QA repository branch to clone, e.g. main",
+	"branches":                 "list branch names or regex patterns, e.g. [\"main\", \"release-.*\"]",
+	"tags":                     "use a valid regex, or move tag monitoring to a github repository",
+	"repo_type":                "use one of github, gitlab, gitea, bitbucket, git",
+	"token":                    "set the token directly, reference an environment variable, e.g. \"${GITHUB_TOKEN}\", or use token_file",
+	"token_file":               "point at a readable, non-empty file holding only the token, and remove token",
+	"project_name":             "use lowercase letters, digits and '-', starting and ending with a letter or digit",
+	"commands":                 "list at least one shell command to run in the QA repository",
+	"run":                      "remove the empty command or give it a command line",
+	"dir":                      "use a path relative to the QA repository root, without '..'",
+	"shell":                    "give only the interpreter path, e.g. /bin/bash; it is invoked with -c",
+	"timeout":                  "use a number of seconds, or remove it to use the default",
+	"max_output_bytes":         "use a size in bytes, or remove it to keep the last 1MB of each command's output",
+	"command_timeout":          "use a number of seconds, or remove it to use the 300 second default",
+	"execution_strategy":       "use parallel or sequential",
+	"max_parallel":             "use 1 or more concurrent deployments",
+	"global_timeout":           "use a positive number of seconds covering the whole group deployment",
+	"order":                    "list every member of the sequential group exactly once, or remove it to use declaration order",
+	"group_trigger_branches":   "put the repository in a group, and use branch names or Go regexes such as main or release/.*",
+	"group_trigger_fallback":   "use ignore, or individual to deploy the repository on its own",
+	"group":                    "define the group under groups: or remove the repository's group field",
+	"api_base_url":             "use the full API URL, e.g. https://ghe.company.com/api/v3",
+	"manifest_globs":           "use filepath.Match patterns such as *.yaml",
+	"branch_map":               "map a branch name or Go regex such as release/.* to a non-empty QA branch",
+	"substitutions":            "rename the key using only letters, digits and underscores",
+	"env":                      "rename the variable using only letters, digits and underscores, not starting with a digit",
+	"max_retries":              "use 0 to disable retries, or remove it to use the default",
+	"retry_delay":              "use 0 for immediate retries, or remove it to use the default",
+	"retry_max_delay":          "remove it to use the 30 second default",
+	"monitor_timeout":          "use a number of seconds, or remove it to use timeout",
+	"deploy_timeout":           "use a number of seconds, or remove it to use timeout",
+	"repo_deploy_timeout":      "use a number of seconds covering the clone and every command, or remove it to use 3600",
+	"history_size":             "remove it to keep the default 50 results",
+	"max_parallel_individual":  "use 1 or more, or remove it to deploy ungrouped repositories one at a time",
+	"include_message_regex":    "use a valid Go regex on a github, gitlab, gitea or bitbucket repository",
+	"exclude_message_regex":    "use a valid Go regex, e.g. \\[skip ci\\]",
+	"paths":                    "use globs such as deploy/*.yaml or charts/** on a github repository",
+	"log_level":                "use debug, info, warn or error",
+	"user_agent":               "remove line breaks, e.g. \"Sentry-qa-team\"",
+	"log_max_size_mb":          "use a size in megabytes, or remove it to rotate at 100 MB",
+	"log_max_backups":          "use 0 to keep no rotated files, or remove it to keep 3",
+	"clone_args":               "remove the empty argument; give each flag and value as its own list item",
+	"orphan_temp_max_age":      "use a number of seconds, or remove it to sweep temp directories older than a day",
+	"deploy_lock_wait":         "use 0 to fail at once, or the seconds a deployment may wait for another process",
+	"config_check_interval":    "use a number of seconds, e.g. 30, or 0 to reload only on SIGHUP",
+	"ca_cert_file":             "give the path of a PEM file holding your internal CA certificate(s)",
+	"proxy":                    "use a URL such as http://proxy.corp:3128 or socks5://proxy.corp:1080",
+	"no_proxy":                 "set proxy as well, or remove no_proxy",
+	"git_binary":               "give the full path of the git executable, or remove it to use git from PATH",
+	"pre_apply_validate":       "remove it; gitlab_pipeline mode runs no kubectl apply commands",
+	"clone_depth":              "use 0 for a full clone, or remove it for a shallow clone of depth 1",
+	"mode":                     "use commands, or gitlab_pipeline to trigger a GitLab pipeline",
+	"trigger_token":            "create a pipeline trigger token in the QA project's CI/CD settings",
+	"type":                     "use slack or generic_webhook",
+	"url":                      "set the full URL receiving notifications, e.g. \"${SLACK_WEBHOOK_URL}\"",
+	"template":                 "fix the Go text/template syntax, e.g. {{.RepoName}} failed: {{.Error}}",
+	"check_failure_threshold":  "use the number of failed polls in a row to alert on, or 0 to disable check alerts",
+	"deploy_cooldown":          "use a number of seconds, or 0 to deploy on every change",
+	"verify_pipeline_run":      "use the deploy's commands mode, or remove verify_pipeline_run",
+	"pipeline_name":            "set the name of the Tekton Pipeline whose runs the commands start",
+	"namespace":                "use lowercase letters, digits and '-', starting and ending with a letter or digit",
+	"app_id":                   "use the App ID shown on the GitHub App's settings page",
+	"installation_id":          "use the number at the end of the app installation's settings URL",
+	"private_key_path":         "set the path of the .pem private key generated for the GitHub App",
+	"refresh_token":            "set the OAuth refresh token, e.g. \"${GITLAB_REFRESH_TOKEN}\"",
+	"token_url":                "use the provider's OAuth token endpoint, e.g. https://gitlab.com/oauth/token",
+	"allowed_command_binaries": "list bare program names such as kubectl, or absolute paths",
+}
+
+// validationHint returns the suggested fix for a problem at path, or "" when none is known
+func validationHint(path string) string {
+	if strings.Contains(path, ".branch_map.") {
+		return validationHints["branch_map"]
+	}
+	if strings.Contains(path, ".substitutions.") {
+		return validationHints["substitutions"]
+	}
+	if strings.Contains(path, ".env.") {
+		return validationHints["env"]
+	}
+
+	field := path[strings.LastIndex(path, ".")+1:]
+	if i := strings.Index(field, "["); i >= 0 {
+		field = field[:i]
+	}
+	return validationHints[field]
+}
+
+// strictWarnings returns settings that are legal but likely mistakes, reported by validate -strict
+// Values already rejected by validateConfig are not repeated as warnings.
+func strictWarnings(config *Config) ValidationErrors {
+	var warnings ValidationErrors
+	warn := func(path string, hint string, format string, args ...interface{}) {
+		warnings = append(warnings, ValidationError{Path: path, Message: fmt.Sprintf(format, args...), Hint: hint})
+	}
+
+	if config.PollingInterval >= 60 && config.PollingInterval < recommendedPollingInterval {
+		warn("polling_interval", fmt.Sprintf("use %d seconds or more unless fast detection is needed", recommendedPollingInterval),
+			"%ds polls every repository often and may exhaust API rate limits", config.PollingInterval)
+	}
+
+	if config.Global.InsecureSkipVerify {
+		warn("global.insecure_skip_verify", "set ca_cert_file to your internal CA instead",
+			"disables TLS certificate verification for every API call and git command")
+	}
+
+	usedGroups := make(map[string]bool)
+	for i, repo := range config.Repositories {
+		context := fmt.Sprintf("repositories[%d]", i)
+		usedGroups[repo.Group] = true
+
+		if repo.PollInterval >= 60 && repo.PollInterval < recommendedPollingInterval {
+			warn(context+".poll_interval", fmt.Sprintf("use %d seconds or more unless fast detection is needed", recommendedPollingInterval),
+				"%ds polls often and may exhaust API rate limits", repo.PollInterval)
+		}
+		if repo.Monitor.Auth.Username == "" && repo.Monitor.RepoType == "bitbucket" {
+			warn(context+".monitor.auth.username", "set the Bitbucket account name when the token is an app password",
+				"is empty; the token is used as a Bitbucket access token, app passwords need the account username")
+		}
+		if repo.Deploy.Auth.Username == "" && repo.Deploy.Auth.Type != authTypeGitHubApp && !hasTokenUsername(repo.Deploy.RepoType) {
+			warn(context+".deploy.auth.username", "set the account name owning the token",
+				"is empty; some Git hosts reject token clones without a username")
+		}
+	}
+
+	for _, groupName := range sortedGroupNames(config) {
+		if !usedGroups[groupName] {
+			warn("groups."+groupName, "assign repositories to the group or remove it",
+				"no repository belongs to this group")
+			continue
+		}
+		group := config.Groups[groupName]
+		if members := len(groupMembers(config, groupName)); group.ExecutionStrategy == "parallel" && group.MaxParallel > members {
+			warn("groups."+groupName+".max_parallel", fmt.Sprintf("use %d, the number of repositories in the group", members),
+				"%d exceeds the group's %d member repositories", group.MaxParallel, members)
+		}
+	}
+
+	return warnings
+}
+
+// writeValidationText prints validation errors and warnings, one per line with their hints
+func writeValidationText(w io.Writer, errs ValidationErrors, warnings ValidationErrors) error {
+	write := func(label string, problems ValidationErrors) error {
+		for _, problem := range problems {
+			if _, err := fmt.Fprintf(w, "[%s] %s\n", label, problem.Error()); err != nil {
+				return err
+			}
+			if problem.Hint != "" {
+				if _, err := fmt.Fprintf(w, "        hint: %s\n", problem.Hint); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := write("ERROR", errs); err != nil {
+		return err
+	}
+	if err := write("WARN", warnings); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d error(s), %d warning(s)\n", len(errs), len(warnings))
+	return err
+}
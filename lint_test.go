@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidationHint(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "polling_interval", expected: validationHints["polling_interval"]},
+		{path: "repositories[2].deploy.project_name", expected: validationHints["project_name"]},
+		{path: "repositories[0].deploy.commands[1].run", expected: validationHints["run"]},
+		{path: "repositories[0].monitor.branches[3]", expected: validationHints["branches"]},
+		{path: "repositories[0].deploy.substitutions.bad-key", expected: validationHints["substitutions"]},
+		{path: "repositories[0].deploy.env.1BAD", expected: validationHints["env"]},
+		{path: "unknown.field", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := validationHint(tt.path); got != tt.expected {
+				t.Errorf("validationHint(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateConfigReportsAllErrorsWithHints(t *testing.T) {
+	config := &Config{
+		PollingInterval: 10,
+		Repositories: []RepositoryConfig{
+			{
+				Name:    "repo-a",
+				Monitor: MonitorConfig{RepoURL: "https://github.com/test/repo-a", RepoType: "github", Branches: []string{"main"}, Auth: AuthConfig{Token: "token"}},
+				Deploy:  DeployConfig{QARepoURL: "https://github.com/test/qa", QARepoBranch: "main", RepoType: "github", Auth: AuthConfig{Token: "token"}, ProjectName: "repo-a", Commands: []CommandSpec{{Run: "echo ok"}}},
+			},
+			{
+				Name:    "repo-b",
+				Monitor: MonitorConfig{RepoURL: "https://github.com/test/repo-b", RepoType: "github", Branches: []string{"main"}, Auth: AuthConfig{Token: "token"}},
+				Deploy:  DeployConfig{QARepoURL: "https://github.com/test/qa", QARepoBranch: "main", RepoType: "github", Auth: AuthConfig{Token: "token"}, ProjectName: "repo-b", Commands: []CommandSpec{{Run: "echo ok"}}},
+			},
+			{
+				Name:    "repo-c",
+				Monitor: MonitorConfig{RepoURL: "https://github.com/test/repo-c", RepoType: "github", Branches: []string{"main"}},
+				Deploy:  DeployConfig{QARepoURL: "https://github.com/test/qa", QARepoBranch: "main", RepoType: "github", Auth: AuthConfig{Token: "token"}, ProjectName: "Repo_C"},
+			},
+		},
+	}
+
+	var validationErrs ValidationErrors
+	if err := validateConfig(config); !errors.As(err, &validationErrs) {
+		t.Fatalf("validateConfig() error = %v, want ValidationErrors", err)
+	}
+
+	wantPaths := []string{
+		"polling_interval",
+		"repositories[2].monitor.auth.token",
+		"repositories[2].deploy.project_name",
+		"repositories[2].deploy.commands",
+	}
+	if len(validationErrs) != len(wantPaths) {
+		t.Fatalf("validateConfig() reported %d problems, want %d: %v", len(validationErrs), len(wantPaths), validationErrs)
+	}
+	for i, path := range wantPaths {
+		if validationErrs[i].Path != path {
+			t.Errorf("problem %d path = %q, want %q", i, validationErrs[i].Path, path)
+		}
+		if validationErrs[i].Hint == "" {
+			t.Errorf("problem %d (%s) has no hint", i, path)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeValidationText(&buf, validationErrs, nil); err != nil {
+		t.Fatalf("writeValidationText() error = %v", err)
+	}
+	text := buf.String()
+	if got := strings.Count(text, "[ERROR] "); got != len(wantPaths) {
+		t.Errorf("text report has %d error lines, want %d:\n%s", got, len(wantPaths), text)
+	}
+	for _, want := range []string{"[ERROR] repositories[2].deploy.project_name: 'Repo_C'", "hint: " + validationHints["project_name"], "4 error(s), 0 warning(s)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestStrictWarnings(t *testing.T) {
+	config := &Config{
+		PollingInterval: 60,
+		Groups: map[string]GroupConfig{
+			"used":   {ExecutionStrategy: "parallel", MaxParallel: 1, GlobalTimeout: 600},
+			"unused": {ExecutionStrategy: "parallel", MaxParallel: 1, GlobalTimeout: 600},
+			"wide":   {ExecutionStrategy: "parallel", MaxParallel: 50, GlobalTimeout: 600},
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name:    "repo-a",
+				Group:   "used",
+				Monitor: MonitorConfig{RepoType: "github", Auth: AuthConfig{Token: "token"}},
+				Deploy:  DeployConfig{Auth: AuthConfig{Username: "qa", Token: "token"}},
+			},
+			{
+				Name:         "repo-b",
+				Group:        "wide",
+				PollInterval: 90,
+				Monitor:      MonitorConfig{RepoType: "bitbucket", Auth: AuthConfig{Token: "token"}},
+				Deploy:       DeployConfig{Auth: AuthConfig{Token: "token"}},
+			},
+		},
+	}
+
+	warnings := strictWarnings(config)
+
+	wantPaths := []string{
+		"polling_interval",
+		"repositories[1].poll_interval",
+		"repositories[1].monitor.auth.username",
+		"repositories[1].deploy.auth.username",
+		"groups.unused",
+		"groups.wide.max_parallel",
+	}
+	gotPaths := make([]string, len(warnings))
+	for i, warning := range warnings {
+		gotPaths[i] = warning.Path
+		if warning.Hint == "" {
+			t.Errorf("warning %s has no hint", warning.Path)
+		}
+	}
+	if strings.Join(gotPaths, ",") != strings.Join(wantPaths, ",") {
+		t.Errorf("strictWarnings() paths = %v, want %v", gotPaths, wantPaths)
+	}
+
+	// A relaxed config has nothing to warn about
+	config.PollingInterval = 300
+	config.Repositories = config.Repositories[:1]
+	delete(config.Groups, "unused")
+	delete(config.Groups, "wide")
+	if warnings := strictWarnings(config); len(warnings) != 0 {
+		t.Errorf("strictWarnings() = %v, want none", warnings)
+	}
+}
+
+func TestWriteValidationReportWarnings(t *testing.T) {
+	warnings := ValidationErrors{{Path: "polling_interval", Message: "60s polls often", Hint: "use 120 seconds or more"}}
+
+	var buf bytes.Buffer
+	if err := writeValidationReport(&buf, nil, warnings); err != nil {
+		t.Fatalf("writeValidationReport() error = %v", err)
+	}
+
+	var report ValidationReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if !report.Valid || len(report.Warnings) != 1 || report.Warnings[0].Hint != "use 120 seconds or more" {
+		t.Errorf("report = %+v, want valid with one hinted warning", report)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultLogMaxSizeMB  = 100 // Log file size that triggers rotation when log_max_size_mb is unset
+	defaultLogMaxBackups = 3   // Rotated log files kept when log_max_backups is unset
+)
+
+// rotatingFile is a log file that is renamed to <path>.1 once it grows past maxSize
+// Older backups shift to <path>.2 and so on; at most maxBackups are kept.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newRotatingFile opens (or appends to) the log file at path
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the current log file, creating its directory when needed
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when it would push the file past maxSize
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// An empty file is never rotated, so a single oversized line is still written
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		// A failed rotation keeps appending to the current file and is retried on the next write
+		r.rotate()
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts backups up by one, moves the current file to <path>.1 and reopens it.
+// The current file is only closed once it was moved away and its replacement is open.
+func (r *rotatingFile) rotate() error {
+	if r.maxBackups > 0 {
+		os.Remove(r.backupPath(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			os.Rename(r.backupPath(i), r.backupPath(i+1))
+		}
+		if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return fmt.Errorf("failed to truncate log file: %w", err)
+	}
+
+	// If the replacement can't be opened, logging carries on in the moved file
+	previous := r.file
+	if err := r.open(); err != nil {
+		return err
+	}
+	previous.Close()
+	return nil
+}
+
+// backupPath returns the name of the n-th most recent rotated log file
+func (r *rotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// Close closes the current log file
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// getLogMaxSize gets the log rotation size in bytes from global config or uses default
+func getLogMaxSize(config *Config) int64 {
+	sizeMB := defaultLogMaxSizeMB
+	if config.Global.LogMaxSizeMB > 0 {
+		sizeMB = config.Global.LogMaxSizeMB
+	}
+	return int64(sizeMB) * 1024 * 1024
+}
+
+// getLogMaxBackups gets how many rotated log files to keep from global config or uses default
+func getLogMaxBackups(config *Config) int {
+	if config.Global.LogMaxBackups != nil {
+		return *config.Global.LogMaxBackups
+	}
+	return defaultLogMaxBackups
+}
+
+// configureLogFile sends the global logger to global.log_file, teeing to console when log_stdout is set
+// It returns nil when no log file is configured.
+func configureLogFile(config *Config, console io.Writer) (io.Closer, error) {
+	if config.Global.LogFile == "" {
+		return nil, nil
+	}
+
+	file, err := newRotatingFile(config.Global.LogFile, getLogMaxSize(config), getLogMaxBackups(config))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Global.LogStdout {
+		AppLogger.SetOutput(io.MultiWriter(console, file))
+	} else {
+		AppLogger.SetOutput(file)
+	}
+	return file, nil
+}
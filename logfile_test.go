@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotates(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+	defer InitializeLogger(false)
+
+	path := filepath.Join(t.TempDir(), "logs", "sentry.log")
+	file, err := newRotatingFile(path, 200, 2)
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+	defer file.Close()
+
+	AppLogger.SetOutput(file)
+	for i := 0; i < 20; i++ {
+		AppLogger.InfoS("Repository check", "repo", "rotate-repo", "attempt", i)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("%s is %d bytes, want at most 200", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, found %s.3", path)
+	}
+
+	// The newest lines stay in the current file
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "[attempt=19]") {
+		t.Errorf("current log file missing the last line: %s", data)
+	}
+}
+
+func TestConfigureLogFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		logStdout   bool
+		wantConsole bool
+	}{
+		{"file only", false, false},
+		{"file and console", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Initialize logger for test
+			InitializeLogger(false)
+			defer InitializeLogger(false)
+
+			path := filepath.Join(t.TempDir(), "sentry.log")
+			config := &Config{Global: GlobalConfig{LogFile: path, LogStdout: tt.logStdout}}
+
+			var console bytes.Buffer
+			closer, err := configureLogFile(config, &console)
+			if err != nil {
+				t.Fatalf("configureLogFile() error = %v", err)
+			}
+			AppLogger.Info("hello file")
+			closer.Close()
+
+			data, _ := os.ReadFile(path)
+			if !strings.Contains(string(data), "hello file") {
+				t.Errorf("log file missing line: %q", data)
+			}
+			if got := strings.Contains(console.String(), "hello file"); got != tt.wantConsole {
+				t.Errorf("console output = %q, want logged %v", console.String(), tt.wantConsole)
+			}
+		})
+	}
+}
+
+func TestConfigureLogFileUnset(t *testing.T) {
+	closer, err := configureLogFile(&Config{}, os.Stdout)
+	if err != nil || closer != nil {
+		t.Errorf("configureLogFile() = %v, %v; want nil, nil without log_file", closer, err)
+	}
+}
+
+func TestRotatingFileKeepsLoggingWhenRotationFails(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+	defer InitializeLogger(false)
+
+	path := filepath.Join(t.TempDir(), "sentry.log")
+	file, err := newRotatingFile(path, 200, 1)
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+	defer file.Close()
+
+	// A non-empty directory in the backup slot makes every rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0755); err != nil {
+		t.Fatalf("failed to create backup directory: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		line := []byte(strings.Repeat("x", 60) + "\n")
+		if n, err := file.Write(line); err != nil || n != len(line) {
+			t.Fatalf("Write() = %d, %v; want %d, nil", n, err, len(line))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 10 {
+		t.Errorf("log file has %d lines, want all 10 kept after the failed rotation", got)
+	}
+}
@@ -2,8 +2,16 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -36,35 +44,165 @@ func (l LogLevel) String() string {
 	}
 }
 
+// ParseLogLevel converts a config log_level ("debug", "info", "warn" or "error") into a LogLevel
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	default:
+		return LogLevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+}
+
 // Logger provides structured logging functionality
 type Logger struct {
-	level   LogLevel
-	verbose bool
-	logger  *log.Logger
+	level      LogLevel
+	verbose    bool
+	showCaller bool // Prefix lines with the file:line that logged them (on in verbose mode)
+	logger     *log.Logger
 }
 
-// NewLogger creates a new logger instance
+// NewLogger creates a new logger instance writing to stdout
 func NewLogger(verbose bool) *Logger {
+	return NewLoggerWithWriter(os.Stdout, verbose)
+}
+
+// NewLoggerWithWriter creates a new logger instance writing to w
+func NewLoggerWithWriter(w io.Writer, verbose bool) *Logger {
 	level := LogLevelInfo
 	if verbose {
 		level = LogLevelDebug
 	}
 
 	return &Logger{
-		level:   level,
-		verbose: verbose,
-		logger:  log.New(os.Stdout, "", 0),
+		level:      level,
+		verbose:    verbose,
+		showCaller: verbose,
+		logger:     log.New(&redactingWriter{w: w}, "", 0),
+	}
+}
+
+// SetLevel changes the minimum level that is logged
+func (l *Logger) SetLevel(level LogLevel) {
+	l.level = level
+}
+
+// SetShowCaller toggles the file:line of the calling code on each log line
+func (l *Logger) SetShowCaller(show bool) {
+	l.showCaller = show
+}
+
+// callerLocation returns "file:line" of the first caller outside logger.go
+func callerLocation() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if filepath.Base(frame.File) != "logger.go" {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return "unknown:0"
+		}
 	}
 }
 
+// linePrefix builds the timestamp, level and optional caller that start every log line
+func (l *Logger) linePrefix(level LogLevel) string {
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	if l.showCaller {
+		return fmt.Sprintf("[%s] %s: %s: ", timestamp, level.String(), callerLocation())
+	}
+	return fmt.Sprintf("[%s] %s: ", timestamp, level.String())
+}
+
+// SetOutput redirects log lines, e.g. to stderr or a log file
+func (l *Logger) SetOutput(w io.Writer) {
+	l.logger.SetOutput(&redactingWriter{w: w})
+}
+
+// minRedactedSecretLength keeps trivially short values from masking unrelated text
+const minRedactedSecretLength = 4
+
+// secretRegistry holds the secret values masked in every log line
+type secretRegistry struct {
+	mu      sync.RWMutex
+	secrets []string // Longest first, so a secret containing another is masked whole
+}
+
+// logSecrets is the registry every Logger redacts with
+var logSecrets = &secretRegistry{}
+
+// Register adds secrets to mask; empty, short and already registered values are ignored
+func (r *secretRegistry) Register(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) < minRedactedSecretLength || slices.Contains(r.secrets, secret) {
+			continue
+		}
+		r.secrets = append(r.secrets, secret)
+	}
+	sort.SliceStable(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+}
+
+// Redact replaces every registered secret in text with ***
+func (r *secretRegistry) Redact(text string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, secret := range r.secrets {
+		text = strings.ReplaceAll(text, secret, "***")
+	}
+	return text
+}
+
+// registerConfigSecrets adds every credential of config to the log secret registry
+// Tokens of earlier configs stay registered, since they may still appear in in-flight output.
+func registerConfigSecrets(config *Config) {
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		registerAuthSecrets(repo.Monitor.Auth)
+		registerAuthSecrets(repo.Deploy.Auth)
+		logSecrets.Register(repo.WebhookSecret, repo.Deploy.Pipeline.TriggerToken, repo.WebhookURL)
+	}
+
+	// Webhook URLs carry their credential in the path, e.g. Slack incoming webhooks
+	notifications := &config.Global.Notifications
+	logSecrets.Register(notifications.SlackWebhookURL)
+	for _, target := range notifications.Targets {
+		logSecrets.Register(target.URL)
+	}
+}
+
+// redactingWriter masks registered secrets in each log line before passing it on
+// log.Logger writes a whole line per call, so a secret is never split across writes.
+type redactingWriter struct {
+	w io.Writer
+}
+
+// Write redacts p and writes it to the underlying writer
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, logSecrets.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // logf formats and logs a message at the specified level
 func (l *Logger) logf(level LogLevel, format string, args ...interface{}) {
 	if level < l.level {
 		return
 	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	prefix := fmt.Sprintf("[%s] %s: ", timestamp, level.String())
+	prefix := l.linePrefix(level)
 	message := fmt.Sprintf(format, args...)
 
 	l.logger.Printf("%s%s", prefix, message)
@@ -102,8 +240,7 @@ func (l *Logger) StructuredLog(level LogLevel, message string, kvPairs ...interf
 		return
 	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	prefix := fmt.Sprintf("[%s] %s: ", timestamp, level.String())
+	prefix := l.linePrefix(level)
 
 	// Build structured message
 	var structuredMessage string
@@ -152,7 +289,7 @@ func (l *Logger) DebugS(message string, kvPairs ...interface{}) {
 // LogRepositoryCheck logs repository monitoring activity
 func (l *Logger) LogRepositoryCheck(repoKey string, success bool, commitSHA string, author string) {
 	if success {
-		l.Info("Repository %s check successful - Latest commit: %s by %s", repoKey, commitSHA[:8], author)
+		l.Info("Repository %s check successful - Latest commit: %s by %s", repoKey, shortSHA(commitSHA), author)
 	} else {
 		l.Warn("Repository %s check failed", repoKey)
 	}
@@ -188,8 +325,9 @@ func (l *Logger) LogCleanup(path string, success bool) {
 }
 
 // LogAPICall logs API call information
+// 304 Not Modified answers a conditional poll with nothing new, so it counts as a success.
 func (l *Logger) LogAPICall(service string, url string, statusCode int, duration time.Duration) {
-	if statusCode >= 200 && statusCode < 300 {
+	if statusCode >= 200 && statusCode < 300 || statusCode == http.StatusNotModified {
 		l.Debug("API call successful: %s %s - %d (%v)", service, url, statusCode, duration)
 	} else {
 		l.Warn("API call failed: %s %s - %d (%v)", service, url, statusCode, duration)
@@ -213,3 +351,13 @@ var AppLogger *Logger
 func InitializeLogger(verbose bool) {
 	AppLogger = NewLogger(verbose)
 }
+
+// configureLogLevel applies global.log_level to the global logger; -verbose still forces debug
+func configureLogLevel(config *Config, verbose bool) {
+	if verbose || config.Global.LogLevel == "" {
+		return
+	}
+	if level, err := ParseLogLevel(config.Global.LogLevel); err == nil {
+		AppLogger.SetLevel(level)
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
+	"time"
 )
 
 // Application version information (can be overridden at build time)
@@ -18,9 +23,26 @@ var (
 
 // AppConfig holds application runtime configuration
 type AppConfig struct {
-	Action     string
-	ConfigPath string
-	Verbose    bool
+	Action        string
+	ConfigPath    string
+	Verbose       bool
+	Repo          string
+	Group         string
+	Branch        string
+	Output        string
+	Strict        bool
+	EnvFile       string
+	AllowUnsetEnv bool
+	FailFast      bool
+	Once          bool
+	StatePath     string
+	Force         bool
+	All           bool
+}
+
+// configLoadOptions returns how the configuration file should be loaded
+func (a *AppConfig) configLoadOptions() ConfigLoadOptions {
+	return ConfigLoadOptions{EnvFile: a.EnvFile, AllowUnsetEnv: a.AllowUnsetEnv}
 }
 
 // SentryApp represents the main application
@@ -28,6 +50,7 @@ type SentryApp struct {
 	config         *Config
 	monitorService *MonitorService
 	deployService  *DeployService
+	statusServer   *StatusServer // Optional /healthz, /metrics and /status server (nil when http_addr is unset)
 	appConfig      *AppConfig
 }
 
@@ -38,29 +61,93 @@ func main() {
 	// Setup logging
 	InitializeLogger(appConfig.Verbose)
 
-	// Print banner
-	printBanner()
+	// init scaffolds the config, so it runs before one is loaded and without the banner polluting -output=-
+	if appConfig.Action == "init" {
+		if err := initConfigAction(appConfig, os.Stdout); err != nil {
+			AppLogger.Fatal("Action failed: %v", err)
+		}
+		return
+	}
+
+	console := io.Writer(os.Stdout)
+	if appConfig.Output == "json" {
+		// Keep stdout machine-readable; logs go to stderr
+		console = os.Stderr
+		AppLogger.SetOutput(console)
+	} else {
+		// Print banner
+		printBanner()
+	}
 
 	// Load configuration
-	config, err := LoadConfig(appConfig.ConfigPath)
+	config, err := LoadConfig(appConfig.ConfigPath, appConfig.configLoadOptions())
 	if err != nil {
+		var validationErrs ValidationErrors
+		if appConfig.Action == "validate" && errors.As(err, &validationErrs) {
+			// Report every problem at once, each with its path and a hint
+			if err := writeValidationProblems(os.Stdout, appConfig.Output, validationErrs, strictConfigWarnings(appConfig)); err != nil {
+				AppLogger.Fatal("Failed to write validation report: %v", err)
+			}
+			os.Exit(1)
+		}
+		if appConfig.Action == "doctor" {
+			// Report the broken config alongside the checks that do not need it
+			if err := runDoctor(nil, err, appConfig.Output); err != nil {
+				AppLogger.Fatal("Action failed: %v", err)
+			}
+			return
+		}
 		AppLogger.Fatal("Failed to load configuration: %v", err)
 	}
 
+	configureLogLevel(config, appConfig.Verbose)
+
+	// Write logs to a rotating file when configured
+	logFile, err := configureLogFile(config, console)
+	if err != nil {
+		AppLogger.Fatal("Failed to open log file: %v", err)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
+
 	// Create services - order matters: deploy service first, then monitor service
 	deployService := NewDeployService(config)
+	if config.Global.DBPath != "" {
+		if err := deployService.EnableResultStore(config.Global.DBPath); err != nil {
+			AppLogger.Fatal("Failed to open result store: %v", err)
+		}
+		defer deployService.Close()
+	}
 	monitorService := NewMonitorService(config, deployService)
 
+	var statusServer *StatusServer
+	if config.Global.HTTPAddr != "" {
+		metrics := NewMetrics()
+		deployService.SetMetrics(metrics)
+		monitorService.SetMetrics(metrics)
+		statusServer = NewStatusServer(config.Global.HTTPAddr, metrics, deployService)
+	}
+
 	// Create application instance
 	app := &SentryApp{
 		config:         config,
 		monitorService: monitorService,
 		deployService:  deployService,
+		statusServer:   statusServer,
 		appConfig:      appConfig,
 	}
 
 	// Execute requested action
 	if err := app.executeAction(); err != nil {
+		if appConfig.Action == "validate" {
+			// Distinct exit codes let scripts tell bad credentials from outages and missing branches or repositories
+			AppLogger.Error("Action failed: %v", err)
+			if hint := accessProblemHint(err); hint != "" {
+				AppLogger.Error("Hint: %s", hint)
+			}
+			os.Exit(validateExitCode(err))
+		}
 		AppLogger.Fatal("Action failed: %v", err)
 	}
 }
@@ -70,9 +157,21 @@ func parseCommandLineArgs() *AppConfig {
 	var appConfig AppConfig
 
 	// Define command line flags
-	flag.StringVar(&appConfig.Action, "action", "", "Action to perform: watch, trigger, validate")
+	flag.StringVar(&appConfig.Action, "action", "", "Action to perform: watch, trigger, validate, doctor, status, reset-breaker, export-state, import-state, init")
 	flag.StringVar(&appConfig.ConfigPath, "config", "sentry.yaml", "Path to configuration file")
+	flag.StringVar(&appConfig.EnvFile, "env-file", "", "Path to a .env file loaded before the config (default ./.env if present)")
 	flag.BoolVar(&appConfig.Verbose, "verbose", false, "Enable verbose logging")
+	flag.StringVar(&appConfig.Repo, "repo", "", "Repository name (reset-breaker; trigger deploys only this repository)")
+	flag.StringVar(&appConfig.Group, "group", "", "Group name (trigger deploys only this group)")
+	flag.StringVar(&appConfig.Branch, "branch", "", "Branch name (reset-breaker; all branches when omitted)")
+	flag.StringVar(&appConfig.Output, "output", "text", "Output format: text, json (validate, doctor, status, trigger)")
+	flag.BoolVar(&appConfig.Strict, "strict", false, "Also warn about suspicious but legal settings (validate)")
+	flag.BoolVar(&appConfig.FailFast, "fail-fast", false, "Stop at the first failed deployment (trigger)")
+	flag.BoolVar(&appConfig.Once, "once", false, "Run a single check cycle and exit (watch)")
+	flag.StringVar(&appConfig.StatePath, "state", "", "JSON file of last seen commits to import, - for stdin (import-state)")
+	flag.BoolVar(&appConfig.All, "all", false, "Also test repositories with enabled: false (validate)")
+	flag.BoolVar(&appConfig.Force, "force", false, "Overwrite an existing -config file (init)")
+	flag.BoolVar(&appConfig.AllowUnsetEnv, "allow-unset-env", false, "Expand unset ${VAR} references in the config to empty strings instead of failing")
 
 	// Add help flag
 	showHelp := flag.Bool("help", false, "Show help information")
@@ -99,7 +198,7 @@ func parseCommandLineArgs() *AppConfig {
 	}
 
 	// Validate action value
-	validActions := []string{"watch", "trigger", "validate"}
+	validActions := []string{"watch", "trigger", "validate", "doctor", "status", "reset-breaker", "export-state", "import-state", "init"}
 	actionValid := false
 	for _, validAction := range validActions {
 		if appConfig.Action == validAction {
@@ -114,6 +213,13 @@ func parseCommandLineArgs() *AppConfig {
 		os.Exit(1)
 	}
 
+	// init alone accepts -output=- to print the example to stdout
+	if appConfig.Output != "text" && appConfig.Output != "json" && !(appConfig.Action == "init" && appConfig.Output == initOutputStdout) {
+		fmt.Fprintf(os.Stderr, "Error: invalid output '%s'. Valid outputs: text, json\n\n", appConfig.Output)
+		printUsage()
+		os.Exit(1)
+	}
+
 	return &appConfig
 }
 
@@ -123,9 +229,19 @@ func (app *SentryApp) executeAction() error {
 	case "validate":
 		return app.validateAction()
 	case "trigger":
-		return app.triggerAction()
+		return app.triggerAction(os.Stdout)
 	case "watch":
 		return app.watchAction()
+	case "doctor":
+		return runDoctor(app.config, nil, app.appConfig.Output)
+	case "status":
+		return app.statusAction(os.Stdout)
+	case "reset-breaker":
+		return app.resetBreakerAction()
+	case "export-state":
+		return app.exportStateAction(os.Stdout)
+	case "import-state":
+		return app.importStateAction(os.Stdin)
 	default:
 		return fmt.Errorf("unknown action: %s", app.appConfig.Action)
 	}
@@ -135,10 +251,24 @@ func (app *SentryApp) executeAction() error {
 func (app *SentryApp) validateAction() error {
 	AppLogger.Info("Starting configuration and environment validation...")
 
+	if app.appConfig.Output == "json" {
+		return app.validateActionJSON(os.Stdout)
+	}
+
+	if app.appConfig.Strict {
+		if err := writeValidationText(os.Stdout, nil, strictWarnings(app.config)); err != nil {
+			return fmt.Errorf("failed to write validation report: %w", err)
+		}
+	}
+
 	// Test repository connectivity for all configured repositories
 	AppLogger.Info("Testing repository connectivity...")
 
 	for _, repo := range app.config.Repositories {
+		if !app.testsConnectivity(&repo) {
+			continue
+		}
+
 		// Test monitor repository connectivity
 		if err := app.testRepositoryConnectivity(&repo.Monitor, fmt.Sprintf("Monitor repo %s", repo.Name)); err != nil {
 			return fmt.Errorf("monitor repository %s connectivity test failed: %w", repo.Name, err)
@@ -154,29 +284,129 @@ func (app *SentryApp) validateAction() error {
 	return nil
 }
 
-// triggerAction manually triggers deployment for all configured repositories
-func (app *SentryApp) triggerAction() error {
+// validateActionJSON tests every repository and emits all problems and per-repository results as a JSON report
+// The configuration itself has already been validated by LoadConfig
+func (app *SentryApp) validateActionJSON(w io.Writer) error {
+	report := ValidationReport{Repositories: make([]RepositoryConnectivity, 0, len(app.config.Repositories))}
+	var connectivityErrs []error
+
+	for i, repo := range app.config.Repositories {
+		if !app.testsConnectivity(&repo) {
+			continue
+		}
+		context := fmt.Sprintf("repositories[%d]", i)
+
+		monitorErr := app.testRepositoryConnectivity(&repo.Monitor, fmt.Sprintf("Monitor repo %s", repo.Name))
+		if monitorErr != nil {
+			report.Errors = append(report.Errors, connectivityProblem(context+".monitor", monitorErr))
+			connectivityErrs = append(connectivityErrs, monitorErr)
+		}
+
+		deployErr := app.testQARepositoryConnectivity(&repo.Deploy, fmt.Sprintf("Deploy repo %s", repo.Name))
+		if deployErr != nil {
+			report.Errors = append(report.Errors, connectivityProblem(context+".deploy", deployErr))
+			connectivityErrs = append(connectivityErrs, deployErr)
+		}
+
+		report.Repositories = append(report.Repositories, RepositoryConnectivity{
+			Name:    repo.Name,
+			Monitor: newConnectivityResult(monitorErr),
+			Deploy:  newConnectivityResult(deployErr),
+		})
+	}
+
+	if app.appConfig.Strict {
+		report.Warnings = strictWarnings(app.config)
+	}
+
+	if err := encodeValidationReport(w, report); err != nil {
+		return fmt.Errorf("failed to write validation report: %w", err)
+	}
+
+	if len(report.Errors) > 0 {
+		// Keep the underlying errors so the exit code reflects their category
+		return fmt.Errorf("validation found %d problem(s): %w", len(report.Errors), errors.Join(connectivityErrs...))
+	}
+	return nil
+}
+
+// testsConnectivity reports whether validate checks the repository's connectivity
+// Disabled repositories are skipped unless -all is set, since they may point at hosts under maintenance.
+func (app *SentryApp) testsConnectivity(repo *RepositoryConfig) bool {
+	if repo.IsEnabled() || app.appConfig.All {
+		return true
+	}
+	AppLogger.InfoS("Skipping connectivity test of disabled repository", "repo", repo.GetDisplayName())
+	return false
+}
+
+// connectivityProblem reports a failed connectivity test at path with a hint matching its cause
+func connectivityProblem(path string, err error) ValidationError {
+	return ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf("connectivity test failed: %v", err),
+		Hint:    accessProblemHint(err),
+	}
+}
+
+// writeValidationProblems prints configuration errors and warnings as text or JSON
+func writeValidationProblems(w io.Writer, output string, errs ValidationErrors, warnings ValidationErrors) error {
+	if output == "json" {
+		return writeValidationReport(w, errs, warnings)
+	}
+	return writeValidationText(w, errs, warnings)
+}
+
+// strictConfigWarnings lints a configuration that failed validation, when -strict is set
+func strictConfigWarnings(appConfig *AppConfig) ValidationErrors {
+	if !appConfig.Strict {
+		return nil
+	}
+	config, err := parseConfig(appConfig.ConfigPath, appConfig.configLoadOptions())
+	if err != nil {
+		return nil
+	}
+	return strictWarnings(config)
+}
+
+// triggerAction deploys every selected group and repository, then prints a summary of the outcomes
+// All targets are attempted unless -fail-fast is set; the error reports how many failed.
+func (app *SentryApp) triggerAction(w io.Writer) error {
 	AppLogger.Info("Starting manual deployment trigger...")
 
-	// Group repositories by their groups
-	groups := make(map[string][]string)
-	individual := make([]string, 0)
+	groups, individual, err := app.triggerTargets()
+	if err != nil {
+		return err
+	}
 
-	for _, repo := range app.config.Repositories {
-		if repo.Group != "" {
-			groups[repo.Group] = append(groups[repo.Group], repo.Name)
-		} else {
-			individual = append(individual, repo.Name)
+	app.cleanupStaleTempDirectories()
+
+	var outcomes []triggerOutcome
+	failFast := func() error {
+		if err := writeTriggerOutput(w, app.appConfig.Output, outcomes); err != nil {
+			return fmt.Errorf("failed to write trigger summary: %w", err)
 		}
+		last := outcomes[len(outcomes)-1]
+		return fmt.Errorf("%s %s deployment failed: %s", last.Kind, last.Name, last.Error)
+	}
+
+	// Trigger group deployments in a stable order
+	groupNames := make([]string, 0, len(groups))
+	for groupName := range groups {
+		groupNames = append(groupNames, groupName)
 	}
+	sort.Strings(groupNames)
 
-	// Trigger group deployments
-	for groupName, repoNames := range groups {
+	for _, groupName := range groupNames {
+		repoNames := groups[groupName]
 		groupConfig := app.config.Groups[groupName]
 		AppLogger.InfoS("Triggering group deployment", "group", groupName, "repositories", repoNames)
 
-		if err := app.deployService.DeployGroup(groupName, repoNames, &groupConfig); err != nil {
-			return fmt.Errorf("group %s deployment failed: %w", groupName, err)
+		startTime := time.Now()
+		result, err := app.deployService.deployGroup(context.Background(), groupName, repoNames, &groupConfig, nil)
+		outcomes = append(outcomes, newTriggerOutcome("group", groupName, startTime, result, err))
+		if err != nil && app.appConfig.FailFast {
+			return failFast()
 		}
 	}
 
@@ -186,47 +416,235 @@ func (app *SentryApp) triggerAction() error {
 
 		// Find repo config
 		var repoConfig *RepositoryConfig
-		for _, repo := range app.config.Repositories {
-			if repo.Name == repoName {
-				repoConfig = &repo
+		for i := range app.config.Repositories {
+			if app.config.Repositories[i].Name == repoName {
+				repoConfig = &app.config.Repositories[i]
 				break
 			}
 		}
 
-		if repoConfig == nil {
-			return fmt.Errorf("repository configuration not found: %s", repoName)
+		startTime := time.Now()
+		err := fmt.Errorf("repository configuration not found: %s", repoName)
+		result := &DeployResult{RepoName: repoName, Error: err.Error()}
+		if repoConfig != nil {
+			result, err = app.deployService.deployIndividual(context.Background(), repoConfig, nil)
+		}
+		outcomes = append(outcomes, newTriggerOutcome("repository", repoName, startTime, result, err))
+		if err != nil && app.appConfig.FailFast {
+			return failFast()
 		}
+	}
+
+	if err := writeTriggerOutput(w, app.appConfig.Output, outcomes); err != nil {
+		return fmt.Errorf("failed to write trigger summary: %w", err)
+	}
 
-		if err := app.deployService.DeployIndividual(repoConfig); err != nil {
-			return fmt.Errorf("individual deployment %s failed: %w", repoName, err)
+	failed := 0
+	for _, outcome := range outcomes {
+		if !outcome.Success {
+			failed++
 		}
 	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deployment(s) failed", failed, len(outcomes))
+	}
 
 	AppLogger.Info("Manual deployment trigger completed successfully!")
 	return nil
 }
 
+// triggerTargets selects the groups and individual repositories a manual trigger deploys
+// -repo deploys just that repository (even if it belongs to a group) and -group just that group;
+// without either every repository and group is deployed.
+func (app *SentryApp) triggerTargets() (map[string][]string, []string, error) {
+	repoName, groupName := app.appConfig.Repo, app.appConfig.Group
+	if repoName != "" && groupName != "" {
+		return nil, nil, fmt.Errorf("use only one of -repo and -group")
+	}
+
+	groups := make(map[string][]string)
+	individual := make([]string, 0)
+
+	for _, repo := range app.config.Repositories {
+		if !repo.IsEnabled() {
+			if repoName == repo.Name {
+				return nil, nil, fmt.Errorf("repository %s is disabled (enabled: false)", repoName)
+			}
+			AppLogger.InfoS("Skipping disabled repository", "repo", repo.GetDisplayName())
+			continue
+		}
+		switch {
+		case repoName != "":
+			if repo.Name == repoName {
+				individual = append(individual, repo.Name)
+			}
+		case groupName != "":
+			if repo.Group == groupName {
+				groups[repo.Group] = append(groups[repo.Group], repo.Name)
+			}
+		case repo.Group != "":
+			groups[repo.Group] = append(groups[repo.Group], repo.Name)
+		default:
+			individual = append(individual, repo.Name)
+		}
+	}
+
+	if repoName != "" && len(individual) == 0 {
+		return nil, nil, fmt.Errorf("repository configuration not found: %s", repoName)
+	}
+	if groupName != "" && len(groups) == 0 {
+		return nil, nil, fmt.Errorf("group not found or has no repositories: %s", groupName)
+	}
+	return groups, individual, nil
+}
+
 // watchAction starts continuous monitoring of repositories
 func (app *SentryApp) watchAction() error {
+	if app.appConfig.Once {
+		return app.watchOnce()
+	}
+
 	AppLogger.Info("Starting continuous repository monitoring...")
 
-	// Setup signal handling for graceful shutdown
+	app.cleanupStaleTempDirectories()
+
+	if app.statusServer != nil {
+		app.statusServer.Start()
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := app.statusServer.Shutdown(ctx); err != nil {
+				AppLogger.WarnS("Failed to stop status server", "error", err)
+			}
+		}()
+	}
+
+	// Setup signal handling for graceful shutdown and config reloads
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	// Cancelled on shutdown so in-flight deployments stop starting new commands
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if usesPushWebhooks(app.config) {
+		receiver := NewWebhookReceiver(ctx, getWebhookAddr(app.config), app.monitorService)
+		receiver.Start()
+		defer func() {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer shutdownCancel()
+			if err := receiver.Shutdown(shutdownCtx); err != nil {
+				AppLogger.WarnS("Failed to stop push webhook receiver", "error", err)
+			}
+		}()
+	}
 
 	// Start monitoring in a goroutine
 	monitorChan := make(chan error, 1)
 	go func() {
-		monitorChan <- app.startMonitoring()
+		monitorChan <- app.startMonitoring(ctx)
+	}()
+
+	// Reload the config when its file changes, e.g. an updated ConfigMap volume
+	var configWatcher *configFileWatcher
+	var configChecks <-chan time.Time
+	if interval := getConfigCheckInterval(app.config); interval > 0 {
+		configWatcher = newConfigFileWatcher(app.appConfig.ConfigPath)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		configChecks = ticker.C
+	}
+
+	// Wait for a shutdown signal or monitor error, reloading the config on SIGHUP or a file change
+	for {
+		select {
+		case <-configChecks:
+			app.reloadIfConfigChanged(configWatcher)
+		case sig := <-signalChan:
+			if sig == syscall.SIGHUP {
+				AppLogger.Info("Received SIGHUP")
+				if configWatcher != nil {
+					// Take in the current content so the next check does not reload it again
+					configWatcher.changed()
+				}
+				app.reloadConfig()
+				continue
+			}
+			AppLogger.Info("Received signal %v, shutting down gracefully...", sig)
+			// A reload during shutdown must not count as the second, impatient signal
+			signal.Ignore(syscall.SIGHUP)
+			cancel()
+			app.drainDeployments(signalChan)
+
+			// Let the poll loop observe the cancellation and exit cleanly
+			select {
+			case <-monitorChan:
+			case <-time.After(shutdownCleanupMargin):
+				AppLogger.Warn("Monitoring loop did not stop in time")
+			}
+			return nil
+		case err := <-monitorChan:
+			return fmt.Errorf("monitoring failed: %w", err)
+		}
+	}
+}
+
+// shutdownCleanupMargin is extra time after the grace period for killed deployments to clean up
+const shutdownCleanupMargin = 10 * time.Second
+
+// drainDeployments waits for in-flight deployments to finish; a second signal stops waiting
+func (app *SentryApp) drainDeployments(signalChan <-chan os.Signal) {
+	grace := getShutdownGracePeriod(app.config)
+	AppLogger.Info("Waiting up to %s for in-flight deployments to finish...", grace)
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- app.deployService.WaitForDeployments(grace + shutdownCleanupMargin)
 	}()
 
-	// Wait for either signal or monitor error
 	select {
+	case drained := <-done:
+		if !drained {
+			AppLogger.Warn("In-flight deployments did not finish within the shutdown grace period")
+		}
 	case sig := <-signalChan:
-		AppLogger.Info("Received signal %v, shutting down gracefully...", sig)
-		return nil
-	case err := <-monitorChan:
-		return fmt.Errorf("monitoring failed: %w", err)
+		AppLogger.Warn("Received signal %v again, exiting without waiting for deployments", sig)
+	}
+}
+
+// resetBreakerAction clears branch breaker suppression for a repository
+func (app *SentryApp) resetBreakerAction() error {
+	if app.appConfig.Repo == "" {
+		return fmt.Errorf("-repo is required for reset-breaker")
+	}
+
+	found := false
+	for _, repo := range app.config.Repositories {
+		if repo.Name == app.appConfig.Repo {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("repository configuration not found: %s", app.appConfig.Repo)
+	}
+
+	cleared, err := app.monitorService.ResetBreaker(app.appConfig.Repo, app.appConfig.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to reset breaker: %w", err)
+	}
+
+	AppLogger.InfoS("Branch breaker reset",
+		"repo", app.appConfig.Repo,
+		"branch", app.appConfig.Branch,
+		"cleared", cleared)
+	return nil
+}
+
+// cleanupStaleTempDirectories sweeps temp directories left behind by a previous run
+func (app *SentryApp) cleanupStaleTempDirectories() {
+	if _, err := app.deployService.CleanupStale(); err != nil {
+		AppLogger.WarnS("Failed to sweep orphaned temp directories", "error", err)
 	}
 }
 
@@ -238,6 +656,11 @@ func (app *SentryApp) testRepositoryConnectivity(monitor *MonitorConfig, repoNam
 	for _, branch := range monitor.Branches {
 		// Try to get latest commit to test connectivity
 		commit, err := app.monitorService.GetLatestCommit(monitor, branch)
+		if errors.Is(err, ErrEmptyRepository) {
+			// Reachable; monitoring starts deploying once the first commit is pushed
+			AppLogger.InfoS("Repository is reachable but has no commits yet", "repo", repoName, "branch", branch)
+			continue
+		}
 		if err != nil {
 			return fmt.Errorf("failed to access repository %s branch %s: %w", repoName, branch, err)
 		}
@@ -270,25 +693,25 @@ func (app *SentryApp) testQARepositoryConnectivity(deploy *DeployConfig, repoNam
 }
 
 // startMonitoring starts the continuous monitoring process with deployment integration
-func (app *SentryApp) startMonitoring() error {
+func (app *SentryApp) startMonitoring(ctx context.Context) error {
 	// Create a custom monitoring loop that integrates with deployment
 	AppLogger.Info("Initializing monitoring services...")
 
 	// Perform initial repository check
-	if err := app.monitorService.CheckAllRepositories(); err != nil {
+	if err := app.monitorService.CheckAllRepositories(ctx); err != nil {
 		return fmt.Errorf("initial repository check failed: %w", err)
 	}
 
 	// Create monitoring loop with deployment integration
-	return app.runMonitoringLoop()
+	return app.runMonitoringLoop(ctx)
 }
 
 // runMonitoringLoop runs the main monitoring loop with deployment triggers
-func (app *SentryApp) runMonitoringLoop() error {
+func (app *SentryApp) runMonitoringLoop(ctx context.Context) error {
 	AppLogger.Info("Starting monitoring loop (checking every %d seconds)...", app.config.PollingInterval)
 
 	// Use the MonitorService which now includes deployment triggering
-	return app.monitorService.StartMonitoring()
+	return app.monitorService.StartMonitoring(ctx)
 }
 
 // printVersionInfo prints detailed version information
@@ -318,17 +741,62 @@ Actions:
   validate    Validate configuration and environment
   trigger     Manually trigger deployment from all repositories  
   watch       Start continuous monitoring of repositories
+  doctor      Check git, tmp_dir, command binaries and repository access
+  status      Show the latest commit of every monitored branch
+  reset-breaker  Clear deploy suppression for a failing branch
+  export-state   Print the last seen commits of the commit state file as JSON
+  import-state   Replace the commit state file with the JSON given by -state
+  init        Write an example configuration to -config, or to stdout with -output=-
 
 Options:
   -config     Path to configuration file (default: sentry.yaml)
+  -env-file   Path to a .env file loaded before the config (default: ./.env if present)
+  -allow-unset-env  Expand unset ${VAR} references to empty strings instead of failing
   -verbose    Enable verbose logging (default: false)
+  -repo       Repository name (reset-breaker; trigger deploys only this repository)
+  -group      Group name (trigger deploys only this group)
+  -branch     Branch name (reset-breaker; all branches when omitted)
+  -strict     validate also warns about suspicious but legal settings
+  -fail-fast  trigger stops at the first failed deployment instead of attempting all
+  -once       watch runs a single check cycle, deploying only what changed, then exits
+  -state      import-state reads this JSON file of last seen commits, - for stdin
+  -force      init overwrites an existing -config file
+  -all        validate also tests repositories with enabled: false
+  -output     Output format: text or json (default: text); validate -output=json
+              reports every configuration problem as {path, message, hint} objects
+              and each repository's connectivity under "repositories",
+              doctor -output=json reports every check as {name, passed, detail},
+              status -output=json reports every branch as {repo, branch, sha, ...},
+              trigger -output=json emits every group and repository deploy result
   -help       Show this help information
   -version    Show version information
 
+Exit codes (validate):
+  1  Configuration or other failure
+  3  Authentication rejected (HTTP 401/403)
+  4  Repository host unreachable
+  5  Branch not found
+  6  Repository not found, or not visible to the token
+
 Examples:
+  sentry -action=init -config=sentry.yaml
+  sentry -action=init -output=- > sentry.yaml
   sentry -action=validate
+  sentry -action=validate -output=json
+  sentry -action=validate -strict
+  sentry -action=doctor
+  sentry -action=status
   sentry -action=trigger -config=my-config.yaml
+  sentry -action=trigger -repo=my-repo
+  sentry -action=trigger -group=frontend
+  sentry -action=trigger -fail-fast
+  sentry -action=trigger -output=json
+  sentry -action=watch -env-file=.env.staging
   sentry -action=watch -verbose
+  sentry -action=watch -once
+  sentry -action=reset-breaker -repo=my-repo -branch=main
+  sentry -action=export-state > state.json
+  sentry -action=import-state -state=state.json
 
 Environment Variables:
   GITHUB_TOKEN    GitHub personal access token
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newTriggerTestApp returns an app with ungrouped "solo" and grouped "web-a"/"web-b" repositories
+// Each deployment touches a marker named after its repository in the returned directory.
+func newTriggerTestApp(t *testing.T, repo string, group string) (*SentryApp, string) {
+	markers := t.TempDir()
+	config := newDrainTestConfig(t, nil)
+	template := config.Repositories[0]
+	config.Repositories = nil
+
+	for _, r := range []struct{ name, group string }{{"solo", ""}, {"web-a", "web"}, {"web-b", "web"}} {
+		repoConfig := template
+		repoConfig.Name = r.name
+		repoConfig.Group = r.group
+		repoConfig.Deploy.Commands = []CommandSpec{{Run: "touch " + filepath.Join(markers, r.name)}}
+		config.Repositories = append(config.Repositories, repoConfig)
+	}
+	config.Groups = map[string]GroupConfig{"web": {ExecutionStrategy: "sequential", GlobalTimeout: 60}}
+
+	deployService := NewDeployService(config)
+	return &SentryApp{
+		config:         config,
+		monitorService: NewMonitorService(config, deployService),
+		deployService:  deployService,
+		appConfig:      &AppConfig{Action: "trigger", Repo: repo, Group: group},
+	}, markers
+}
+
+func TestTriggerActionScope(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		repo     string
+		group    string
+		wantErr  string
+		deployed []string
+	}{
+		{"all repositories", "", "", "", []string{"solo", "web-a", "web-b"}},
+		{"single repository", "solo", "", "", []string{"solo"}},
+		{"single grouped repository", "web-b", "", "", []string{"web-b"}},
+		{"single group", "", "web", "", []string{"web-a", "web-b"}},
+		{"unknown repository", "missing", "", "repository configuration not found: missing", nil},
+		{"unknown group", "", "missing", "group not found or has no repositories: missing", nil},
+		{"repo and group", "solo", "web", "use only one of -repo and -group", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, markers := newTriggerTestApp(t, tt.repo, tt.group)
+
+			err := app.triggerAction(io.Discard)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("triggerAction() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("triggerAction() error = %v", err)
+			}
+
+			entries, _ := os.ReadDir(markers)
+			var deployed []string
+			for _, entry := range entries {
+				deployed = append(deployed, entry.Name())
+			}
+			sort.Strings(deployed)
+			if strings.Join(deployed, ",") != strings.Join(tt.deployed, ",") {
+				t.Errorf("deployed %v, want %v", deployed, tt.deployed)
+			}
+		})
+	}
+}
+
+func TestTriggerActionSkipsDisabled(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name     string
+		repo     string
+		group    string
+		disabled []string
+		wantErr  string
+		deployed []string
+	}{
+		{"all repositories", "", "", []string{"solo", "web-b"}, "", []string{"web-a"}},
+		{"single group", "", "web", []string{"web-a"}, "", []string{"web-b"}},
+		{"disabled repository named", "solo", "", []string{"solo"}, "repository solo is disabled", nil},
+		{"group without enabled repositories", "", "web", []string{"web-a", "web-b"}, "group not found or has no repositories: web", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, markers := newTriggerTestApp(t, tt.repo, tt.group)
+			enabled := false
+			for i := range app.config.Repositories {
+				if slices.Contains(tt.disabled, app.config.Repositories[i].Name) {
+					app.config.Repositories[i].Enabled = &enabled
+				}
+			}
+
+			err := app.triggerAction(io.Discard)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("triggerAction() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("triggerAction() error = %v", err)
+			}
+
+			entries, _ := os.ReadDir(markers)
+			var deployed []string
+			for _, entry := range entries {
+				deployed = append(deployed, entry.Name())
+			}
+			if strings.Join(deployed, ",") != strings.Join(tt.deployed, ",") {
+				t.Errorf("deployed %v, want %v", deployed, tt.deployed)
+			}
+		})
+	}
+}
+
+func TestTriggerActionSummary(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name        string
+		failFast    bool
+		wantErr     string
+		wantRows    []string
+		wantSummary string
+		deployed    []string
+	}{
+		{
+			name:        "attempts every target",
+			wantErr:     "1 of 2 deployment(s) failed",
+			wantRows:    []string{"web  group  FAILED", "solo  repository  ok"},
+			wantSummary: "1 succeeded, 1 failed",
+			deployed:    []string{"solo", "web-a"},
+		},
+		{
+			name:        "fail fast",
+			failFast:    true,
+			wantErr:     "group web deployment failed: ",
+			wantRows:    []string{"web  group  FAILED"},
+			wantSummary: "0 succeeded, 1 failed",
+			deployed:    []string{"web-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, markers := newTriggerTestApp(t, "", "")
+			app.appConfig.FailFast = tt.failFast
+			app.config.Repositories[2].Deploy.Commands = []CommandSpec{{Run: "echo broken >&2 && exit 1"}}
+
+			var out bytes.Buffer
+			err := app.triggerAction(&out)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("triggerAction() error = %v, want %q", err, tt.wantErr)
+			}
+
+			summary := regexp.MustCompile(` {2,}`).ReplaceAllString(out.String(), "  ")
+			for _, row := range append(tt.wantRows, tt.wantSummary) {
+				if !strings.Contains(summary, row) {
+					t.Errorf("summary = %q, want it to contain %q", out.String(), row)
+				}
+			}
+			if tt.failFast && strings.Contains(summary, "solo") {
+				t.Errorf("summary = %q, want no attempt after the failure", out.String())
+			}
+
+			entries, _ := os.ReadDir(markers)
+			var deployed []string
+			for _, entry := range entries {
+				deployed = append(deployed, entry.Name())
+			}
+			sort.Strings(deployed)
+			if strings.Join(deployed, ",") != strings.Join(tt.deployed, ",") {
+				t.Errorf("deployed %v, want %v", deployed, tt.deployed)
+			}
+		})
+	}
+}
+
+func TestTriggerActionJSON(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app, _ := newTriggerTestApp(t, "", "")
+	app.appConfig.Output = "json"
+	app.config.Repositories[2].Deploy.Commands = []CommandSpec{{Run: "echo broken >&2 && exit 1"}}
+
+	var out bytes.Buffer
+	if err := app.triggerAction(&out); err == nil {
+		t.Fatal("triggerAction() error = nil, want the failed group reported")
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out.String())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d results, want the group and the repository", len(entries))
+	}
+
+	var group GroupDeployResult
+	if err := json.Unmarshal(entries[0], &group); err != nil {
+		t.Fatalf("first entry is not a group result: %v", err)
+	}
+	if group.GroupName != "web" || group.Success || group.Strategy != "sequential" {
+		t.Errorf("group result = %+v, want failed sequential group web", group)
+	}
+	if len(group.Results) != 2 || !group.Results["web-a"].Success || group.Results["web-b"].Success {
+		t.Errorf("group member results = %+v, want web-a ok and web-b failed", group.Results)
+	}
+	if !strings.Contains(group.Results["web-b"].Error, "broken") {
+		t.Errorf("web-b error = %q, want the command output", group.Results["web-b"].Error)
+	}
+
+	var repo DeployResult
+	if err := json.Unmarshal(entries[1], &repo); err != nil {
+		t.Fatalf("second entry is not a repository result: %v", err)
+	}
+	if repo.RepoName != "solo" || !repo.Success || len(repo.CommandsRun) != 1 {
+		t.Errorf("repository result = %+v, want successful solo deployment", repo)
+	}
+}
+
+func TestValidateActionJSON(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app, _ := newTriggerTestApp(t, "", "")
+	app.appConfig.Action = "validate"
+	app.appConfig.Output = "json"
+	for i := range app.config.Repositories {
+		repo := &app.config.Repositories[i]
+		repo.Monitor = MonitorConfig{
+			RepoURL:  "https://github.com/owner/" + repo.Name,
+			Branches: []string{"main"},
+			RepoType: "github",
+		}
+	}
+	app.monitorService.retry = RetryConfig{}
+	app.monitorService.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/web-a/") {
+			return stubResponse(http.StatusNotFound, `{"message":"Not Found"}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"Add feature","author":{"name":"Alice"}}}`), nil
+	})})
+
+	var out bytes.Buffer
+	if err := app.validateActionJSON(&out); err == nil {
+		t.Fatal("validateActionJSON() error = nil, want the unreachable repository reported")
+	}
+
+	var report ValidationReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not a JSON report: %v\n%s", err, out.String())
+	}
+	if report.Valid || len(report.Errors) != 1 || report.Errors[0].Path != "repositories[1].monitor" {
+		t.Errorf("report errors = %+v, want only repositories[1].monitor", report.Errors)
+	}
+
+	want := map[string]bool{"solo": true, "web-a": false, "web-b": true}
+	if len(report.Repositories) != len(want) {
+		t.Fatalf("report has %d repositories, want %d", len(report.Repositories), len(want))
+	}
+	for _, repo := range report.Repositories {
+		if repo.Monitor.Reachable != want[repo.Name] {
+			t.Errorf("%s monitor reachable = %v, want %v", repo.Name, repo.Monitor.Reachable, want[repo.Name])
+		}
+		if repo.Monitor.Reachable == (repo.Monitor.Error != "") {
+			t.Errorf("%s monitor = %+v, want an error exactly when unreachable", repo.Name, repo.Monitor)
+		}
+		if !repo.Deploy.Reachable {
+			t.Errorf("%s deploy = %+v, want the local QA repository reachable", repo.Name, repo.Deploy)
+		}
+	}
+}
+
+func TestValidateActionJSONSkipsDisabled(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	for _, all := range []bool{false, true} {
+		app, _ := newTriggerTestApp(t, "", "")
+		app.appConfig.Action = "validate"
+		app.appConfig.Output = "json"
+		app.appConfig.All = all
+		enabled := false
+		for i := range app.config.Repositories {
+			repo := &app.config.Repositories[i]
+			repo.Monitor = MonitorConfig{
+				RepoURL:  "https://github.com/owner/" + repo.Name,
+				Branches: []string{"main"},
+				RepoType: "github",
+			}
+			if repo.Name == "web-a" {
+				repo.Enabled = &enabled
+			}
+		}
+		// Only the disabled repository is unreachable
+		app.monitorService.retry = RetryConfig{}
+		app.monitorService.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+			if strings.Contains(req.URL.Path, "/web-a/") {
+				return stubResponse(http.StatusNotFound, `{"message":"Not Found"}`), nil
+			}
+			return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"Add feature","author":{"name":"Alice"}}}`), nil
+		})})
+
+		var out bytes.Buffer
+		err := app.validateActionJSON(&out)
+		if all && err == nil {
+			t.Error("validateActionJSON() with -all error = nil, want the disabled repository tested")
+		}
+		if !all && err != nil {
+			t.Errorf("validateActionJSON() error = %v, want the disabled repository skipped", err)
+		}
+
+		var report ValidationReport
+		if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+			t.Fatalf("output is not a JSON report: %v\n%s", err, out.String())
+		}
+		var names []string
+		for _, repo := range report.Repositories {
+			names = append(names, repo.Name)
+		}
+		want := []string{"solo", "web-b"}
+		if all {
+			want = []string{"solo", "web-a", "web-b"}
+		}
+		if strings.Join(names, ",") != strings.Join(want, ",") {
+			t.Errorf("all=%v: report repositories = %v, want %v", all, names, want)
+		}
+	}
+}
+
+func TestConnectivityProblem(t *testing.T) {
+	problem := connectivityProblem("repositories[0].monitor", fmt.Errorf("failed to access repository: %w", &APIError{Provider: "gitHub", StatusCode: http.StatusNotFound, Body: `{"message":"Not Found"}`}))
+
+	if problem.Path != "repositories[0].monitor" {
+		t.Errorf("Path = %q, want repositories[0].monitor", problem.Path)
+	}
+	if !strings.HasPrefix(problem.Message, "connectivity test failed: ") {
+		t.Errorf("Message = %q, want connectivity test failure", problem.Message)
+	}
+	if problem.Hint != accessProblemHint(ErrRepoNotFound) {
+		t.Errorf("Hint = %q, want the repository not found hint", problem.Hint)
+	}
+}
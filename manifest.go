@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxSubstitutionFileSize caps the size of files considered for manifest substitution
+const maxSubstitutionFileSize = 1 << 20 // 1 MiB
+
+// binarySniffLength is how many leading bytes are inspected to detect binary files
+const binarySniffLength = 8000
+
+// defaultManifestGlobs selects the files treated as manifests when deploy.manifest_globs is unset
+var defaultManifestGlobs = []string{"*.yaml", "*.yml"}
+
+// substitutionKeyPattern matches valid substitution keys
+var substitutionKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// substitutionTokenPattern matches ${key} tokens in manifest content
+var substitutionTokenPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// applySubstitutions renders a repository's substitution values and applies them to its clone
+func applySubstitutions(repoConfig *RepositoryConfig, workDir string, data commandTemplateData) error {
+	values, err := substitutionValues(repoConfig, data)
+	if err != nil {
+		return err
+	}
+
+	changed, err := substituteManifests(workDir, manifestGlobs(repoConfig), values)
+	if err != nil {
+		return err
+	}
+
+	AppLogger.InfoS("Manifest substitution completed",
+		"repo", repoConfig.GetDisplayName(),
+		"keys", len(values),
+		"files_changed", changed)
+	return nil
+}
+
+// substitutionValues builds the key -> value map for manifest substitution
+// Built-in SENTRY_* keys are always available; configured keys override them.
+func substitutionValues(repoConfig *RepositoryConfig, data commandTemplateData) (map[string]string, error) {
+	values := map[string]string{
+		"SENTRY_REPO":    data.RepoName,
+		"SENTRY_PROJECT": data.ProjectName,
+	}
+	// Leave the token in place rather than blanking it when the commit is unknown
+	if data.CommitSHA != "" {
+		values["SENTRY_COMMIT_SHA"] = data.CommitSHA
+	}
+
+	for key, value := range repoConfig.Deploy.Substitutions {
+		rendered, err := renderCommandTemplate(value, data)
+		if err != nil {
+			return nil, fmt.Errorf("substitution %s: %w", key, err)
+		}
+		values[key] = rendered
+	}
+
+	return values, nil
+}
+
+// manifestGlobs returns the configured manifest globs or the YAML defaults
+func manifestGlobs(repoConfig *RepositoryConfig) []string {
+	if len(repoConfig.Deploy.ManifestGlobs) > 0 {
+		return repoConfig.Deploy.ManifestGlobs
+	}
+	return defaultManifestGlobs
+}
+
+// matchesManifestGlobs reports whether a path relative to the working dir is a manifest
+// Patterns containing a slash match the relative path; others match the file name.
+func matchesManifestGlobs(relPath string, globs []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range globs {
+		target := filepath.Base(relPath)
+		if strings.Contains(pattern, "/") {
+			target = relPath
+		}
+		if matched, _ := filepath.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// substituteManifests replaces ${key} tokens with known values across manifest files under workDir
+// Files outside globs, unknown tokens, binary files, oversized files, symlinks and the .git
+// directory are left untouched. It returns the number of files rewritten.
+func substituteManifests(workDir string, globs []string, values map[string]string) (int, error) {
+	changed := 0
+
+	err := filepath.WalkDir(workDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(workDir, path)
+		if err != nil {
+			return err
+		}
+		if !matchesManifestGlobs(relPath, globs) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxSubstitutionFileSize {
+			AppLogger.WarnS("Skipping oversized file during substitution",
+				"path", path,
+				"size", info.Size(),
+				"limit", maxSubstitutionFileSize)
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if isBinaryContent(content) {
+			return nil
+		}
+
+		updated := substitutionTokenPattern.ReplaceAllFunc(content, func(token []byte) []byte {
+			key := string(token[2 : len(token)-1]) // Remove ${ and }
+			if value, ok := values[key]; ok {
+				return []byte(value)
+			}
+			return token
+		})
+		if bytes.Equal(updated, content) {
+			return nil
+		}
+
+		if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed++
+		return nil
+	})
+	if err != nil {
+		return changed, err
+	}
+
+	return changed, nil
+}
+
+// isBinaryContent reports whether content looks binary (contains a NUL byte near the start)
+func isBinaryContent(content []byte) bool {
+	sniff := content
+	if len(sniff) > binarySniffLength {
+		sniff = sniff[:binarySniffLength]
+	}
+	return bytes.IndexByte(sniff, 0) >= 0
+}
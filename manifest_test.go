@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSubstituteManifests(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	workDir := t.TempDir()
+	write := func(name string, content []byte) string {
+		path := filepath.Join(workDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	manifest := write("pipeline/deploy.yaml", []byte(
+		"image: registry/app:${SENTRY_COMMIT_SHA}\ntag: ${IMAGE_TAG}\nother: ${NOT_DEFINED}\n"))
+	binaryContent := []byte("\x00\x01${IMAGE_TAG}")
+	binary := write("bin/tool", binaryContent)
+	gitFile := write(".git/config", []byte("${IMAGE_TAG}"))
+	oversizedContent := []byte(strings.Repeat("x", maxSubstitutionFileSize) + "${IMAGE_TAG}")
+	oversized := write("big.yaml", oversizedContent)
+
+	values := map[string]string{
+		"SENTRY_COMMIT_SHA": "abc123def",
+		"IMAGE_TAG":         "sha-abc123def",
+	}
+
+	// Match every file so the binary and size guards are exercised
+	changed, err := substituteManifests(workDir, []string{"*"}, values)
+	if err != nil {
+		t.Fatalf("substituteManifests() error = %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("substituteManifests() changed = %d, want 1", changed)
+	}
+
+	got, _ := os.ReadFile(manifest)
+	want := "image: registry/app:abc123def\ntag: sha-abc123def\nother: ${NOT_DEFINED}\n"
+	if string(got) != want {
+		t.Errorf("manifest = %q, want %q", string(got), want)
+	}
+
+	untouched := map[string][]byte{
+		binary:    binaryContent,
+		gitFile:   []byte("${IMAGE_TAG}"),
+		oversized: oversizedContent,
+	}
+	for path, original := range untouched {
+		content, _ := os.ReadFile(path)
+		if string(content) != string(original) {
+			t.Errorf("%s should not be modified", path)
+		}
+	}
+}
+
+func TestSubstituteManifestsGlobs(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		globs     []string
+		processed []string
+		skipped   []string
+	}{
+		{
+			name:      "default globs select yaml only",
+			globs:     manifestGlobs(&RepositoryConfig{}),
+			processed: []string{"deploy.yaml", "tekton/pipeline.yml"},
+			skipped:   []string{"values.json", "README.md"},
+		},
+		{
+			name:      "custom globs",
+			globs:     []string{"*.json", "tekton/*.yml"},
+			processed: []string{"values.json", "tekton/pipeline.yml"},
+			skipped:   []string{"deploy.yaml", "README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir := t.TempDir()
+			files := append(append([]string{}, tt.processed...), tt.skipped...)
+			for _, name := range files {
+				path := filepath.Join(workDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("failed to create directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte("value: ${KEY}\n"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			changed, err := substituteManifests(workDir, tt.globs, map[string]string{"KEY": "replaced"})
+			if err != nil {
+				t.Fatalf("substituteManifests() error = %v", err)
+			}
+			if changed != len(tt.processed) {
+				t.Errorf("substituteManifests() changed = %d, want %d", changed, len(tt.processed))
+			}
+
+			for _, name := range tt.processed {
+				content, _ := os.ReadFile(filepath.Join(workDir, name))
+				if string(content) != "value: replaced\n" {
+					t.Errorf("%s = %q, want substituted", name, string(content))
+				}
+			}
+			for _, name := range tt.skipped {
+				content, _ := os.ReadFile(filepath.Join(workDir, name))
+				if string(content) != "value: ${KEY}\n" {
+					t.Errorf("%s = %q, want untouched", name, string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestSubstitutionValues(t *testing.T) {
+	repoConfig := &RepositoryConfig{
+		Name: "app",
+		Deploy: DeployConfig{
+			ProjectName: "app-project",
+			Substitutions: map[string]string{
+				"IMAGE_TAG":      "sha-{{.CommitSHA}}",
+				"SENTRY_PROJECT": "overridden",
+			},
+		},
+	}
+
+	values, err := substitutionValues(repoConfig, newCommandTemplateData(repoConfig, DeployTrigger{CommitInfo: CommitInfo{SHA: "abc123"}}))
+	if err != nil {
+		t.Fatalf("substitutionValues() error = %v", err)
+	}
+
+	want := map[string]string{
+		"SENTRY_REPO":       "app",
+		"SENTRY_PROJECT":    "overridden",
+		"SENTRY_COMMIT_SHA": "abc123",
+		"IMAGE_TAG":         "sha-abc123",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("values[%s] = %q, want %q", key, values[key], value)
+		}
+	}
+
+	// Unknown commit leaves the built-in undefined so tokens are not blanked
+	values, err = substitutionValues(repoConfig, newCommandTemplateData(repoConfig, DeployTrigger{}))
+	if err != nil {
+		t.Fatalf("substitutionValues() error = %v", err)
+	}
+	if _, ok := values["SENTRY_COMMIT_SHA"]; ok {
+		t.Error("SENTRY_COMMIT_SHA should be undefined when no trigger commit is known")
+	}
+
+	repoConfig.Deploy.Substitutions = map[string]string{"BAD": "{{.Unknown}}"}
+	if _, err := substitutionValues(repoConfig, newCommandTemplateData(repoConfig, DeployTrigger{})); err == nil {
+		t.Error("substitutionValues() should fail on an invalid template value")
+	}
+}
+
+func TestDeployRepositorySubstitutesManifests(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	qaRepo := newLocalQARepo(t, map[string]string{
+		"deploy.yaml": "image: registry/app:${SENTRY_COMMIT_SHA}\n",
+	})
+	outputPath := filepath.Join(t.TempDir(), "applied.yaml")
+
+	config := &Config{
+		Global: GlobalConfig{
+			TmpDir:  t.TempDir(),
+			Cleanup: true,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "app",
+				Deploy: DeployConfig{
+					QARepoURL:     qaRepo,
+					QARepoBranch:  "main",
+					RepoType:      "git",
+					ProjectName:   "app",
+					Commands:      []CommandSpec{{Run: "cp deploy.yaml " + outputPath}},
+					Substitutions: map[string]string{"IMAGE_TAG": "sha-{{.CommitSHA}}"},
+				},
+			},
+		},
+	}
+
+	service := NewDeployService(config)
+	trigger := &DeployTrigger{Branch: "main", CommitInfo: CommitInfo{SHA: "0123456789abcdef"}}
+
+	result := service.deployRepository("app", context.Background(), trigger)
+	if !result.Success {
+		t.Fatalf("deployRepository() failed: %s", result.Error)
+	}
+
+	applied, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read applied manifest: %v", err)
+	}
+	if string(applied) != "image: registry/app:0123456789abcdef\n" {
+		t.Errorf("applied manifest = %q, want commit SHA substituted", string(applied))
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors for polling and deployments
+// All methods are no-ops on a nil *Metrics so services work without metrics enabled.
+type Metrics struct {
+	registry       *prometheus.Registry
+	repoChecks     *prometheus.CounterVec
+	commitChanges  *prometheus.CounterVec
+	deployments    *prometheus.CounterVec
+	deployDuration *prometheus.HistogramVec
+}
+
+// NewMetrics creates a metrics registry with all Sentry collectors registered
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		repoChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentry_repo_checks_total",
+			Help: "Repository branch checks by result (success or error).",
+		}, []string{"repo", "result"}),
+		commitChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentry_commit_changes_total",
+			Help: "New commits detected on monitored branches.",
+		}, []string{"repo"}),
+		deployments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sentry_deployments_total",
+			Help: "Repository deployments by result (success or failure).",
+		}, []string{"repo", "result"}),
+		deployDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sentry_deploy_duration_seconds",
+			Help:    "Duration of repository deployments.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+		}, []string{"repo"}),
+	}
+
+	m.registry.MustRegister(m.repoChecks, m.commitChanges, m.deployments, m.deployDuration)
+	return m
+}
+
+// Handler returns an HTTP handler exposing the registry in Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RecordRepoCheck counts a repository branch check
+func (m *Metrics) RecordRepoCheck(repoName string, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.repoChecks.WithLabelValues(repoName, result).Inc()
+}
+
+// RecordCommitChange counts a newly detected commit
+func (m *Metrics) RecordCommitChange(repoName string) {
+	if m == nil {
+		return
+	}
+	m.commitChanges.WithLabelValues(repoName).Inc()
+}
+
+// RecordDeployment counts a finalized deployment and observes its duration
+func (m *Metrics) RecordDeployment(result *DeployResult) {
+	if m == nil {
+		return
+	}
+
+	outcome := "failure"
+	if result.Success {
+		outcome = "success"
+	}
+	m.deployments.WithLabelValues(result.RepoName, outcome).Inc()
+
+	if duration, err := time.ParseDuration(result.Duration); err == nil {
+		m.deployDuration.WithLabelValues(result.RepoName).Observe(duration.Seconds())
+	}
+}
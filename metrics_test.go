@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gatheredValue returns the counter value (or histogram sample count) of a metric with matching labels
+func gatheredValue(t *testing.T, metrics *Metrics, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := metrics.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+			if matched != len(labels) {
+				continue
+			}
+			if histogram := metric.GetHistogram(); histogram != nil {
+				return float64(histogram.GetSampleCount())
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestMetricsRecordDeployment(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{
+		Global: GlobalConfig{
+			TmpDir:  t.TempDir(),
+			Cleanup: true,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "metrics-repo",
+				Deploy: DeployConfig{
+					QARepoURL:    filepath.Join(t.TempDir(), "missing"),
+					QARepoBranch: "main",
+					RepoType:     "git",
+					ProjectName:  "metrics",
+					Commands:     []CommandSpec{{Run: "true"}},
+				},
+			},
+		},
+	}
+
+	metrics := NewMetrics()
+	service := NewDeployService(config)
+	service.SetMetrics(metrics)
+
+	// Clone of a missing QA repo fails quickly and deterministically
+	service.deployRepository("metrics-repo", context.Background(), nil)
+	service.deployRepository("metrics-repo", context.Background(), nil)
+
+	failures := gatheredValue(t, metrics, "sentry_deployments_total", map[string]string{"repo": "metrics-repo", "result": "failure"})
+	if failures != 2 {
+		t.Errorf("sentry_deployments_total{result=failure} = %v, want 2", failures)
+	}
+
+	observations := gatheredValue(t, metrics, "sentry_deploy_duration_seconds", map[string]string{"repo": "metrics-repo"})
+	if observations != 2 {
+		t.Errorf("sentry_deploy_duration_seconds sample count = %v, want 2", observations)
+	}
+}
+
+func TestMetricsRecordRepoChecks(t *testing.T) {
+	metrics := NewMetrics()
+
+	metrics.RecordRepoCheck("repo-a", nil)
+	metrics.RecordRepoCheck("repo-a", errors.New("status 500"))
+	metrics.RecordCommitChange("repo-a")
+
+	if got := gatheredValue(t, metrics, "sentry_repo_checks_total", map[string]string{"repo": "repo-a", "result": "success"}); got != 1 {
+		t.Errorf("sentry_repo_checks_total{result=success} = %v, want 1", got)
+	}
+	if got := gatheredValue(t, metrics, "sentry_repo_checks_total", map[string]string{"repo": "repo-a", "result": "error"}); got != 1 {
+		t.Errorf("sentry_repo_checks_total{result=error} = %v, want 1", got)
+	}
+	if got := gatheredValue(t, metrics, "sentry_commit_changes_total", map[string]string{"repo": "repo-a"}); got != 1 {
+		t.Errorf("sentry_commit_changes_total = %v, want 1", got)
+	}
+
+	// A nil *Metrics is a valid no-op for services without metrics enabled
+	var disabled *Metrics
+	disabled.RecordRepoCheck("repo-a", nil)
+	disabled.RecordCommitChange("repo-a")
+	disabled.RecordDeployment(&DeployResult{RepoName: "repo-a"})
+}
+
+func TestStatusServerEndpoints(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.RecordCommitChange("repo-a")
+
+	server := httptest.NewServer(NewStatusServer(":0", metrics, nil).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /healthz status = %d, want 200", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if !strings.Contains(string(body), `sentry_commit_changes_total{repo="repo-a"} 1`) {
+		t.Errorf("GET /metrics missing commit counter:\n%s", body)
+	}
+}
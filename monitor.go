@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,19 +28,82 @@ type CommitInfo struct {
 	URL       string    `json:"url"`
 }
 
+// shortSHA abbreviates a commit SHA to at most 8 characters for logging
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
 // MonitorService handles repository monitoring
 type MonitorService struct {
-	config        *Config
+	config        atomic.Pointer[Config] // Swapped on reload
 	httpClient    *http.Client
-	lastCommit    map[string]string // repoName -> last commit SHA
-	deployService *DeployService    // Deploy service for triggered deployments
-	mu            sync.RWMutex      // Protects lastCommit map
+	lastCommit    map[string]string        // repoName -> last commit SHA
+	deployService *DeployService           // Deploy service for triggered deployments
+	breaker       *BranchBreaker           // Suppresses deploys for repeatedly failing branches
+	checkFailures *checkFailureTracker     // Consecutive failed checks per repository, for check alerts
+	metrics       *Metrics                 // Optional Prometheus metrics (nil when disabled)
+	retry         RetryConfig              // Retry behavior for monitor API calls
+	sleep         func(time.Duration)      // Waits between retries (replaceable in tests)
+	etags         map[string]string        // refCacheKey -> ETag of the last polled GitHub commits response
+	pending       map[string]DeployTrigger // refCacheKey -> new change on the ref, until deployChanges takes it
+	mu            sync.RWMutex             // Protects lastCommit, etags and pending maps
+	deployMu      sync.Mutex               // Serializes deployments started by polling and push webhooks
+	reloads       chan struct{}            // Signals the polling loop to reschedule after a reload
 }
 
 // RetryConfig defines retry behavior for network requests
 type RetryConfig struct {
 	MaxRetries int
-	RetryDelay time.Duration
+	RetryDelay time.Duration // Base delay, doubled on each retry
+	MaxDelay   time.Duration // Upper bound for a single retry delay
+}
+
+// RateLimitError reports that a provider API rate limit is exhausted until Reset
+type RateLimitError struct {
+	Provider string
+	Reset    time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s API rate limit exceeded (resets at %s)", e.Provider, e.Reset.Format(time.RFC3339))
+}
+
+// parseGitHubRateLimit returns a RateLimitError when a response reports an exhausted rate limit
+func parseGitHubRateLimit(resp *http.Response) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return nil
+	}
+
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &RateLimitError{Provider: "gitHub", Reset: time.Unix(reset, 0)}
+}
+
+// Backoff returns the delay before the given retry attempt (starting at 1)
+// The delay doubles each attempt up to MaxDelay, and a random jitter in the
+// upper half spreads out retries from repositories that failed together.
+func (r RetryConfig) Backoff(attempt int) time.Duration {
+	delay := r.RetryDelay
+	for i := 1; i < attempt && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return delay - half + time.Duration(rand.Int63n(int64(half)+1))
 }
 
 // GroupTrigger represents a triggered group deployment
@@ -40,18 +112,50 @@ type GroupTrigger struct {
 	Repositories []string
 	TriggerTime  time.Time
 	TriggerRepo  string // Which repo triggered this group
+	Sources      []TriggerSource
+}
+
+// TriggerSource identifies a repository branch whose change caused a deployment
+type TriggerSource struct {
+	RepoName string
+	Branch   string
+	Trigger  *DeployTrigger // The change itself, passed on to the deployment it causes
 }
 
 // NewMonitorService creates a new monitor service instance
 func NewMonitorService(config *Config, deployService *DeployService) *MonitorService {
-	return &MonitorService{
-		config: config,
+	m := &MonitorService{
 		httpClient: &http.Client{
-			Timeout: time.Duration(getTimeoutFromConfig(config)) * time.Second,
+			Timeout:   getMonitorTimeout(config),
+			Transport: newHTTPTransport(config),
 		},
 		lastCommit:    make(map[string]string),
+		etags:         make(map[string]string),
+		pending:       make(map[string]DeployTrigger),
 		deployService: deployService,
+		breaker:       NewBranchBreaker(config),
+		checkFailures: newCheckFailureTracker(),
+		retry:         getRetryConfig(config),
+		sleep:         time.Sleep,
+		reloads:       make(chan struct{}, 1),
 	}
+	m.config.Store(config)
+	return m
+}
+
+// currentConfig returns the configuration in effect, which a reload may replace at any time
+func (m *MonitorService) currentConfig() *Config {
+	return m.config.Load()
+}
+
+// SetHTTPClient replaces the client used for provider API calls, e.g. to add a proxy, custom CAs or mutual TLS
+func (m *MonitorService) SetHTTPClient(client *http.Client) {
+	m.httpClient = client
+}
+
+// SetMetrics enables Prometheus instrumentation of repository checks
+func (m *MonitorService) SetMetrics(metrics *Metrics) {
+	m.metrics = metrics
 }
 
 // getTimeoutFromConfig gets timeout from global config or uses default
@@ -62,173 +166,530 @@ func getTimeoutFromConfig(config *Config) int {
 	return 30 // Default 30 seconds
 }
 
+// getMonitorTimeout gets the timeout for provider API calls and git ls-remote, falling back to timeout
+func getMonitorTimeout(config *Config) time.Duration {
+	if config.Global.MonitorTimeout > 0 {
+		return time.Duration(config.Global.MonitorTimeout) * time.Second
+	}
+	return time.Duration(getTimeoutFromConfig(config)) * time.Second
+}
+
+// getDeployTimeout gets the timeout for cloning the QA repository and triggering its pipeline, falling back to timeout
+func getDeployTimeout(config *Config) time.Duration {
+	if config.Global.DeployTimeout > 0 {
+		return time.Duration(config.Global.DeployTimeout) * time.Second
+	}
+	return time.Duration(getTimeoutFromConfig(config)) * time.Second
+}
+
+// getGitBinary gets the git executable to run, defaulting to git on PATH
+func getGitBinary(config *Config) string {
+	if config != nil && config.Global.GitBinary != "" {
+		return config.Global.GitBinary
+	}
+	return "git"
+}
+
+// defaultRepoDeployTimeout bounds a whole repository deployment when repo_deploy_timeout is unset
+const defaultRepoDeployTimeout = time.Hour
+
+// getRepoDeployTimeout gets the overall deadline of one repository deployment, clone and commands included
+func getRepoDeployTimeout(config *Config) time.Duration {
+	if config.Global.RepoDeployTimeout > 0 {
+		return time.Duration(config.Global.RepoDeployTimeout) * time.Second
+	}
+	return defaultRepoDeployTimeout
+}
+
+// getRetryConfig gets retry behavior from global config or uses defaults
+func getRetryConfig(config *Config) RetryConfig {
+	retryConfig := RetryConfig{
+		MaxRetries: 3,
+		RetryDelay: 2 * time.Second,
+		MaxDelay:   30 * time.Second,
+	}
+	if config.Global.MaxRetries != nil {
+		retryConfig.MaxRetries = *config.Global.MaxRetries
+	}
+	if config.Global.RetryDelay != nil {
+		retryConfig.RetryDelay = time.Duration(*config.Global.RetryDelay) * time.Second
+	}
+	if config.Global.RetryMaxDelay > 0 {
+		retryConfig.MaxDelay = time.Duration(config.Global.RetryMaxDelay) * time.Second
+	}
+	return retryConfig
+}
+
 // StartMonitoring starts the continuous monitoring process
-func (m *MonitorService) StartMonitoring() error {
-	AppLogger.InfoS("Starting repository monitoring", "polling_interval", m.config.PollingInterval)
+// It polls until ctx is cancelled and then returns ctx.Err(); deployments it
+// triggers run under ctx so shutdown can drain them.
+func (m *MonitorService) StartMonitoring(ctx context.Context) error {
+	AppLogger.InfoS("Starting repository monitoring", "polling_interval", m.currentConfig().PollingInterval)
 
 	// Initial check to get baseline
-	if err := m.CheckAllRepositories(); err != nil {
+	if err := m.CheckAllRepositories(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("initial repository check failed: %w", err)
 	}
 
-	// Start polling loop
-	ticker := time.NewTicker(time.Duration(m.config.PollingInterval) * time.Second)
-	defer ticker.Stop()
+	// Start polling loop; each repository is checked on its own interval.
+	// The schedule indexes into config, so both are replaced together on reload.
+	config := m.currentConfig()
+	schedule := newPollSchedule(config, time.Now())
+
+	for {
+		// With nothing scheduled, wait only for shutdown or a reload
+		var due <-chan time.Time
+		var timer *time.Timer
+		if nextDue, ok := schedule.nextDue(); ok {
+			timer = time.NewTimer(time.Until(nextDue))
+			due = timer.C
+		}
+
+		var repos []RepositoryConfig
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			AppLogger.Info("Repository monitoring stopped")
+			return ctx.Err()
+		case <-m.reloads:
+			if timer != nil {
+				timer.Stop()
+			}
+			config = m.currentConfig()
+			schedule = newPollSchedule(config, time.Now())
+			// Baseline newly added repositories now rather than one interval later
+			for _, repo := range config.Repositories {
+				if !m.hasBaseline(repo.Name) {
+					repos = append(repos, repo)
+				}
+			}
+		case now := <-due:
+			for _, i := range schedule.due(now) {
+				repos = append(repos, config.Repositories[i])
+			}
+		}
 
-	for range ticker.C {
-		if err := m.CheckAllRepositories(); err != nil {
+		if len(repos) == 0 {
+			continue
+		}
+		if err := m.checkRepositories(ctx, repos); err != nil && ctx.Err() == nil {
 			AppLogger.ErrorS("Error checking repositories", "error", err)
 		}
 	}
-
-	return nil // This will never be reached but satisfies the linter
 }
 
 // CheckAllRepositories checks all configured repositories for changes
-func (m *MonitorService) CheckAllRepositories() error {
+func (m *MonitorService) CheckAllRepositories(ctx context.Context) error {
+	return m.checkRepositories(ctx, m.currentConfig().Repositories)
+}
+
+// checkRepositories checks the given repositories for changes and triggers their deployments
+// Changes found in the same pass are batched, so a group deploys once however many members changed.
+func (m *MonitorService) checkRepositories(ctx context.Context, repos []RepositoryConfig) error {
 	var errors []string
-	triggeredGroups := make(map[string]*GroupTrigger)
-	triggeredIndividual := make([]string, 0)
+	var changes []repoChange
 
-	// Check all repositories for changes
-	for _, repo := range m.config.Repositories {
-		changed, err := m.checkRepository(&repo)
+	// Check the repositories for changes
+	for i := range repos {
+		repo := &repos[i]
+		if !repo.IsEnabled() {
+			AppLogger.InfoS("Skipping disabled repository", "repo", repo.GetDisplayName())
+			continue
+		}
+		changedBranches, err := m.checkRepository(repo)
+		m.recordCheckOutcome(repo, err)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("%s: %v", repo.Name, err))
-			continue
 		}
+		// Changes detected before a later ref failed still deploy
+		if len(changedBranches) > 0 {
+			changes = append(changes, repoChange{repo: repo, refs: changedBranches})
+		}
+	}
 
-		if changed {
-			AppLogger.InfoS("Repository change detected", "repo", repo.Name, "group", repo.Group)
-
-			if repo.Group != "" {
-				// This repo belongs to a group
-				if _, exists := triggeredGroups[repo.Group]; !exists {
-					triggeredGroups[repo.Group] = &GroupTrigger{
-						GroupName:    repo.Group,
-						Repositories: make([]string, 0),
-						TriggerTime:  time.Now(),
-						TriggerRepo:  repo.Name,
-					}
-				}
-				// Add all repositories in this group to the trigger list
-				for _, r := range m.config.Repositories {
-					if r.Group == repo.Group {
-						triggeredGroups[repo.Group].Repositories = append(triggeredGroups[repo.Group].Repositories, r.Name)
-					}
-				}
-			} else {
-				// Individual repository (no group)
-				triggeredIndividual = append(triggeredIndividual, repo.Name)
-			}
+	// Do not start deployments once shutdown has been requested
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	errors = append(errors, m.deployChanges(ctx, changes)...)
+
+	if len(errors) > 0 {
+		return fmt.Errorf("repository check errors: %s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// repoChange lists the changed branches and tag refs of one repository
+type repoChange struct {
+	repo *RepositoryConfig
+	refs []string
+}
+
+// deployChanges deploys the repositories whose refs changed and returns a message per failure
+// Changes are batched, so a group deploys once however many members changed.
+func (m *MonitorService) deployChanges(ctx context.Context, changes []repoChange) []string {
+	m.deployMu.Lock()
+	defer m.deployMu.Unlock()
+
+	var errors []string
+	triggeredGroups := make(map[string]*GroupTrigger)
+	triggeredIndividual := make([]string, 0)
+
+	individualSources := make(map[string][]TriggerSource)
+	individualGroups := make(map[string]string) // Group of a member deploying alone per group_trigger_fallback
+
+	for _, change := range changes {
+		repo := change.repo
+		triggers := m.takePendingTriggers(repo.Name, change.refs)
+		groupRefs, individualRefs := []string(nil), change.refs
+		if repo.Group != "" {
+			// Members escalate to their group unless group_trigger_branches scopes which branches do
+			groupRefs, individualRefs = splitGroupTriggerRefs(repo, change.refs)
+		}
+		groupSources := m.allowedTriggerSources(repo, groupRefs, triggers)
+		sources := m.allowedTriggerSources(repo, individualRefs, triggers)
+		if len(groupSources) > 0 || len(sources) > 0 {
+			AppLogger.InfoS("Repository change detected", "repo", repo.GetDisplayName(), "group", repo.Group)
+		}
+
+		if len(groupSources) > 0 {
+			m.addGroupTrigger(triggeredGroups, repo, groupSources)
+		}
+		if len(sources) > 0 {
+			triggeredIndividual = append(triggeredIndividual, repo.Name)
+			individualSources[repo.Name] = sources
+			individualGroups[repo.Name] = repo.Group
 		}
 	}
 
-	// Process group triggers
-	for groupName, trigger := range triggeredGroups {
+	// A member whose group deploys anyway is not deployed a second time on its own
+	individual := triggeredIndividual[:0]
+	for _, repoName := range triggeredIndividual {
+		if trigger, ok := triggeredGroups[individualGroups[repoName]]; ok {
+			trigger.Sources = append(trigger.Sources, individualSources[repoName]...)
+			continue
+		}
+		individual = append(individual, repoName)
+	}
+	triggeredIndividual = individual
+
+	// Process group triggers in a stable order, so deployments and logs match from run to run
+	groupNames := make([]string, 0, len(triggeredGroups))
+	for groupName := range triggeredGroups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+
+	for _, groupName := range groupNames {
+		trigger := triggeredGroups[groupName]
 		AppLogger.InfoS("Triggering group deployment",
 			"group", groupName,
 			"triggered_by", trigger.TriggerRepo,
 			"repositories", trigger.Repositories)
 
-		if err := m.triggerGroupDeployment(groupName, trigger.Repositories); err != nil {
-			errors = append(errors, fmt.Sprintf("group %s deployment failed: %v", groupName, err))
+		// Members route by their own changes; branches mapped to different QA branches deploy the group again
+		for _, round := range splitTriggerRounds(m.currentConfig(), trigger.Sources) {
+			err := m.triggerGroupDeployment(ctx, groupName, trigger.Repositories, sourceTriggers(round))
+			m.recordTriggerResults(ctx, round, err == nil)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("group %s deployment failed: %v", groupName, err))
+			}
 		}
 	}
 
 	// Process individual triggers
-	for _, repoName := range triggeredIndividual {
-		AppLogger.InfoS("Triggering individual deployment", "repo", repoName)
-		if err := m.triggerIndividualDeployment(repoName); err != nil {
-			errors = append(errors, fmt.Sprintf("individual %s deployment failed: %v", repoName, err))
+	return append(errors, m.triggerIndividualDeployments(ctx, triggeredIndividual, individualSources)...)
+}
+
+// addGroupTrigger records that a member of a group changed, creating the group's trigger on first change
+// The trigger lists every group member exactly once, however many members changed in the pass.
+func (m *MonitorService) addGroupTrigger(triggers map[string]*GroupTrigger, repo *RepositoryConfig, sources []TriggerSource) {
+	trigger, exists := triggers[repo.Group]
+	if !exists {
+		trigger = &GroupTrigger{
+			GroupName:    repo.Group,
+			Repositories: make([]string, 0),
+			TriggerTime:  time.Now(),
+			TriggerRepo:  repo.Name,
+		}
+		// Add all enabled repositories in this group to the trigger list
+		for _, r := range m.currentConfig().Repositories {
+			if r.Group == repo.Group && r.IsEnabled() {
+				trigger.Repositories = append(trigger.Repositories, r.Name)
+			}
 		}
+		triggers[repo.Group] = trigger
 	}
+	trigger.Sources = append(trigger.Sources, sources...)
+}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("repository check errors: %s", strings.Join(errors, "; "))
+// triggerIndividualDeployments deploys ungrouped repositories, at most MaxParallelIndividual at a time
+// Deployments start in the given order; the returned messages describe every failure.
+func (m *MonitorService) triggerIndividualDeployments(ctx context.Context, repoNames []string, sources map[string][]TriggerSource) []string {
+	var failures []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// Create semaphore to limit concurrent deployments
+	semaphore := make(chan struct{}, getMaxParallelIndividual(m.currentConfig()))
+
+	for _, repoName := range repoNames {
+		semaphore <- struct{}{}
+
+		// Do not start deployments once shutdown has been requested
+		if ctx.Err() != nil {
+			<-semaphore
+			break
+		}
+
+		wg.Add(1)
+		go func(rn string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// One deployment per distinct QA branch the repository's changed branches map to
+			for _, round := range splitTriggerRounds(m.currentConfig(), sources[rn]) {
+				AppLogger.InfoS("Triggering individual deployment", "repo", rn)
+				err := m.triggerIndividualDeployment(ctx, rn, sourceTriggers(round)[rn])
+				m.recordTriggerResults(ctx, round, err == nil)
+				if err != nil {
+					mu.Lock()
+					failures = append(failures, fmt.Sprintf("individual %s deployment failed: %v", rn, err))
+					mu.Unlock()
+				}
+			}
+		}(repoName)
 	}
 
-	return nil
+	wg.Wait()
+	return failures
 }
 
-// checkRepository checks a single repository for changes
-func (m *MonitorService) checkRepository(repo *RepositoryConfig) (bool, error) {
-	// Check all configured branches
-	for _, branch := range repo.Monitor.Branches {
+// getMaxParallelIndividual gets how many ungrouped deployments may run at once (default 1, sequential)
+func getMaxParallelIndividual(config *Config) int {
+	if config.Global.MaxParallelIndividual > 0 {
+		return config.Global.MaxParallelIndividual
+	}
+	return 1
+}
+
+// checkRepository checks a single repository for changes and returns the changed branches and tag refs
+// A failing branch or tag check does not stop the others. Refs found changed are returned together with
+// the error, because their new commits are already recorded and would otherwise never deploy.
+func (m *MonitorService) checkRepository(repo *RepositoryConfig) ([]string, error) {
+	var changedRefs []string
+	var checkErrs []error
+
+	branches, err := m.expandBranches(&repo.Monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check all matching branches so every branch SHA stays tracked
+	for _, branch := range branches {
 		changed, err := m.checkRepositoryBranch(repo, branch)
 		if err != nil {
-			return false, err
+			checkErrs = append(checkErrs, err)
+			continue
 		}
 		if changed {
-			return true, nil // Any branch change triggers deployment
+			changedRefs = append(changedRefs, branch)
 		}
 	}
-	return false, nil
+
+	changedTags, err := m.checkRepositoryTags(repo)
+	if err != nil {
+		checkErrs = append(checkErrs, err)
+	}
+	return append(changedRefs, changedTags...), errors.Join(checkErrs...)
+}
+
+// takePendingTriggers removes and returns the recorded changes of a repository's changed refs, keyed by ref
+func (m *MonitorService) takePendingTriggers(repoName string, refs []string) map[string]*DeployTrigger {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	triggers := make(map[string]*DeployTrigger)
+	for _, ref := range refs {
+		cacheKey := refCacheKey(repoName, ref)
+		if trigger, ok := m.pending[cacheKey]; ok {
+			triggers[ref] = &trigger
+			delete(m.pending, cacheKey)
+		}
+	}
+	return triggers
+}
+
+// setPendingTrigger records the change on a ref for the next deployChanges
+func (m *MonitorService) setPendingTrigger(repoName string, ref string, trigger DeployTrigger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[refCacheKey(repoName, ref)] = trigger
+}
+
+// allowedTriggerSources filters changed branches through the branch breaker
+func (m *MonitorService) allowedTriggerSources(repo *RepositoryConfig, changedBranches []string, triggers map[string]*DeployTrigger) []TriggerSource {
+	var sources []TriggerSource
+	for _, branch := range changedBranches {
+		if !m.breaker.Allow(repo.Name, branch) {
+			AppLogger.WarnS("Deploy suppressed by branch breaker",
+				"repo", repo.GetDisplayName(),
+				"branch", branch)
+			continue
+		}
+		sources = append(sources, TriggerSource{RepoName: repo.Name, Branch: branch, Trigger: triggers[branch]})
+	}
+	return sources
+}
+
+// sourceTriggers returns the change each repository of sources deploys, the first one recorded per repository
+func sourceTriggers(sources []TriggerSource) map[string]*DeployTrigger {
+	triggers := make(map[string]*DeployTrigger)
+	for _, source := range sources {
+		if triggers[source.RepoName] == nil {
+			triggers[source.RepoName] = source.Trigger
+		}
+	}
+	return triggers
+}
+
+// recordTriggerResults feeds a deployment outcome back to the branch breaker
+// Deployments cut short by shutdown say nothing about the branch and are not recorded.
+func (m *MonitorService) recordTriggerResults(ctx context.Context, sources []TriggerSource, success bool) {
+	if ctx.Err() != nil {
+		return
+	}
+	for _, source := range sources {
+		m.breaker.RecordResult(source.RepoName, source.Branch, success)
+	}
+}
+
+// ResetBreaker clears the breaker state of a repository branch, or all its branches when branch is empty
+func (m *MonitorService) ResetBreaker(repoName string, branch string) (int, error) {
+	return m.breaker.Reset(repoName, branch)
 }
 
 // checkRepositoryBranch checks a specific branch of a repository
 func (m *MonitorService) checkRepositoryBranch(repo *RepositoryConfig, branch string) (bool, error) {
-	// Create a temporary repo config for this specific branch
-	branchRepo := &MonitorConfig{
-		RepoURL:  repo.Monitor.RepoURL,
-		RepoType: repo.Monitor.RepoType,
-		Auth:     repo.Monitor.Auth,
+	commit, err := m.fetchLatestCommit(&repo.Monitor, branch, refCacheKey(repo.Name, branch))
+	if errors.Is(err, ErrCommitUnchanged) {
+		m.metrics.RecordRepoCheck(repo.Name, nil)
+		return false, nil
 	}
-
-	commit, err := m.GetLatestCommit(branchRepo, branch)
+	if errors.Is(err, ErrEmptyRepository) {
+		// The first commit pushed later becomes the baseline like any other first check
+		AppLogger.InfoS("Repository has no commits yet, nothing to deploy",
+			"repo", repo.GetDisplayName(),
+			"branch", branch)
+		m.metrics.RecordRepoCheck(repo.Name, nil)
+		return false, nil
+	}
+	m.metrics.RecordRepoCheck(repo.Name, err)
 	if err != nil {
 		return false, fmt.Errorf("failed to get latest commit for branch %s: %w", branch, err)
 	}
+	return m.recordBranchCommit(repo, branch, commit), nil
+}
 
-	cacheKey := fmt.Sprintf("%s:%s", repo.Name, branch)
+// recordBranchCommit records a branch's latest commit and reports whether it should trigger a deployment
+// The first commit seen is a baseline; filtered-out commits are recorded as seen without deploying.
+func (m *MonitorService) recordBranchCommit(repo *RepositoryConfig, branch string, commit *CommitInfo) bool {
+	cacheKey := refCacheKey(repo.Name, branch)
 
+	// Compare and record in one step so a poll and a push webhook never both report the same commit
 	m.mu.Lock()
 	lastSHA, exists := m.lastCommit[cacheKey]
+	m.lastCommit[cacheKey] = commit.SHA
+	m.mu.Unlock()
+
 	if !exists {
 		// First time checking this repository/branch
-		m.lastCommit[cacheKey] = commit.SHA
-		m.mu.Unlock()
 		AppLogger.InfoS("Initial commit recorded",
-			"repo", repo.Name,
+			"repo", repo.GetDisplayName(),
 			"branch", branch,
-			"sha", commit.SHA[:8])
-		return false, nil
+			"sha", shortSHA(commit.SHA))
+		return false
+	}
+	if commit.SHA == lastSHA {
+		return false
 	}
-	m.mu.Unlock()
 
-	if commit.SHA != lastSHA {
-		AppLogger.InfoS("New commit detected",
-			"repo", repo.Name,
-			"branch", branch,
-			"old_sha", lastSHA[:8],
-			"new_sha", commit.SHA[:8],
-			"author", commit.Author,
-			"message", commit.Message)
+	AppLogger.InfoS("New commit detected",
+		"repo", repo.GetDisplayName(),
+		"branch", branch,
+		"old_sha", shortSHA(lastSHA),
+		"new_sha", shortSHA(commit.SHA),
+		"author", commit.Author,
+		"message", commit.Message)
 
-		m.mu.Lock()
-		m.lastCommit[cacheKey] = commit.SHA
-		m.mu.Unlock()
-		return true, nil
+	m.metrics.RecordCommitChange(repo.Name)
+
+	if deploy, reason := commitMessageAllowed(&repo.Monitor, commit.Message); !deploy {
+		AppLogger.InfoS("Skipping deployment for filtered commit message",
+			"repo", repo.GetDisplayName(),
+			"branch", branch,
+			"sha", shortSHA(commit.SHA),
+			"reason", reason)
+		return false
 	}
+	if !m.changedFilesAllowed(repo, lastSHA, commit.SHA) {
+		return false
+	}
+
+	m.setPendingTrigger(repo.Name, branch, DeployTrigger{Branch: branch, CommitInfo: *commit})
+	return true
+}
 
-	return false, nil
+// commitMessageAllowed reports whether a commit message passes the monitor's message filters
+// reason names the pattern that rejected the message.
+func commitMessageAllowed(monitor *MonitorConfig, message string) (bool, string) {
+	if monitor.ExcludeMessageRegex != "" {
+		if matched, _ := regexp.MatchString(monitor.ExcludeMessageRegex, message); matched {
+			return false, fmt.Sprintf("matches exclude_message_regex %q", monitor.ExcludeMessageRegex)
+		}
+	}
+	if monitor.IncludeMessageRegex != "" {
+		if matched, _ := regexp.MatchString(monitor.IncludeMessageRegex, message); !matched {
+			return false, fmt.Sprintf("does not match include_message_regex %q", monitor.IncludeMessageRegex)
+		}
+	}
+	return true, ""
 }
 
+// ErrCommitUnchanged reports that a conditional request found the branch head unchanged since the last poll
+var ErrCommitUnchanged = errors.New("commit unchanged since last check")
+
 // GetLatestCommit retrieves the latest commit information from repository with retry
 func (m *MonitorService) GetLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
-	retryConfig := RetryConfig{
-		MaxRetries: 3,
-		RetryDelay: 2 * time.Second,
-	}
+	return m.fetchLatestCommit(monitor, branch, "")
+}
+
+// fetchLatestCommit retrieves the latest commit with retry
+// With an etagKey, GitHub requests send the ETag last remembered under that key and an
+// unchanged branch returns ErrCommitUnchanged instead of a commit.
+func (m *MonitorService) fetchLatestCommit(monitor *MonitorConfig, branch string, etagKey string) (*CommitInfo, error) {
+	retryConfig := m.retry
 
 	var lastErr error
+	var rateLimitWait time.Duration
 	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
 		if attempt > 0 {
+			delay := retryConfig.Backoff(attempt)
+			if rateLimitWait > 0 {
+				delay = rateLimitWait
+			}
 			AppLogger.WarnS("Retrying API call",
 				"attempt", attempt,
 				"max_retries", retryConfig.MaxRetries,
+				"delay", delay,
 				"error", lastErr)
-			time.Sleep(retryConfig.RetryDelay)
+			m.sleep(delay)
 		}
 
 		var commit *CommitInfo
@@ -236,23 +697,39 @@ func (m *MonitorService) GetLatestCommit(monitor *MonitorConfig, branch string)
 
 		switch monitor.RepoType {
 		case "github":
-			commit, err = m.getGitHubLatestCommit(monitor, branch)
+			commit, err = m.getGitHubLatestCommit(monitor, branch, etagKey)
 		case "gitlab":
 			commit, err = m.getGitLabLatestCommit(monitor, branch)
 		case "gitea":
 			commit, err = m.getGiteaLatestCommit(monitor, branch)
+		case "bitbucket":
+			commit, err = m.getBitbucketLatestCommit(monitor, branch)
+		case "git":
+			commit, err = m.getGitLatestCommit(monitor, branch)
 		default:
 			return nil, fmt.Errorf("unsupported repository type: %s", monitor.RepoType)
 		}
 
-		if err == nil {
-			return commit, nil
+		// An empty repository is an answer, not a failure worth retrying
+		if err == nil || errors.Is(err, ErrCommitUnchanged) || errors.Is(err, ErrEmptyRepository) {
+			return commit, err
 		}
 
-		lastErr = err
+		lastErr = classifyTransportError(err)
+
+		// Wait for a near rate limit reset; a distant one is left to the next poll
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			rateLimitWait = time.Until(rateLimitErr.Reset)
+			if rateLimitWait > retryConfig.MaxDelay {
+				return nil, err
+			}
+			continue
+		}
+		rateLimitWait = 0
 
 		// Don't retry for authentication or client errors (4xx)
-		if strings.Contains(err.Error(), "status 4") {
+		if isClientError(err) {
 			break
 		}
 	}
@@ -260,37 +737,65 @@ func (m *MonitorService) GetLatestCommit(monitor *MonitorConfig, branch string)
 	return nil, fmt.Errorf("failed after %d retries: %w", retryConfig.MaxRetries, lastErr)
 }
 
+// monitorAuth returns the credentials for monitor's API calls and git commands, minting a
+// GitHub App installation token or refreshing an OAuth access token when configured
+func (m *MonitorService) monitorAuth(monitor *MonitorConfig) (AuthConfig, error) {
+	return resolveAuth(context.Background(), m.httpClient, monitor.Auth, monitor.RepoURL, monitor.APIBaseURL)
+}
+
 // getGitHubLatestCommit gets latest commit from GitHub API
-func (m *MonitorService) getGitHubLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
-	// Extract owner and repo from URL
-	parts := strings.Split(strings.TrimSuffix(monitor.RepoURL, "/"), "/")
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid GitHub URL format: %s", monitor.RepoURL)
+// A non-empty etagKey makes the request conditional: it sends If-None-Match and remembers the response
+// ETag under that key. Only polling passes one, so one-off lookups such as status never hide a change
+// from the next poll. Polling keys by repository entry and branch, so entries sharing a URL never
+// consume each other's changes.
+func (m *MonitorService) getGitHubLatestCommit(monitor *MonitorConfig, branch string, etagKey string) (*CommitInfo, error) {
+	repoAPIURL, err := githubRepoAPIURL(monitor)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
 	}
-	owner := parts[len(parts)-2]
-	repoName := parts[len(parts)-1]
 
 	// GitHub API endpoint for latest commit
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s", owner, repoName, branch)
+	url := fmt.Sprintf("%s/commits/%s", repoAPIURL, branch)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := m.newAPIRequest("GET", url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
 	// Add authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", monitor.Auth.Token))
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", auth.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	conditional := etagKey != ""
+	if conditional {
+		m.mu.RLock()
+		etag := m.etags[etagKey]
+		m.mu.RUnlock()
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
 
-	resp, err := m.httpClient.Do(req)
+	resp, err := m.doAPIRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("hTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if rateLimitErr := parseGitHubRateLimit(resp); rateLimitErr != nil {
+		return nil, rateLimitErr
+	}
+
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		return nil, ErrCommitUnchanged
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("gitHub API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{Provider: "gitHub", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
 	}
 
 	// Limit response body size to prevent memory issues
@@ -316,6 +821,12 @@ func (m *MonitorService) getGitHubLatestCommit(monitor *MonitorConfig, branch st
 		return nil, fmt.Errorf("failed to parse GitHub response: %w", err)
 	}
 
+	if etag := resp.Header.Get("ETag"); conditional && etag != "" {
+		m.mu.Lock()
+		m.etags[etagKey] = etag
+		m.mu.Unlock()
+	}
+
 	return &CommitInfo{
 		SHA:       githubCommit.SHA,
 		Message:   githubCommit.Commit.Message,
@@ -327,35 +838,27 @@ func (m *MonitorService) getGitHubLatestCommit(monitor *MonitorConfig, branch st
 
 // getGitLabLatestCommit gets latest commit from GitLab API
 func (m *MonitorService) getGitLabLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
-	url := strings.TrimSuffix(monitor.RepoURL, "/")
-
-	// Find the base URL and project path
-	var baseURL, projectPath string
-	if strings.Contains(url, "gitlab.com") {
-		baseURL = "https://gitlab.com"
-		projectPath = strings.TrimPrefix(url, "https://gitlab.com/")
-	} else if strings.Contains(url, "gitlab-master.nvidia.com") {
-		baseURL = "https://gitlab-master.nvidia.com"
-		projectPath = strings.TrimPrefix(url, "https://gitlab-master.nvidia.com/")
-	} else {
-		return nil, fmt.Errorf("unsupported GitLab URL format: %s", monitor.RepoURL)
+	apiBaseURL, projectPath, err := gitlabProject(monitor)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
 	}
-
-	// URL encode the project path
-	projectPath = strings.ReplaceAll(projectPath, "/", "%2F")
 
 	// GitLab API endpoint for latest commit
-	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits/%s", baseURL, projectPath, branch)
+	apiURL := fmt.Sprintf("%s/projects/%s/repository/commits/%s", apiBaseURL, projectPath, branch)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := m.newAPIRequest("GET", apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
 	// Add authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", monitor.Auth.Token))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.Token))
 
-	resp, err := m.httpClient.Do(req)
+	resp, err := m.doAPIRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("hTTP request failed: %w", err)
 	}
@@ -363,7 +866,11 @@ func (m *MonitorService) getGitLabLatestCommit(monitor *MonitorConfig, branch st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("gitLab API error (status %d): %s", resp.StatusCode, string(body))
+		// An empty project answers 404 like a missing branch; its empty_repo flag tells them apart
+		if resp.StatusCode == http.StatusNotFound && m.gitlabProjectEmpty(apiBaseURL, projectPath, auth.Token) {
+			return nil, fmt.Errorf("gitLab project %s: %w", monitor.RepoURL, ErrEmptyRepository)
+		}
+		return nil, &APIError{Provider: "gitLab", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
 	}
 
 	// Limit response body size to prevent memory issues
@@ -394,6 +901,33 @@ func (m *MonitorService) getGitLabLatestCommit(monitor *MonitorConfig, branch st
 	}, nil
 }
 
+// gitlabProjectEmpty reports whether a GitLab project exists but has no commits
+// Lookup failures report false so the original error is kept.
+func (m *MonitorService) gitlabProjectEmpty(apiBaseURL string, projectPath string, token string) bool {
+	req, err := m.newAPIRequest("GET", fmt.Sprintf("%s/projects/%s", apiBaseURL, projectPath))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := m.doAPIRequest(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	var project struct {
+		EmptyRepo bool `json:"empty_repo"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&project); err != nil {
+		return false
+	}
+	return project.EmptyRepo
+}
+
 // getGiteaLatestCommit gets latest commit from Gitea API
 func (m *MonitorService) getGiteaLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
 	// Extract base URL, owner and repo from URL
@@ -407,18 +941,23 @@ func (m *MonitorService) getGiteaLatestCommit(monitor *MonitorConfig, branch str
 	owner := parts[len(parts)-2]
 	repoName := parts[len(parts)-1]
 
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+
 	// Gitea API endpoint for latest commit
 	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/commits/%s", baseURL, owner, repoName, branch)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := m.newAPIRequest("GET", apiURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
 	// Add authorization header
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", monitor.Auth.Token))
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", auth.Token))
 
-	resp, err := m.httpClient.Do(req)
+	resp, err := m.doAPIRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("hTTP request failed: %w", err)
 	}
@@ -426,7 +965,7 @@ func (m *MonitorService) getGiteaLatestCommit(monitor *MonitorConfig, branch str
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("gitea API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &APIError{Provider: "gitea", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
 	}
 
 	// Limit response body size to prevent memory issues
@@ -461,19 +1000,463 @@ func (m *MonitorService) getGiteaLatestCommit(monitor *MonitorConfig, branch str
 	}, nil
 }
 
+// bitbucketAPIBaseURL is the Bitbucket Cloud REST API root
+const bitbucketAPIBaseURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketRepository extracts the workspace and repository slug from a Bitbucket URL
+func bitbucketRepository(repoURL string) (string, string, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git"), "/")
+	if len(parts) < 5 {
+		return "", "", fmt.Errorf("invalid Bitbucket URL format: %s", repoURL)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// getBitbucketLatestCommit gets latest commit from Bitbucket Cloud API
+func (m *MonitorService) getBitbucketLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
+	workspace, repoSlug, err := bitbucketRepository(monitor.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bitbucket API endpoint listing commits reachable from the branch, newest first
+	apiURL := fmt.Sprintf("%s/repositories/%s/%s/commits/%s?pagelen=1", bitbucketAPIBaseURL, workspace, repoSlug, branch)
+
+	req, err := m.newAPIRequest("GET", apiURL)
+	if err != nil {
+		return nil, err
+	}
+
+	setBitbucketAuth(req, auth)
+
+	resp, err := m.doAPIRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("hTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{Provider: "bitbucket", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
+	}
+
+	// Limit response body size to prevent memory issues
+	limitedReader := io.LimitReader(resp.Body, 1024*1024) // 1MB limit
+	body, err := io.ReadAll(limitedReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var bitbucketCommits struct {
+		Values []struct {
+			Hash    string    `json:"hash"`
+			Message string    `json:"message"`
+			Date    time.Time `json:"date"`
+			Author  struct {
+				Raw  string `json:"raw"`
+				User struct {
+					DisplayName string `json:"display_name"`
+				} `json:"user"`
+			} `json:"author"`
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+		} `json:"values"`
+	}
+
+	if err := json.Unmarshal(body, &bitbucketCommits); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitbucket response: %w", err)
+	}
+
+	if len(bitbucketCommits.Values) == 0 {
+		return nil, fmt.Errorf("%w: no commits found on branch %s", ErrBranchNotFound, branch)
+	}
+
+	latest := bitbucketCommits.Values[0]
+
+	// Prefer the linked account name; fall back to the raw "Name <email>" author
+	author := latest.Author.User.DisplayName
+	if author == "" {
+		author = latest.Author.Raw
+		if idx := strings.Index(author, " <"); idx > 0 {
+			author = author[:idx]
+		}
+	}
+
+	return &CommitInfo{
+		SHA:       latest.Hash,
+		Message:   latest.Message,
+		Author:    author,
+		Timestamp: latest.Date,
+		URL:       latest.Links.HTML.Href,
+	}, nil
+}
+
+// getGitLatestCommit gets the branch HEAD via "git ls-remote" for hosts without a supported API
+func (m *MonitorService) getGitLatestCommit(monitor *MonitorConfig, branch string) (*CommitInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getMonitorTimeout(m.currentConfig()))
+	defer cancel()
+
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+	remoteURL := authenticatedURL(monitor.RepoURL, monitor.RepoType, auth)
+	cmd := exec.CommandContext(ctx, getGitBinary(m.currentConfig()), "ls-remote", remoteURL, "refs/heads/"+branch)
+
+	// Avoid interactive prompts and trust the configured CA bundle
+	cmd.Env = gitCommandEnv(m.currentConfig())
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote failed for %s: %w", redactCredentials(monitor.RepoURL), err)
+	}
+
+	sha, err := parseLsRemoteOutput(string(output), branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plain git provides no commit metadata without a clone
+	return &CommitInfo{
+		SHA: sha,
+	}, nil
+}
+
+// parseLsRemoteOutput extracts the SHA for refs/heads/<branch> from "git ls-remote" output
+func parseLsRemoteOutput(output string, branch string) (string, error) {
+	ref := "refs/heads/" + branch
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s not in git ls-remote output", ErrBranchNotFound, branch)
+}
+
+// defaultGitHubAPIBaseURL is the public GitHub REST API endpoint
+const defaultGitHubAPIBaseURL = "https://api.github.com"
+
+// githubRepoAPIURL returns the REST API URL of a GitHub repository
+// Owner and name come from the last two RepoURL path segments, so enterprise hostnames work too;
+// APIBaseURL replaces the public endpoint for GitHub Enterprise Server.
+func githubRepoAPIURL(monitor *MonitorConfig) (string, error) {
+	parts := strings.Split(strings.TrimSuffix(monitor.RepoURL, "/"), "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid GitHub URL format: %s", monitor.RepoURL)
+	}
+	owner := parts[len(parts)-2]
+	repoName := parts[len(parts)-1]
+
+	baseURL := defaultGitHubAPIBaseURL
+	if monitor.APIBaseURL != "" {
+		baseURL = strings.TrimSuffix(monitor.APIBaseURL, "/")
+	}
+	return fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repoName), nil
+}
+
+// gitlabProject splits a GitLab repository URL into its API base URL and encoded project path
+// Any host works: the scheme and host form the base and the path (subgroups included) is the project.
+// APIBaseURL replaces the derived "<scheme>://<host>/api/v4" for unusual setups.
+func gitlabProject(monitor *MonitorConfig) (string, string, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(monitor.RepoURL, "/"))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", "", fmt.Errorf("unsupported GitLab URL format: %s", monitor.RepoURL)
+	}
+
+	projectPath := strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git")
+	if !strings.Contains(projectPath, "/") {
+		return "", "", fmt.Errorf("unsupported GitLab URL format: %s (want <namespace>/<project>)", monitor.RepoURL)
+	}
+
+	apiBaseURL := fmt.Sprintf("%s://%s/api/v4", parsed.Scheme, parsed.Host)
+	if monitor.APIBaseURL != "" {
+		apiBaseURL = strings.TrimSuffix(monitor.APIBaseURL, "/")
+	}
+
+	// URL encode the project path
+	return apiBaseURL, strings.ReplaceAll(projectPath, "/", "%2F"), nil
+}
+
+// maxBranchPages bounds branch-list pagination so a misbehaving API cannot loop forever
+const maxBranchPages = 50
+
+// branchPageSize is the page size requested from branch-list APIs
+const branchPageSize = 100
+
+// isLiteralBranch reports whether a configured branch is a plain name rather than a pattern
+// Dots are common in names such as release-1.0, so only regex syntax other than '.' makes a pattern.
+func isLiteralBranch(branch string) bool {
+	return !strings.ContainsAny(branch, `\+*?()|[]{}^$`)
+}
+
+// matchesBranch reports whether branch is the configured plain name or matches the configured pattern
+func matchesBranch(configured string, branch string) bool {
+	if isLiteralBranch(configured) {
+		return configured == branch
+	}
+	pattern, err := compileBranchPattern(configured)
+	return err == nil && pattern.MatchString(branch)
+}
+
+// compileBranchPattern compiles a configured branch pattern anchored to the full branch name
+func compileBranchPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// expandBranches resolves configured branch names and regex patterns into concrete branches
+// Plain names are used as-is without an API call; patterns are matched against the branch list.
+func (m *MonitorService) expandBranches(monitor *MonitorConfig) ([]string, error) {
+	var branches []string
+	var available []string
+	seen := make(map[string]bool)
+
+	add := func(branch string) {
+		if !seen[branch] {
+			seen[branch] = true
+			branches = append(branches, branch)
+		}
+	}
+
+	for _, configured := range monitor.Branches {
+		if isLiteralBranch(configured) {
+			add(configured)
+			continue
+		}
+
+		pattern, err := compileBranchPattern(configured)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch pattern %q: %w", configured, err)
+		}
+
+		// List branches once, only when a pattern needs it
+		if available == nil {
+			available, err = m.listBranches(monitor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list branches: %w", err)
+			}
+			sort.Strings(available)
+		}
+
+		matched := 0
+		for _, branch := range available {
+			if pattern.MatchString(branch) {
+				add(branch)
+				matched++
+			}
+		}
+
+		if matched == 0 {
+			AppLogger.DebugS("Branch pattern matched no branches",
+				"repo_url", monitor.RepoURL,
+				"pattern", configured)
+		}
+	}
+
+	return branches, nil
+}
+
+// listBranches returns every branch name of a repository using the provider's branch-list API
+func (m *MonitorService) listBranches(monitor *MonitorConfig) ([]string, error) {
+	switch monitor.RepoType {
+	case "github":
+		repoAPIURL, err := githubRepoAPIURL(monitor)
+		if err != nil {
+			return nil, err
+		}
+		auth, err := m.monitorAuth(monitor)
+		if err != nil {
+			return nil, err
+		}
+		return m.listBranchPages("gitHub", func(page int) string {
+			return fmt.Sprintf("%s/branches?per_page=%d&page=%d", repoAPIURL, branchPageSize, page)
+		}, fmt.Sprintf("token %s", auth.Token))
+	case "gitlab":
+		apiBaseURL, projectPath, err := gitlabProject(monitor)
+		if err != nil {
+			return nil, err
+		}
+		auth, err := m.monitorAuth(monitor)
+		if err != nil {
+			return nil, err
+		}
+		return m.listBranchPages("gitLab", func(page int) string {
+			return fmt.Sprintf("%s/projects/%s/repository/branches?per_page=%d&page=%d", apiBaseURL, projectPath, branchPageSize, page)
+		}, fmt.Sprintf("Bearer %s", auth.Token))
+	case "gitea":
+		parts := strings.Split(strings.TrimSuffix(monitor.RepoURL, "/"), "/")
+		if len(parts) < 5 {
+			return nil, fmt.Errorf("invalid Gitea URL format: %s", monitor.RepoURL)
+		}
+		baseURL := strings.Join(parts[:3], "/")
+		owner := parts[len(parts)-2]
+		repoName := parts[len(parts)-1]
+		auth, err := m.monitorAuth(monitor)
+		if err != nil {
+			return nil, err
+		}
+		return m.listBranchPages("gitea", func(page int) string {
+			return fmt.Sprintf("%s/api/v1/repos/%s/%s/branches?limit=%d&page=%d", baseURL, owner, repoName, branchPageSize, page)
+		}, fmt.Sprintf("token %s", auth.Token))
+	case "bitbucket":
+		return m.listBitbucketBranches(monitor)
+	case "git":
+		return m.listGitBranches(monitor)
+	default:
+		return nil, fmt.Errorf("unsupported repository type: %s", monitor.RepoType)
+	}
+}
+
+// listBranchPages walks a paginated branch-list endpoint returning [{"name": ...}] pages
+func (m *MonitorService) listBranchPages(provider string, pageURL func(page int) string, authorization string) ([]string, error) {
+	var branches []string
+
+	for page := 1; page <= maxBranchPages; page++ {
+		req, err := m.newAPIRequest("GET", pageURL(page))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", authorization)
+
+		resp, err := m.doAPIRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("hTTP request failed: %w", err)
+		}
+
+		// Limit response body size to prevent memory issues
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{Provider: provider, StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
+		}
+
+		var pageBranches []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &pageBranches); err != nil {
+			return nil, fmt.Errorf("failed to parse branch list: %w", err)
+		}
+
+		for _, branch := range pageBranches {
+			branches = append(branches, branch.Name)
+		}
+
+		if len(pageBranches) < branchPageSize {
+			break
+		}
+	}
+
+	return branches, nil
+}
+
+// listBitbucketBranches lists branches via the Bitbucket API, following "next" page links
+func (m *MonitorService) listBitbucketBranches(monitor *MonitorConfig) ([]string, error) {
+	workspace, repoSlug, err := bitbucketRepository(monitor.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	nextURL := fmt.Sprintf("%s/repositories/%s/%s/refs/branches?pagelen=%d", bitbucketAPIBaseURL, workspace, repoSlug, branchPageSize)
+
+	for page := 1; nextURL != "" && page <= maxBranchPages; page++ {
+		req, err := m.newAPIRequest("GET", nextURL)
+		if err != nil {
+			return nil, err
+		}
+		setBitbucketAuth(req, auth)
+
+		resp, err := m.doAPIRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("hTTP request failed: %w", err)
+		}
+
+		// Limit response body size to prevent memory issues
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{Provider: "bitbucket", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
+		}
+
+		var branchPage struct {
+			Values []struct {
+				Name string `json:"name"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if err := json.Unmarshal(body, &branchPage); err != nil {
+			return nil, fmt.Errorf("failed to parse branch list: %w", err)
+		}
+
+		for _, branch := range branchPage.Values {
+			branches = append(branches, branch.Name)
+		}
+		nextURL = branchPage.Next
+	}
+
+	return branches, nil
+}
+
+// listGitBranches lists branch heads via "git ls-remote --heads"
+func (m *MonitorService) listGitBranches(monitor *MonitorConfig) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), getMonitorTimeout(m.currentConfig()))
+	defer cancel()
+
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, getGitBinary(m.currentConfig()), "ls-remote", "--heads", authenticatedURL(monitor.RepoURL, monitor.RepoType, auth))
+	cmd.Env = gitCommandEnv(m.currentConfig())
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote failed for %s: %w", redactCredentials(monitor.RepoURL), err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.HasPrefix(fields[1], "refs/heads/") {
+			branches = append(branches, strings.TrimPrefix(fields[1], "refs/heads/"))
+		}
+	}
+	return branches, nil
+}
+
 // TriggerManualCheck performs a manual check of all repositories
-func (m *MonitorService) TriggerManualCheck() error {
+func (m *MonitorService) TriggerManualCheck(ctx context.Context) error {
 	AppLogger.Info("Performing manual repository check")
-	return m.CheckAllRepositories()
+	return m.CheckAllRepositories(ctx)
 }
 
 // triggerGroupDeployment triggers deployment for a group of repositories
-func (m *MonitorService) triggerGroupDeployment(groupName string, repositories []string) error {
+func (m *MonitorService) triggerGroupDeployment(ctx context.Context, groupName string, repositories []string, triggers map[string]*DeployTrigger) error {
 	if m.deployService == nil {
 		return fmt.Errorf("deploy service not initialized")
 	}
 
-	groupConfig, exists := m.config.Groups[groupName]
+	groupConfig, exists := m.currentConfig().Groups[groupName]
 	if !exists {
 		return fmt.Errorf("group configuration not found: %s", groupName)
 	}
@@ -483,20 +1466,21 @@ func (m *MonitorService) triggerGroupDeployment(groupName string, repositories [
 		"strategy", groupConfig.ExecutionStrategy,
 		"repositories", repositories)
 
-	return m.deployService.DeployGroup(groupName, repositories, &groupConfig)
+	return m.deployService.DeployGroup(ctx, groupName, repositories, &groupConfig, triggers)
 }
 
 // triggerIndividualDeployment triggers deployment for an individual repository
-func (m *MonitorService) triggerIndividualDeployment(repoName string) error {
+func (m *MonitorService) triggerIndividualDeployment(ctx context.Context, repoName string, trigger *DeployTrigger) error {
 	if m.deployService == nil {
 		return fmt.Errorf("deploy service not initialized")
 	}
 
 	// Find the repository config
 	var repoConfig *RepositoryConfig
-	for _, repo := range m.config.Repositories {
-		if repo.Name == repoName {
-			repoConfig = &repo
+	config := m.currentConfig()
+	for i := range config.Repositories {
+		if config.Repositories[i].Name == repoName {
+			repoConfig = &config.Repositories[i]
 			break
 		}
 	}
@@ -505,7 +1489,7 @@ func (m *MonitorService) triggerIndividualDeployment(repoName string) error {
 		return fmt.Errorf("repository configuration not found: %s", repoName)
 	}
 
-	AppLogger.InfoS("Starting individual deployment", "repo", repoName)
+	AppLogger.InfoS("Starting individual deployment", "repo", repoConfig.GetDisplayName())
 
-	return m.deployService.DeployIndividual(repoConfig)
+	return m.deployService.DeployIndividual(ctx, repoConfig, trigger)
 }
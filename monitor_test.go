@@ -1,6 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,7 +39,7 @@ func TestMonitorServiceBasics(t *testing.T) {
 		return
 	}
 
-	if service.config != config {
+	if service.currentConfig() != config {
 		t.Error("NewMonitorService() did not set config correctly")
 	}
 
@@ -83,6 +99,35 @@ func TestMonitorGetTimeoutFromConfig(t *testing.T) {
 	}
 }
 
+func TestMonitorAndDeployTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		global      GlobalConfig
+		wantMonitor time.Duration
+		wantDeploy  time.Duration
+	}{
+		{"defaults", GlobalConfig{}, 30 * time.Second, 30 * time.Second},
+		{"inherit timeout", GlobalConfig{Timeout: 300}, 300 * time.Second, 300 * time.Second},
+		{"separate timeouts", GlobalConfig{Timeout: 300, MonitorTimeout: 10, DeployTimeout: 900}, 10 * time.Second, 900 * time.Second},
+		{"only monitor set", GlobalConfig{MonitorTimeout: 10}, 10 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Global: tt.global}
+			if got := getMonitorTimeout(config); got != tt.wantMonitor {
+				t.Errorf("getMonitorTimeout() = %v, want %v", got, tt.wantMonitor)
+			}
+			if got := getDeployTimeout(config); got != tt.wantDeploy {
+				t.Errorf("getDeployTimeout() = %v, want %v", got, tt.wantDeploy)
+			}
+			if got := NewMonitorService(config, nil).httpClient.Timeout; got != tt.wantMonitor {
+				t.Errorf("monitor httpClient.Timeout = %v, want %v", got, tt.wantMonitor)
+			}
+		})
+	}
+}
+
 func TestMonitorTriggerManualCheck(t *testing.T) {
 	// Initialize logger for test
 	InitializeLogger(false)
@@ -109,11 +154,67 @@ func TestMonitorTriggerManualCheck(t *testing.T) {
 	}
 	deployService := NewDeployService(config)
 	service := NewMonitorService(config, deployService)
+	service.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"sha":"manual123","commit":{"message":"Initial"}}`), nil
+	})})
+
+	// The first manual check records the baseline commit without deploying
+	if err := service.TriggerManualCheck(context.Background()); err != nil {
+		t.Fatalf("TriggerManualCheck() error = %v", err)
+	}
+	if got := service.lastCommit[refCacheKey("test-repo", "main")]; got != "manual123" {
+		t.Errorf("recorded commit = %q, want manual123", got)
+	}
+}
+
+func TestMonitorStartMonitoringStopsOnCancel(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{
+		PollingInterval: 1,
+		Repositories: []RepositoryConfig{
+			{
+				Name: "poll-repo",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/owner/repo",
+					Branches: []string{"main"},
+					RepoType: "github",
+				},
+			},
+		},
+	}
+	monitor := NewMonitorService(config, NewDeployService(config))
 
-	// Test manual check (this will fail but we test the function call)
-	service.TriggerManualCheck()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// We can't verify much without mocking, but at least it doesn't panic
+	// Cancel once the first tick has polled the repository
+	polls := 0
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		polls++
+		if polls == 2 {
+			cancel()
+		}
+		return stubResponse(http.StatusOK, `{"sha":"abc123"}`), nil
+	})})
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- monitor.StartMonitoring(ctx)
+	}()
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("StartMonitoring() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("StartMonitoring() did not return after its context was cancelled")
+	}
+	if polls != 2 {
+		t.Errorf("polls = %d, want initial check plus one tick", polls)
+	}
 }
 
 func TestMonitorTriggerGroupDeployment(t *testing.T) {
@@ -140,7 +241,7 @@ func TestMonitorTriggerGroupDeployment(t *testing.T) {
 	repositories := []string{"repo1", "repo2"}
 
 	// Test group deployment trigger (this mainly tests that it doesn't panic)
-	err := service.triggerGroupDeployment("test-group", repositories)
+	err := service.triggerGroupDeployment(context.Background(), "test-group", repositories, nil)
 	if err != nil {
 		// This is expected to fail since we don't have real repos
 		t.Logf("triggerGroupDeployment() returned expected error: %v", err)
@@ -163,7 +264,7 @@ func TestMonitorTriggerIndividualDeployment(t *testing.T) {
 	repoName := "individual-repo"
 
 	// Test individual deployment trigger (this mainly tests that it doesn't panic)
-	err := service.triggerIndividualDeployment(repoName)
+	err := service.triggerIndividualDeployment(context.Background(), repoName, nil)
 	if err != nil {
 		// This is expected to fail since we don't have real repos
 		t.Logf("triggerIndividualDeployment() returned expected error: %v", err)
@@ -199,17 +300,247 @@ func TestMonitorCommitChangeDetection(t *testing.T) {
 }
 
 func TestMonitorRetryConfig(t *testing.T) {
+	zero := 0
+	five := 5
+	one := 1
+
+	tests := []struct {
+		name     string
+		global   GlobalConfig
+		expected RetryConfig
+	}{
+		{
+			name:     "defaults when unset",
+			global:   GlobalConfig{},
+			expected: RetryConfig{MaxRetries: 3, RetryDelay: 2 * time.Second, MaxDelay: 30 * time.Second},
+		},
+		{
+			name:     "configured values",
+			global:   GlobalConfig{MaxRetries: &five, RetryDelay: &one, RetryMaxDelay: 10},
+			expected: RetryConfig{MaxRetries: 5, RetryDelay: time.Second, MaxDelay: 10 * time.Second},
+		},
+		{
+			name:     "retries disabled",
+			global:   GlobalConfig{MaxRetries: &zero, RetryDelay: &zero},
+			expected: RetryConfig{MaxRetries: 0, RetryDelay: 0, MaxDelay: 30 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewMonitorService(&Config{Global: tt.global}, nil)
+			if service.retry != tt.expected {
+				t.Errorf("NewMonitorService() retry = %+v, want %+v", service.retry, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorGetLatestCommitHonorsMaxRetries(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	maxRetries := 4
+	retryDelay := 0
+	monitor := NewMonitorService(&Config{Global: GlobalConfig{MaxRetries: &maxRetries, RetryDelay: &retryDelay}}, nil)
+
+	attempts := 0
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return stubResponse(http.StatusBadGateway, `{"message":"Bad Gateway"}`), nil
+	})})
+
+	_, err := monitor.GetLatestCommit(&MonitorConfig{
+		RepoURL:  "https://github.com/owner/repo",
+		RepoType: "github",
+		Auth:     AuthConfig{Token: "token"},
+	}, "main")
+	if err == nil || !strings.Contains(err.Error(), "failed after 4 retries") {
+		t.Errorf("GetLatestCommit() error = %v, want failure after 4 retries", err)
+	}
+	if attempts != maxRetries+1 {
+		t.Errorf("GetLatestCommit() attempts = %d, want %d", attempts, maxRetries+1)
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
 	retryConfig := RetryConfig{
-		MaxRetries: 3,
-		RetryDelay: 2 * time.Second,
+		MaxRetries: 6,
+		RetryDelay: time.Second,
+		MaxDelay:   5 * time.Second,
+	}
+
+	// Each attempt's jitter range is [delay/2, delay] of a doubling delay capped at MaxDelay
+	bounds := []struct {
+		min time.Duration
+		max time.Duration
+	}{
+		{500 * time.Millisecond, time.Second},
+		{time.Second, 2 * time.Second},
+		{2 * time.Second, 4 * time.Second},
+		{2500 * time.Millisecond, 5 * time.Second},
+		{2500 * time.Millisecond, 5 * time.Second},
+	}
+
+	for i := 0; i < 100; i++ {
+		for j, bound := range bounds {
+			attempt := j + 1
+			delay := retryConfig.Backoff(attempt)
+			if delay < bound.min || delay > bound.max {
+				t.Fatalf("Backoff(%d) = %v, want within [%v, %v]", attempt, delay, bound.min, bound.max)
+			}
+		}
+	}
+
+	if delay := (RetryConfig{}).Backoff(1); delay != 0 {
+		t.Errorf("Backoff() with zero delay = %v, want 0", delay)
+	}
+}
+
+func TestMonitorGetLatestCommitBackoff(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	maxRetries := 4
+	retryDelay := 1
+	monitor := NewMonitorService(&Config{Global: GlobalConfig{
+		MaxRetries:    &maxRetries,
+		RetryDelay:    &retryDelay,
+		RetryMaxDelay: 4,
+	}}, nil)
+
+	var delays []time.Duration
+	monitor.sleep = func(delay time.Duration) {
+		delays = append(delays, delay)
+	}
+
+	attempts := 0
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return stubResponse(http.StatusServiceUnavailable, `{"message":"Unavailable"}`), nil
+	})})
+
+	commitMonitor := &MonitorConfig{
+		RepoURL:  "https://github.com/owner/repo",
+		RepoType: "github",
+		Auth:     AuthConfig{Token: "token"},
+	}
+	if _, err := monitor.GetLatestCommit(commitMonitor, "main"); err == nil {
+		t.Fatal("GetLatestCommit() expected error")
+	}
+
+	if attempts != maxRetries+1 {
+		t.Errorf("GetLatestCommit() attempts = %d, want %d", attempts, maxRetries+1)
+	}
+	if len(delays) != maxRetries {
+		t.Fatalf("GetLatestCommit() slept %d times, want %d", len(delays), maxRetries)
+	}
+	for i, delay := range delays {
+		if delay > 4*time.Second {
+			t.Errorf("delay[%d] = %v, exceeds retry_max_delay", i, delay)
+		}
+		// Jitter ranges of uncapped attempts do not overlap, so delays grow until the cap
+		if i > 0 && i < 3 && delay < delays[i-1] {
+			t.Errorf("delay[%d] = %v, want >= previous %v", i, delay, delays[i-1])
+		}
+	}
+
+	// Client errors are not retried
+	attempts = 0
+	delays = nil
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return stubResponse(http.StatusUnauthorized, `{"message":"Bad credentials"}`), nil
+	})})
+	if _, err := monitor.GetLatestCommit(commitMonitor, "main"); err == nil {
+		t.Fatal("GetLatestCommit() expected error for 401")
+	}
+	if attempts != 1 || len(delays) != 0 {
+		t.Errorf("GetLatestCommit() on 401 attempts = %d, sleeps = %d, want 1 and 0", attempts, len(delays))
+	}
+}
+
+// rateLimitedResponse returns a GitHub 403 response with an exhausted rate limit resetting at reset
+func rateLimitedResponse(reset time.Time) *http.Response {
+	resp := stubResponse(http.StatusForbidden, `{"message":"API rate limit exceeded"}`)
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return resp
+}
+
+func TestMonitorGitHubRateLimitWaitsForReset(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	monitor := NewMonitorService(&Config{Global: GlobalConfig{RetryMaxDelay: 10}}, nil)
+
+	var delays []time.Duration
+	monitor.sleep = func(delay time.Duration) {
+		delays = append(delays, delay)
+	}
+
+	attempts := 0
+	reset := time.Now().Add(5 * time.Second)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return rateLimitedResponse(reset), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"msg","author":{"name":"dev"}}}`), nil
+	})})
+
+	commit, err := monitor.GetLatestCommit(&MonitorConfig{
+		RepoURL:  "https://github.com/owner/repo",
+		RepoType: "github",
+		Auth:     AuthConfig{Token: "token"},
+	}, "main")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if commit.SHA != "abc123" {
+		t.Errorf("GetLatestCommit() SHA = %s, want abc123", commit.SHA)
+	}
+
+	// The wait follows the reset header instead of the 2s base backoff
+	if len(delays) != 1 {
+		t.Fatalf("GetLatestCommit() slept %d times, want 1", len(delays))
 	}
+	if delays[0] < 3*time.Second || delays[0] > 5*time.Second {
+		t.Errorf("GetLatestCommit() waited %v, want about 5s until reset", delays[0])
+	}
+}
+
+func TestMonitorGitHubRateLimitDistantReset(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
 
-	if retryConfig.MaxRetries != 3 {
-		t.Errorf("RetryConfig.MaxRetries = %v, want %v", retryConfig.MaxRetries, 3)
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.sleep = func(delay time.Duration) {
+		t.Errorf("GetLatestCommit() slept %v, want no wait for a distant reset", delay)
 	}
 
-	if retryConfig.RetryDelay != 2*time.Second {
-		t.Errorf("RetryConfig.RetryDelay = %v, want %v", retryConfig.RetryDelay, 2*time.Second)
+	attempts := 0
+	reset := time.Now().Add(time.Hour)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return rateLimitedResponse(reset), nil
+	})})
+
+	_, err := monitor.GetLatestCommit(&MonitorConfig{
+		RepoURL:  "https://github.com/owner/repo",
+		RepoType: "github",
+		Auth:     AuthConfig{Token: "token"},
+	}, "main")
+
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("GetLatestCommit() error = %v, want RateLimitError", err)
+	}
+	if rateLimitErr.Reset.Unix() != reset.Unix() {
+		t.Errorf("RateLimitError.Reset = %v, want %v", rateLimitErr.Reset, reset)
+	}
+	if attempts != 1 {
+		t.Errorf("GetLatestCommit() attempts = %d, want 1", attempts)
 	}
 }
 
@@ -307,8 +638,9 @@ func TestMonitorGitLabUnsupportedURL(t *testing.T) {
 	deployService := NewDeployService(config)
 	service := NewMonitorService(config, deployService)
 
+	// Without a scheme and host the API base cannot be derived, so no request is made
 	monitor := &MonitorConfig{
-		RepoURL:  "https://unsupported-gitlab.com/owner/repo",
+		RepoURL:  "gitlab.company.com/owner/repo",
 		RepoType: "gitlab",
 		Auth: AuthConfig{
 			Username: "testuser",
@@ -317,13 +649,65 @@ func TestMonitorGitLabUnsupportedURL(t *testing.T) {
 	}
 
 	_, err := service.getGitLabLatestCommit(monitor, "main")
-	if err == nil {
-		t.Error("getGitLabLatestCommit() should return error for unsupported URL format")
+	if err == nil || !strings.Contains(err.Error(), "unsupported GitLab URL format") {
+		t.Errorf("getGitLabLatestCommit() error = %v, want unsupported GitLab URL format", err)
 	}
+}
 
-	// Either gets "unsupported GitLab URL format" or API error, both are valid failures
-	if err == nil {
-		t.Error("getGitLabLatestCommit() should return some error for unsupported URL format")
+func TestGitLabProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitor     MonitorConfig
+		wantBase    string
+		wantProject string
+		wantErr     bool
+	}{
+		{
+			name:        "gitlab.com",
+			monitor:     MonitorConfig{RepoURL: "https://gitlab.com/owner/repo"},
+			wantBase:    "https://gitlab.com/api/v4",
+			wantProject: "owner%2Frepo",
+		},
+		{
+			name:        "self-hosted with port",
+			monitor:     MonitorConfig{RepoURL: "https://git.company.com:8443/team/service.git"},
+			wantBase:    "https://git.company.com:8443/api/v4",
+			wantProject: "team%2Fservice",
+		},
+		{
+			name:        "subgroup path",
+			monitor:     MonitorConfig{RepoURL: "https://gitlab-master.nvidia.com/group/subgroup/project/"},
+			wantBase:    "https://gitlab-master.nvidia.com/api/v4",
+			wantProject: "group%2Fsubgroup%2Fproject",
+		},
+		{
+			name:        "explicit api base url",
+			monitor:     MonitorConfig{RepoURL: "https://code.company.com/group/project", APIBaseURL: "https://code.company.com/gitlab/api/v4/"},
+			wantBase:    "https://code.company.com/gitlab/api/v4",
+			wantProject: "group%2Fproject",
+		},
+		{
+			name:    "missing project",
+			monitor: MonitorConfig{RepoURL: "https://gitlab.com/owner"},
+			wantErr: true,
+		},
+		{
+			name:    "missing scheme",
+			monitor: MonitorConfig{RepoURL: "gitlab.com/owner/repo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, project, err := gitlabProject(&tt.monitor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gitlabProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if base != tt.wantBase || project != tt.wantProject {
+				t.Errorf("gitlabProject() = %q, %q; want %q, %q", base, project, tt.wantBase, tt.wantProject)
+			}
+		})
 	}
 }
 
@@ -336,3 +720,1227 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestGitHubRepoAPIURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		monitor  MonitorConfig
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "public github",
+			monitor:  MonitorConfig{RepoURL: "https://github.com/owner/repo"},
+			expected: "https://api.github.com/repos/owner/repo",
+		},
+		{
+			name:     "enterprise hostname",
+			monitor:  MonitorConfig{RepoURL: "https://ghe.company.com/owner/repo/", APIBaseURL: "https://ghe.company.com/api/v3/"},
+			expected: "https://ghe.company.com/api/v3/repos/owner/repo",
+		},
+		{
+			name:    "invalid url",
+			monitor: MonitorConfig{RepoURL: "repo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := githubRepoAPIURL(&tt.monitor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("githubRepoAPIURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("githubRepoAPIURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorGitHubEnterpriseRequests(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	var requested []string
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.String())
+		if strings.Contains(req.URL.Path, "/branches") {
+			return stubResponse(http.StatusOK, `[{"name":"main"}]`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"ghe-sha"}`), nil
+	})})
+
+	config := &MonitorConfig{
+		RepoURL:    "https://ghe.company.com/owner/repo",
+		RepoType:   "github",
+		Branches:   []string{"ma.*"},
+		APIBaseURL: "https://ghe.company.com/api/v3",
+	}
+
+	branches, err := monitor.expandBranches(config)
+	if err != nil || len(branches) != 1 {
+		t.Fatalf("expandBranches() = %v, %v; want [main]", branches, err)
+	}
+	commit, err := monitor.GetLatestCommit(config, branches[0])
+	if err != nil || commit.SHA != "ghe-sha" {
+		t.Fatalf("GetLatestCommit() = %v, %v; want ghe-sha", commit, err)
+	}
+
+	want := []string{
+		"https://ghe.company.com/api/v3/repos/owner/repo/branches?per_page=100&page=1",
+		"https://ghe.company.com/api/v3/repos/owner/repo/commits/main",
+	}
+	if strings.Join(requested, ",") != strings.Join(want, ",") {
+		t.Errorf("requested = %v, want %v", requested, want)
+	}
+}
+
+func TestParseLsRemoteOutput(t *testing.T) {
+	output := "1111111111111111111111111111111111111111\trefs/heads/main\n" +
+		"2222222222222222222222222222222222222222\trefs/heads/main-backup\n" +
+		"3333333333333333333333333333333333333333\trefs/heads/dev\n"
+
+	tests := []struct {
+		name    string
+		branch  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "exact branch match",
+			branch: "main",
+			want:   "1111111111111111111111111111111111111111",
+		},
+		{
+			name:   "other branch",
+			branch: "dev",
+			want:   "3333333333333333333333333333333333333333",
+		},
+		{
+			name:    "missing branch",
+			branch:  "release",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLsRemoteOutput(output, tt.branch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLsRemoteOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseLsRemoteOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorGitProviderLsRemote(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// Use a local repository so ls-remote works without network access
+	repoDir := newLocalQARepo(t, nil)
+	headSHA, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse failed: %v", err)
+	}
+
+	config := &Config{
+		PollingInterval: 60,
+		Global: GlobalConfig{
+			Timeout: 30,
+		},
+	}
+	service := NewMonitorService(config, NewDeployService(config))
+
+	monitor := &MonitorConfig{
+		RepoURL:  repoDir,
+		RepoType: "git",
+	}
+
+	commit, err := service.GetLatestCommit(monitor, "main")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if commit.SHA != strings.TrimSpace(string(headSHA)) {
+		t.Errorf("GetLatestCommit() SHA = %v, want %v", commit.SHA, strings.TrimSpace(string(headSHA)))
+	}
+}
+
+func TestMonitorDisplayNameInLogsMachineNameInState(t *testing.T) {
+	var buf bytes.Buffer
+	AppLogger = &Logger{level: LogLevelInfo, logger: log.New(&buf, "", 0)}
+	defer InitializeLogger(false)
+
+	repoDir := newLocalQARepo(t, nil)
+	config := &Config{
+		PollingInterval: 60,
+		Global: GlobalConfig{
+			Timeout: 30,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name:        "rag-prod",
+				DisplayName: "RAG Blueprint (prod)",
+				Monitor: MonitorConfig{
+					RepoURL:  repoDir,
+					Branches: []string{"main"},
+					RepoType: "git",
+				},
+			},
+		},
+	}
+	service := NewMonitorService(config, NewDeployService(config))
+
+	if _, err := service.checkRepositoryBranch(&config.Repositories[0], "main"); err != nil {
+		t.Fatalf("checkRepositoryBranch() error = %v", err)
+	}
+
+	if _, exists := service.lastCommit["rag-prod:main"]; !exists {
+		t.Errorf("state should be keyed by machine name, got keys: %v", service.lastCommit)
+	}
+
+	if !strings.Contains(buf.String(), "[repo=RAG Blueprint (prod)]") {
+		t.Errorf("log output should use the display name, got: %s", buf.String())
+	}
+}
+
+// stubRoundTripper serves HTTP requests from a function instead of the network
+type stubRoundTripper func(req *http.Request) (*http.Response, error)
+
+func (f stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubResponse builds an HTTP response with the given status and body
+func stubResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestMonitorExpandBranches(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	branchList := `[{"name":"main"},{"name":"develop"},{"name":"dev-feature"},{"name":"release"},{"name":"old-dev"}]`
+
+	tests := []struct {
+		name        string
+		monitor     MonitorConfig
+		wantURL     string
+		wantAuth    string
+		want        []string
+		wantListing bool
+	}{
+		{
+			name: "github regex expands against branch list",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				RepoType: "github",
+				Branches: []string{"main", "dev.*"},
+				Auth:     AuthConfig{Token: "gh-token"},
+			},
+			wantURL:     "https://api.github.com/repos/owner/repo/branches?per_page=100&page=1",
+			wantAuth:    "token gh-token",
+			want:        []string{"main", "dev-feature", "develop"},
+			wantListing: true,
+		},
+		{
+			name: "gitlab regex uses project branch API",
+			monitor: MonitorConfig{
+				RepoURL:  "https://gitlab.com/group/sub/repo",
+				RepoType: "gitlab",
+				Branches: []string{"rel.*"},
+				Auth:     AuthConfig{Token: "gl-token"},
+			},
+			wantURL:     "https://gitlab.com/api/v4/projects/group%2Fsub%2Frepo/repository/branches?per_page=100&page=1",
+			wantAuth:    "Bearer gl-token",
+			want:        []string{"release"},
+			wantListing: true,
+		},
+		{
+			name: "gitea regex uses repo branch API",
+			monitor: MonitorConfig{
+				RepoURL:  "https://gitea.example.com/owner/repo",
+				RepoType: "gitea",
+				Branches: []string{"main|release"},
+				Auth:     AuthConfig{Token: "gt-token"},
+			},
+			wantURL:     "https://gitea.example.com/api/v1/repos/owner/repo/branches?limit=100&page=1",
+			wantAuth:    "token gt-token",
+			want:        []string{"main", "release"},
+			wantListing: true,
+		},
+		{
+			name: "plain names are exact matches without listing",
+			monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/repo",
+				RepoType: "github",
+				Branches: []string{"main", "feature-x", "release-1.0", "v1.2"},
+			},
+			want:        []string{"main", "feature-x", "release-1.0", "v1.2"},
+			wantListing: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*http.Request
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req)
+				return stubResponse(http.StatusOK, branchList), nil
+			})})
+
+			got, err := monitor.expandBranches(&tt.monitor)
+			if err != nil {
+				t.Fatalf("expandBranches() error = %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expandBranches() = %v, want %v", got, tt.want)
+			}
+
+			if !tt.wantListing {
+				if len(requests) != 0 {
+					t.Errorf("expandBranches() made %d requests, want none for plain names", len(requests))
+				}
+				return
+			}
+			if len(requests) != 1 {
+				t.Fatalf("expandBranches() made %d requests, want 1", len(requests))
+			}
+			if requests[0].URL.String() != tt.wantURL {
+				t.Errorf("request URL = %s, want %s", requests[0].URL, tt.wantURL)
+			}
+			if requests[0].Header.Get("Authorization") != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", requests[0].Header.Get("Authorization"), tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestMonitorListBranchesPagination(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// First page is full, so a second page must be requested
+	var fullPage []string
+	for i := 0; i < branchPageSize; i++ {
+		fullPage = append(fullPage, fmt.Sprintf(`{"name":"feature-%03d"}`, i))
+	}
+
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page") == "1" {
+			return stubResponse(http.StatusOK, "["+strings.Join(fullPage, ",")+"]"), nil
+		}
+		return stubResponse(http.StatusOK, `[{"name":"main"}]`), nil
+	})})
+
+	branches, err := monitor.listBranches(&MonitorConfig{RepoURL: "https://github.com/owner/repo", RepoType: "github"})
+	if err != nil {
+		t.Fatalf("listBranches() error = %v", err)
+	}
+	if len(branches) != branchPageSize+1 || branches[len(branches)-1] != "main" {
+		t.Errorf("listBranches() returned %d branches, want %d ending in main", len(branches), branchPageSize+1)
+	}
+}
+
+func TestMonitorExpandBranchesListError(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusNotFound, `{"message":"Not Found"}`), nil
+	})})
+
+	_, err := monitor.expandBranches(&MonitorConfig{
+		RepoURL:  "https://github.com/owner/repo",
+		RepoType: "github",
+		Branches: []string{"dev.*"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("expandBranches() error = %v, want branch list status 404", err)
+	}
+}
+
+func TestMonitorBitbucketLatestCommit(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantAuthor string
+	}{
+		{
+			name: "linked account author",
+			body: `{"values":[{"hash":"0123456789abcdef","message":"Fix pipeline\n","date":"2024-05-01T10:00:00+00:00",
+				"author":{"raw":"Jane Doe <jane@example.com>","user":{"display_name":"Jane D."}},
+				"links":{"html":{"href":"https://bitbucket.org/workspace/repo/commits/0123456789abcdef"}}},
+				{"hash":"older"}]}`,
+			wantAuthor: "Jane D.",
+		},
+		{
+			name:       "raw author without account",
+			body:       `{"values":[{"hash":"0123456789abcdef","message":"Fix pipeline\n","date":"2024-05-01T10:00:00+00:00","author":{"raw":"Jane Doe <jane@example.com>"}}]}`,
+			wantAuthor: "Jane Doe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *http.Request
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				request = req
+				return stubResponse(http.StatusOK, tt.body), nil
+			})})
+
+			commit, err := monitor.GetLatestCommit(&MonitorConfig{
+				RepoURL:  "https://bitbucket.org/workspace/repo",
+				RepoType: "bitbucket",
+				Auth:     AuthConfig{Username: "bot", Token: "app-password"},
+			}, "main")
+			if err != nil {
+				t.Fatalf("GetLatestCommit() error = %v", err)
+			}
+
+			wantURL := "https://api.bitbucket.org/2.0/repositories/workspace/repo/commits/main?pagelen=1"
+			if request.URL.String() != wantURL {
+				t.Errorf("request URL = %s, want %s", request.URL, wantURL)
+			}
+			if username, password, ok := request.BasicAuth(); !ok || username != "bot" || password != "app-password" {
+				t.Errorf("request basic auth = %q/%q (%v), want bot/app-password", username, password, ok)
+			}
+
+			if commit.SHA != "0123456789abcdef" {
+				t.Errorf("commit SHA = %s, want values[0] hash", commit.SHA)
+			}
+			if commit.Author != tt.wantAuthor {
+				t.Errorf("commit Author = %q, want %q", commit.Author, tt.wantAuthor)
+			}
+			if commit.Message != "Fix pipeline\n" {
+				t.Errorf("commit Message = %q", commit.Message)
+			}
+			if commit.Timestamp.IsZero() {
+				t.Error("commit Timestamp should be parsed")
+			}
+		})
+	}
+}
+
+func TestMonitorBitbucketListBranches(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("page") == "2" {
+			return stubResponse(http.StatusOK, `{"values":[{"name":"develop"}]}`), nil
+		}
+		return stubResponse(http.StatusOK,
+			`{"values":[{"name":"main"}],"next":"https://api.bitbucket.org/2.0/repositories/workspace/repo/refs/branches?page=2"}`), nil
+	})})
+
+	branches, err := monitor.expandBranches(&MonitorConfig{
+		RepoURL:  "https://bitbucket.org/workspace/repo",
+		RepoType: "bitbucket",
+		Branches: []string{"dev.*|main"},
+	})
+	if err != nil {
+		t.Fatalf("expandBranches() error = %v", err)
+	}
+	if strings.Join(branches, ",") != "develop,main" {
+		t.Errorf("expandBranches() = %v, want [develop main]", branches)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	tests := []struct {
+		sha  string
+		want string
+	}{
+		{sha: "0123456789abcdef", want: "01234567"},
+		{sha: "01234567", want: "01234567"},
+		{sha: "abcd", want: "abcd"},
+		{sha: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sha, func(t *testing.T) {
+			if got := shortSHA(tt.sha); got != tt.want {
+				t.Errorf("shortSHA(%q) = %q, want %q", tt.sha, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorCheckRepositoryBranchShortSHA(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// Providers occasionally return short or empty SHAs; abbreviating them must not panic
+	shas := []string{"abcd", ""}
+	call := 0
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		sha := shas[call]
+		call++
+		return stubResponse(http.StatusOK, `{"sha":"`+sha+`"}`), nil
+	})})
+
+	repo := &RepositoryConfig{
+		Name: "short-sha-repo",
+		Monitor: MonitorConfig{
+			RepoURL:  "https://gitea.example.com/owner/repo",
+			RepoType: "gitea",
+		},
+	}
+
+	if changed, err := monitor.checkRepositoryBranch(repo, "main"); err != nil || changed {
+		t.Fatalf("first checkRepositoryBranch() = %v, %v; want baseline without change", changed, err)
+	}
+	if changed, err := monitor.checkRepositoryBranch(repo, "main"); err != nil || !changed {
+		t.Errorf("second checkRepositoryBranch() = %v, %v; want change detected", changed, err)
+	}
+}
+
+// TestMonitorIndividualDeploymentsParallelLimit checks at most MaxParallelIndividual ungrouped deployments run at once
+func TestMonitorIndividualDeploymentsParallelLimit(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	markers := t.TempDir()
+	config := newDrainTestConfig(t, nil)
+	config.Global.MaxParallelIndividual = 2
+
+	template := config.Repositories[0]
+	config.Repositories = nil
+	var names []string
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		repo := template
+		repo.Name = name
+		repo.Deploy.Commands = []CommandSpec{{Run: fmt.Sprintf(
+			"touch %[1]s/running-%[2]s; sleep 0.5; ls %[1]s | grep -c running- > %[1]s/seen-%[2]s; rm %[1]s/running-%[2]s",
+			markers, name)}}
+		config.Repositories = append(config.Repositories, repo)
+		names = append(names, name)
+	}
+
+	monitor := NewMonitorService(config, NewDeployService(config))
+	if failures := monitor.triggerIndividualDeployments(context.Background(), names, nil); len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+
+	maxSeen := 0
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(markers, "seen-"+name))
+		if err != nil {
+			t.Fatalf("deployment %s did not run: %v", name, err)
+		}
+		seen, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			t.Fatalf("invalid marker for %s: %q", name, data)
+		}
+		if seen > maxSeen {
+			maxSeen = seen
+		}
+	}
+	if maxSeen > 2 {
+		t.Errorf("expected at most 2 concurrent deployments, saw %d", maxSeen)
+	}
+	if maxSeen < 2 {
+		t.Errorf("expected deployments to run concurrently, saw at most %d", maxSeen)
+	}
+}
+
+// TestGetMaxParallelIndividual checks the sequential default
+func TestGetMaxParallelIndividual(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    int
+		expected int
+	}{
+		{"unset defaults to sequential", 0, 1},
+		{"configured", 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Global: GlobalConfig{MaxParallelIndividual: tt.value}}
+			if got := getMaxParallelIndividual(config); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestMonitorAddGroupTriggerDeduplicates checks two changed members of one group list each repository once
+func TestMonitorAddGroupTriggerDeduplicates(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{
+		Repositories: []RepositoryConfig{
+			{Name: "frontend", Group: "web"},
+			{Name: "backend", Group: "web"},
+			{Name: "worker"},
+		},
+	}
+	monitor := NewMonitorService(config, nil)
+
+	triggers := make(map[string]*GroupTrigger)
+	monitor.addGroupTrigger(triggers, &config.Repositories[0], []TriggerSource{{RepoName: "frontend", Branch: "main"}})
+	monitor.addGroupTrigger(triggers, &config.Repositories[1], []TriggerSource{{RepoName: "backend", Branch: "main"}})
+
+	if len(triggers) != 1 {
+		t.Fatalf("expected 1 group trigger, got %d", len(triggers))
+	}
+	trigger := triggers["web"]
+	expected := []string{"frontend", "backend"}
+	if strings.Join(trigger.Repositories, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected repositories %v, got %v", expected, trigger.Repositories)
+	}
+	if trigger.TriggerRepo != "frontend" {
+		t.Errorf("expected trigger repo frontend, got %s", trigger.TriggerRepo)
+	}
+	if len(trigger.Sources) != 2 {
+		t.Errorf("expected 2 trigger sources, got %d", len(trigger.Sources))
+	}
+}
+
+// TestCommitMessageAllowed checks include and exclude message filters
+func TestCommitMessageAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		message string
+		want    bool
+	}{
+		{"no filters", "", "", "docs: fix typo", true},
+		{"include only matches", `^(feat|fix)`, "", "fix: null pointer", true},
+		{"include only does not match", `^(feat|fix)`, "", "docs: fix typo", false},
+		{"exclude only matches", "", `\[skip ci\]`, "chore: bump [skip ci]", false},
+		{"exclude only does not match", "", `\[skip ci\]`, "feat: new endpoint", true},
+		{"both pass", `^(feat|fix)`, `\[skip ci\]`, "feat: new endpoint", true},
+		{"both with exclude match", `^(feat|fix)`, `\[skip ci\]`, "feat: wip [skip ci]", false},
+		{"both with include miss", `^(feat|fix)`, `\[skip ci\]`, "docs: readme", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := &MonitorConfig{IncludeMessageRegex: tt.include, ExcludeMessageRegex: tt.exclude}
+			got, reason := commitMessageAllowed(monitor, tt.message)
+			if got != tt.want {
+				t.Errorf("commitMessageAllowed(%q) = %v (%s), want %v", tt.message, got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("expected a reason for the skipped commit")
+			}
+		})
+	}
+}
+
+// TestMonitorCheckRepositoryBranchSkipsFilteredMessage checks a filtered commit is recorded as seen without triggering
+func TestMonitorCheckRepositoryBranchSkipsFilteredMessage(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	commits := []string{
+		`{"sha":"aaa","commit":{"message":"feat: initial"}}`,
+		`{"sha":"bbb","commit":{"message":"docs: typo [skip ci]"}}`,
+		`{"sha":"ccc","commit":{"message":"fix: crash"}}`,
+	}
+	call := 0
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		body := commits[call]
+		call++
+		return stubResponse(http.StatusOK, body), nil
+	})})
+
+	repo := &RepositoryConfig{
+		Name: "filtered-repo",
+		Monitor: MonitorConfig{
+			RepoURL:             "https://github.com/owner/repo",
+			RepoType:            "github",
+			ExcludeMessageRegex: `\[skip ci\]`,
+		},
+	}
+
+	wantChanged := []bool{false, false, true}
+	for i, want := range wantChanged {
+		changed, err := monitor.checkRepositoryBranch(repo, "main")
+		if err != nil {
+			t.Fatalf("check %d: checkRepositoryBranch() error = %v", i+1, err)
+		}
+		if changed != want {
+			t.Errorf("check %d: changed = %v, want %v", i+1, changed, want)
+		}
+	}
+	if got := monitor.lastCommit[refCacheKey("filtered-repo", "main")]; got != "ccc" {
+		t.Errorf("last recorded commit = %s, want ccc", got)
+	}
+}
+
+// TestMonitorGetLatestCommitParsesProviders checks each provider's commit JSON through an injected client
+func TestMonitorGetLatestCommitParsesProviders(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	timestamp := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		monitor  MonitorConfig
+		wantURL  string
+		wantAuth string
+		body     string
+	}{
+		{
+			name:     "github",
+			monitor:  MonitorConfig{RepoURL: "https://github.com/owner/repo", RepoType: "github", Auth: AuthConfig{Token: "gh-token"}},
+			wantURL:  "https://api.github.com/repos/owner/repo/commits/main",
+			wantAuth: "token gh-token",
+			body: `{"sha":"abc123","html_url":"https://example.com/c/abc123",
+				"commit":{"message":"Add feature","author":{"name":"Alice","date":"2024-05-01T12:30:00Z"}}}`,
+		},
+		{
+			name:     "gitlab",
+			monitor:  MonitorConfig{RepoURL: "https://gitlab.example.com/group/project", RepoType: "gitlab", Auth: AuthConfig{Token: "gl-token"}},
+			wantURL:  "https://gitlab.example.com/api/v4/projects/group%2Fproject/repository/commits/main",
+			wantAuth: "Bearer gl-token",
+			body: `{"id":"abc123","title":"Add feature","author_name":"Alice",
+				"created_at":"2024-05-01T12:30:00Z","web_url":"https://example.com/c/abc123"}`,
+		},
+		{
+			name:     "gitea",
+			monitor:  MonitorConfig{RepoURL: "https://gitea.example.com/owner/repo", RepoType: "gitea", Auth: AuthConfig{Token: "gt-token"}},
+			wantURL:  "https://gitea.example.com/api/v1/repos/owner/repo/commits/main",
+			wantAuth: "token gt-token",
+			body: `{"sha":"abc123","html_url":"https://example.com/c/abc123",
+				"commit":{"message":"Add feature","author":{"name":"Alice","date":"2024-05-01T12:30:00Z"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotURL, gotAuth string
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				gotURL = req.URL.String()
+				gotAuth = req.Header.Get("Authorization")
+				return stubResponse(http.StatusOK, tt.body), nil
+			})})
+
+			commit, err := monitor.GetLatestCommit(&tt.monitor, "main")
+			if err != nil {
+				t.Fatalf("GetLatestCommit() error = %v", err)
+			}
+
+			if gotURL != tt.wantURL {
+				t.Errorf("requested %s, want %s", gotURL, tt.wantURL)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+			want := CommitInfo{SHA: "abc123", Message: "Add feature", Author: "Alice", Timestamp: timestamp, URL: "https://example.com/c/abc123"}
+			if commit.SHA != want.SHA || commit.Message != want.Message || commit.Author != want.Author ||
+				!commit.Timestamp.Equal(want.Timestamp) || commit.URL != want.URL {
+				t.Errorf("GetLatestCommit() = %+v, want %+v", *commit, want)
+			}
+		})
+	}
+}
+
+func TestCheckRepositoryBranchConditionalRequests(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	repo := &RepositoryConfig{
+		Name: "etag-repo",
+		Monitor: MonitorConfig{
+			RepoURL:  "https://github.com/owner/repo",
+			RepoType: "github",
+			Branches: []string{"main"},
+		},
+	}
+	config := &Config{Repositories: []RepositoryConfig{*repo}}
+
+	var ifNoneMatch []string
+	monitor := NewMonitorService(config, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		ifNoneMatch = append(ifNoneMatch, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return stubResponse(http.StatusNotModified, ""), nil
+		}
+		resp := stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"Add feature","author":{"name":"Alice"}}}`)
+		resp.Header.Set("ETag", `"v1"`)
+		return resp, nil
+	})})
+
+	// The first poll records the baseline and its ETag
+	changed, err := monitor.checkRepositoryBranch(repo, "main")
+	if err != nil || changed {
+		t.Fatalf("first checkRepositoryBranch() = %v, %v, want baseline", changed, err)
+	}
+
+	// The second poll is answered with 304 and reports no change
+	changed, err = monitor.checkRepositoryBranch(repo, "main")
+	if err != nil || changed {
+		t.Fatalf("second checkRepositoryBranch() = %v, %v, want unchanged", changed, err)
+	}
+	if got := monitor.lastCommit[refCacheKey("etag-repo", "main")]; got != "abc123" {
+		t.Errorf("lastCommit = %q, want abc123", got)
+	}
+
+	// One-off lookups always fetch the full commit
+	commit, err := monitor.GetLatestCommit(&repo.Monitor, "main")
+	if err != nil || commit.SHA != "abc123" {
+		t.Fatalf("GetLatestCommit() = %+v, %v, want full commit", commit, err)
+	}
+
+	if want := []string{"", `"v1"`, ""}; strings.Join(ifNoneMatch, ",") != strings.Join(want, ",") {
+		t.Errorf("If-None-Match headers = %q, want %q", ifNoneMatch, want)
+	}
+}
+
+func TestCheckAllRepositoriesRecordsTriggerOnResult(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	payloads := make(chan DeployWebhookPayload, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload DeployWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("webhook body is not JSON: %v", err)
+		}
+		payloads <- payload
+	}))
+	defer hook.Close()
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+	config.Repositories[0].Monitor = MonitorConfig{
+		RepoURL:  "https://github.com/owner/app",
+		Branches: []string{"main"},
+		RepoType: "github",
+	}
+	config.Repositories[0].WebhookURL = hook.URL
+
+	deployService := NewDeployService(config)
+	monitor := NewMonitorService(config, deployService)
+	monitor.lastCommit[refCacheKey("drain-repo", "main")] = "0000000000000000"
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return stubResponse(http.StatusOK, `{"sha":"0123456789abcdef","html_url":"https://github.com/owner/app/commit/0123456789abcdef",
+			"commit":{"message":"Bump image","author":{"name":"Alice","date":"2024-05-01T12:30:00Z"}}}`), nil
+	})})
+
+	if err := monitor.CheckAllRepositories(context.Background()); err != nil {
+		t.Fatalf("CheckAllRepositories() error = %v", err)
+	}
+
+	history := deployService.history.recent()
+	if len(history) != 1 || history[0].Repository == nil {
+		t.Fatalf("history = %+v, want one repository deployment", history)
+	}
+	trigger := history[0].Repository.Trigger
+	if trigger == nil {
+		t.Fatal("DeployResult.Trigger = nil, want the detected commit")
+	}
+	want := DeployTrigger{Branch: "main", CommitInfo: CommitInfo{
+		SHA:       "0123456789abcdef",
+		Message:   "Bump image",
+		Author:    "Alice",
+		Timestamp: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		URL:       "https://github.com/owner/app/commit/0123456789abcdef",
+	}}
+	if *trigger != want {
+		t.Errorf("DeployResult.Trigger = %+v, want %+v", *trigger, want)
+	}
+
+	payload := <-payloads
+	if payload.Trigger == nil || payload.Trigger.SHA != want.SHA || payload.Trigger.Branch != "main" {
+		t.Errorf("webhook trigger = %+v, want the detected commit", payload.Trigger)
+	}
+}
+
+func TestCheckAllRepositoriesTriggersGroupsInStableOrder(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	logPath := filepath.Join(t.TempDir(), "order.log")
+	config := newDrainTestConfig(t, nil)
+	template := config.Repositories[0]
+	config.Repositories = nil
+	config.Groups = make(map[string]GroupConfig)
+	// Declared out of alphabetical order so neither map nor declaration order passes by accident
+	for _, groupName := range []string{"delta", "alpha", "charlie", "bravo"} {
+		repo := template
+		repo.Name = groupName + "-repo"
+		repo.Group = groupName
+		repo.Monitor = MonitorConfig{
+			RepoURL:  "https://github.com/owner/" + groupName,
+			Branches: []string{"main"},
+			RepoType: "github",
+		}
+		repo.Deploy.Commands = []CommandSpec{{Run: fmt.Sprintf("echo %s >> %s", groupName, logPath)}}
+		config.Repositories = append(config.Repositories, repo)
+		config.Groups[groupName] = GroupConfig{ExecutionStrategy: "sequential", MaxParallel: 1, GlobalTimeout: 300}
+	}
+
+	deployService := NewDeployService(config)
+	monitor := NewMonitorService(config, deployService)
+	monitor.retry = RetryConfig{}
+
+	want := []string{"alpha", "bravo", "charlie", "delta"}
+	for run := 1; run <= 5; run++ {
+		sha := fmt.Sprintf("%016d", run)
+		monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+			return stubResponse(http.StatusOK, fmt.Sprintf(`{"sha":"%s","commit":{"message":"Bump","author":{"name":"Alice"}}}`, sha)), nil
+		})})
+		for _, repo := range config.Repositories {
+			monitor.lastCommit[refCacheKey(repo.Name, "main")] = "previous"
+		}
+		os.Remove(logPath)
+
+		if err := monitor.CheckAllRepositories(context.Background()); err != nil {
+			t.Fatalf("run %d: CheckAllRepositories() error = %v", run, err)
+		}
+
+		data, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("run %d: failed to read deploy order: %v", run, err)
+		}
+		if got := strings.Fields(string(data)); !slices.Equal(got, want) {
+			t.Fatalf("run %d: group deploy order = %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestCheckAllRepositoriesSkipsDisabled(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app, markers := newTriggerTestApp(t, "", "")
+	disabled := false
+	for i := range app.config.Repositories {
+		repo := &app.config.Repositories[i]
+		repo.Monitor = MonitorConfig{
+			RepoURL:  "https://github.com/owner/" + repo.Name,
+			Branches: []string{"main"},
+			RepoType: "github",
+		}
+		if repo.Name != "web-a" {
+			repo.Enabled = &disabled
+		}
+		app.monitorService.lastCommit[refCacheKey(repo.Name, "main")] = "previous"
+	}
+
+	var requested []string
+	app.monitorService.retry = RetryConfig{}
+	app.monitorService.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"Bump","author":{"name":"Alice"}}}`), nil
+	})})
+
+	if err := app.monitorService.CheckAllRepositories(context.Background()); err != nil {
+		t.Fatalf("CheckAllRepositories() error = %v", err)
+	}
+
+	for _, path := range requested {
+		if !strings.Contains(path, "/web-a/") {
+			t.Errorf("checked %s, want disabled repositories skipped", path)
+		}
+	}
+	if len(requested) == 0 {
+		t.Error("enabled repository web-a was not checked")
+	}
+
+	// The change to web-a deploys its group without the disabled web-b
+	entries, _ := os.ReadDir(markers)
+	var deployed []string
+	for _, entry := range entries {
+		deployed = append(deployed, entry.Name())
+	}
+	if !slices.Equal(deployed, []string{"web-a"}) {
+		t.Errorf("deployed %v, want only web-a", deployed)
+	}
+}
+
+func TestCheckAllRepositoriesDeploysChangeDespiteFailingBranch(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app, markers := newTriggerTestApp(t, "", "")
+	disabled := false
+	for i := range app.config.Repositories {
+		repo := &app.config.Repositories[i]
+		repo.Monitor = MonitorConfig{
+			RepoURL:  "https://github.com/owner/" + repo.Name,
+			Branches: []string{"main", "dev"},
+			RepoType: "github",
+		}
+		if repo.Name != "solo" {
+			repo.Enabled = &disabled
+		}
+	}
+	monitor := app.monitorService
+	monitor.lastCommit[refCacheKey("solo", "main")] = "previous"
+	monitor.lastCommit[refCacheKey("solo", "dev")] = "previous"
+
+	monitor.retry = RetryConfig{}
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/commits/dev") {
+			return stubResponse(http.StatusInternalServerError, `{"message":"Server Error"}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"Bump","author":{"name":"Alice"}}}`), nil
+	})})
+
+	err := monitor.CheckAllRepositories(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "branch dev") {
+		t.Fatalf("CheckAllRepositories() error = %v, want the dev branch failure", err)
+	}
+
+	// main changed before dev failed, so its recorded commit must still deploy
+	if _, statErr := os.Stat(filepath.Join(markers, "solo")); statErr != nil {
+		t.Errorf("solo was not deployed for the main change: %v", statErr)
+	}
+	if got := monitor.lastCommit[refCacheKey("solo", "dev")]; got != "previous" {
+		t.Errorf("dev last commit = %q, want it unchanged after the failed check", got)
+	}
+}
+
+func TestCheckRepositoryBranchEmptyRepository(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		monitor   MonitorConfig
+		responses map[string]*http.Response // URL path -> response
+		wantEmpty bool
+	}{
+		{
+			name:    "github empty repository",
+			monitor: MonitorConfig{RepoURL: "https://github.com/owner/new", RepoType: "github"},
+			responses: map[string]*http.Response{
+				"/repos/owner/new/commits/main": stubResponse(http.StatusConflict, `{"message":"Git Repository is empty.","documentation_url":"https://docs.github.com/rest"}`),
+			},
+			wantEmpty: true,
+		},
+		{
+			name:    "gitea empty repository",
+			monitor: MonitorConfig{RepoURL: "https://gitea.example.com/owner/new", RepoType: "gitea"},
+			responses: map[string]*http.Response{
+				"/api/v1/repos/owner/new/commits/main": stubResponse(http.StatusConflict, `{"message":"Git Repository is empty.","url":"https://gitea.example.com/api/swagger"}`),
+			},
+			wantEmpty: true,
+		},
+		{
+			name:    "gitlab empty project",
+			monitor: MonitorConfig{RepoURL: "https://gitlab.example.com/group/new", RepoType: "gitlab"},
+			responses: map[string]*http.Response{
+				"/api/v4/projects/group/new/repository/commits/main": stubResponse(http.StatusNotFound, `{"message":"404 Commit Not Found"}`),
+				"/api/v4/projects/group/new":                         stubResponse(http.StatusOK, `{"id":42,"path_with_namespace":"group/new","empty_repo":true}`),
+			},
+			wantEmpty: true,
+		},
+		{
+			name:    "gitlab missing branch in a project with commits",
+			monitor: MonitorConfig{RepoURL: "https://gitlab.example.com/group/app", RepoType: "gitlab"},
+			responses: map[string]*http.Response{
+				"/api/v4/projects/group/app/repository/commits/main": stubResponse(http.StatusNotFound, `{"message":"404 Commit Not Found"}`),
+				"/api/v4/projects/group/app":                         stubResponse(http.StatusOK, `{"id":43,"path_with_namespace":"group/app","empty_repo":false}`),
+			},
+		},
+		{
+			name:    "other conflict",
+			monitor: MonitorConfig{RepoURL: "https://github.com/owner/app", RepoType: "github"},
+			responses: map[string]*http.Response{
+				"/repos/owner/app/commits/main": stubResponse(http.StatusConflict, `{"message":"Repository access blocked"}`),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.retry = RetryConfig{}
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				if resp, ok := tt.responses[req.URL.EscapedPath()]; ok {
+					return resp, nil
+				}
+				if resp, ok := tt.responses[req.URL.Path]; ok {
+					return resp, nil
+				}
+				t.Errorf("unexpected request %s", req.URL)
+				return stubResponse(http.StatusInternalServerError, "{}"), nil
+			})})
+
+			repo := &RepositoryConfig{Name: "new-repo", Monitor: tt.monitor}
+			repo.Monitor.Branches = []string{"main"}
+			changed, err := monitor.checkRepositoryBranch(repo, "main")
+
+			if tt.wantEmpty {
+				if err != nil || changed {
+					t.Errorf("checkRepositoryBranch() = %v, %v, want no change and no error", changed, err)
+				}
+				if _, recorded := monitor.lastCommit[refCacheKey("new-repo", "main")]; recorded {
+					t.Error("an empty repository recorded a baseline commit")
+				}
+				return
+			}
+			if err == nil || errors.Is(err, ErrEmptyRepository) {
+				t.Errorf("checkRepositoryBranch() error = %v, want a failure other than an empty repository", err)
+			}
+		})
+	}
+}
+
+func TestCheckAllRepositoriesEmptyRepositoryDoesNotBlockOthers(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+	config.Repositories[0].Monitor = MonitorConfig{
+		RepoURL:  "https://github.com/owner/app",
+		Branches: []string{"main"},
+		RepoType: "github",
+	}
+	empty := config.Repositories[0]
+	empty.Name = "empty-repo"
+	empty.Monitor.RepoURL = "https://github.com/owner/new"
+	config.Repositories = append(config.Repositories, empty)
+
+	deployService := NewDeployService(config)
+	monitor := NewMonitorService(config, deployService)
+	monitor.retry = RetryConfig{}
+	monitor.lastCommit[refCacheKey("drain-repo", "main")] = "0000000000000000"
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/repos/owner/new/") {
+			return stubResponse(http.StatusConflict, `{"message":"Git Repository is empty."}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"0123456789abcdef","commit":{"message":"Bump image","author":{"name":"Alice"}}}`), nil
+	})})
+
+	if err := monitor.CheckAllRepositories(context.Background()); err != nil {
+		t.Fatalf("CheckAllRepositories() error = %v, want the empty repository skipped", err)
+	}
+	history := deployService.history.recent()
+	if len(history) != 1 || history[0].Repository == nil || history[0].Repository.RepoName != "drain-repo" {
+		t.Errorf("history = %+v, want one drain-repo deployment", history)
+	}
+}
+
+func TestCheckRepositoryBranchSameURLTrackedIndependently(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// Two projects whose Tekton definitions live in different folders of one repository
+	newEntry := func(name string, path string) *RepositoryConfig {
+		return &RepositoryConfig{
+			Name: name,
+			Monitor: MonitorConfig{
+				RepoURL:  "https://github.com/owner/pipelines",
+				RepoType: "github",
+				Branches: []string{"main"},
+				Paths:    []string{path},
+			},
+		}
+	}
+	projectA := newEntry("project-a", "tekton/a/**")
+	projectB := newEntry("project-b", "tekton/b/**")
+	config := &Config{Repositories: []RepositoryConfig{*projectA, *projectB}}
+
+	head := "sha-1"
+	changedFiles := map[string]string{"sha-2": "tekton/a/pipeline.yaml", "sha-3": "tekton/b/pipeline.yaml"}
+
+	monitor := NewMonitorService(config, nil)
+	monitor.retry = RetryConfig{}
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		// Changed files are compared from the last seen commit, e.g. /compare/sha-1...sha-2
+		if _, headSHA, ok := strings.Cut(path.Base(req.URL.Path), "..."); ok && changedFiles[headSHA] != "" {
+			file := changedFiles[headSHA]
+			return stubResponse(http.StatusOK, fmt.Sprintf(`{"files":[{"filename":%q}]}`, file)), nil
+		}
+		// The branch head answers like GitHub: 304 while the ETag still matches
+		etag := fmt.Sprintf("%q", head)
+		if req.Header.Get("If-None-Match") == etag {
+			return stubResponse(http.StatusNotModified, ""), nil
+		}
+		resp := stubResponse(http.StatusOK, fmt.Sprintf(`{"sha":%q,"commit":{"message":"Update","author":{"name":"Alice"}}}`, head))
+		resp.Header.Set("ETag", etag)
+		return resp, nil
+	})})
+
+	poll := func() (bool, bool) {
+		t.Helper()
+		changedA, err := monitor.checkRepositoryBranch(projectA, "main")
+		if err != nil {
+			t.Fatalf("checkRepositoryBranch(project-a) error = %v", err)
+		}
+		changedB, err := monitor.checkRepositoryBranch(projectB, "main")
+		if err != nil {
+			t.Fatalf("checkRepositoryBranch(project-b) error = %v", err)
+		}
+		return changedA, changedB
+	}
+
+	tests := []struct {
+		head                 string
+		wantA, wantB         bool
+		wantLastA, wantLastB string
+	}{
+		{head: "sha-1", wantLastA: "sha-1", wantLastB: "sha-1"},              // Both baseline
+		{head: "sha-2", wantA: true, wantLastA: "sha-2", wantLastB: "sha-2"}, // Only project A's folder changed
+		{head: "sha-3", wantB: true, wantLastA: "sha-3", wantLastB: "sha-3"}, // Only project B's folder changed
+		{head: "sha-3", wantLastA: "sha-3", wantLastB: "sha-3"},              // Unchanged for both
+	}
+	for i, tt := range tests {
+		head = tt.head
+		changedA, changedB := poll()
+		if changedA != tt.wantA || changedB != tt.wantB {
+			t.Errorf("poll %d at %s: changed = (%v, %v), want (%v, %v)", i+1, tt.head, changedA, changedB, tt.wantA, tt.wantB)
+		}
+		if got := monitor.lastCommit[refCacheKey("project-a", "main")]; got != tt.wantLastA {
+			t.Errorf("poll %d: project-a last commit = %q, want %q", i+1, got, tt.wantLastA)
+		}
+		if got := monitor.lastCommit[refCacheKey("project-b", "main")]; got != tt.wantLastB {
+			t.Errorf("poll %d: project-b last commit = %q, want %q", i+1, got, tt.wantLastB)
+		}
+	}
+}
+
+func TestMatchesBranch(t *testing.T) {
+	tests := []struct {
+		configured string
+		branch     string
+		want       bool
+	}{
+		{"main", "main", true},
+		{"main", "main2", false},
+		{"release-1.0", "release-1.0", true},
+		{"release-1.0", "release-1x0", false}, // A dot in a plain name is not a wildcard
+		{"v1.2", "v1-2", false},
+		{"dev.*", "dev-login", true},
+		{"release/[0-9]+", "release/12", true},
+		{"release/[0-9]+", "release/12-rc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.configured+"/"+tt.branch, func(t *testing.T) {
+			if got := matchesBranch(tt.configured, tt.branch); got != tt.want {
+				t.Errorf("matchesBranch(%q, %q) = %v, want %v", tt.configured, tt.branch, got, tt.want)
+			}
+		})
+	}
+}
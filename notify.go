@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Notification target types
+const (
+	notificationTypeSlack          = "slack"
+	notificationTypeGenericWebhook = "generic_webhook"
+)
+
+// slackMessage is the payload accepted by Slack incoming webhooks
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// shouldNotify reports whether a deployment outcome should be announced
+func (d *DeployService) shouldNotify(success bool) bool {
+	notifications := &d.currentConfig().Global.Notifications
+	if notifications.SlackWebhookURL == "" {
+		return false
+	}
+	if success {
+		return notifications.OnSuccess
+	}
+	return notifications.NotifyOnFailure()
+}
+
+// notifyDeployResult posts an individual deployment outcome to Slack
+// Delivery failures are logged and never change the deployment outcome.
+func (d *DeployService) notifyDeployResult(result *DeployResult) {
+	if !d.shouldNotify(result.Success) {
+		return
+	}
+	d.postSlack(formatDeploySlackMessage(result), "repo", result.RepoName)
+}
+
+// notifyGroupResult posts a group deployment outcome to Slack
+func (d *DeployService) notifyGroupResult(result *GroupDeployResult) {
+	if !d.shouldNotify(result.Success) {
+		return
+	}
+	d.postSlack(formatGroupSlackMessage(result), "group", result.GroupName)
+}
+
+// postSlack sends a message to the configured Slack webhook, logging any failure
+func (d *DeployService) postSlack(text string, subjectKey string, subject string) {
+	if err := d.postWebhook(d.currentConfig().Global.Notifications.SlackWebhookURL, slackMessage{Text: text}); err != nil {
+		AppLogger.WarnS("Failed to send Slack notification",
+			subjectKey, subject,
+			"error", err)
+	}
+}
+
+// formatDeploySlackMessage renders an individual deployment result for Slack
+func formatDeploySlackMessage(result *DeployResult) string {
+	name := result.DisplayName
+	if name == "" {
+		name = result.RepoName
+	}
+
+	var message string
+	if result.Success {
+		message = fmt.Sprintf(":white_check_mark: Deployment succeeded: *%s*\nDuration: %s", name, result.Duration)
+	} else {
+		message = fmt.Sprintf(":x: Deployment failed: *%s*\nError: %s\nDuration: %s", name, result.Error, result.Duration)
+	}
+	if result.Trigger != nil {
+		message += "\n" + formatTriggerLine(result.Trigger)
+	}
+	return message
+}
+
+// formatTriggerLine describes the change that triggered a deployment, e.g. "Commit: 0123abcd on main by Alice"
+func formatTriggerLine(trigger *DeployTrigger) string {
+	line := "Commit: " + shortSHA(trigger.SHA)
+	if trigger.Branch != "" {
+		line += " on " + trigger.Branch
+	}
+	if trigger.Author != "" {
+		line += " by " + trigger.Author
+	}
+	return line
+}
+
+// formatGroupSlackMessage renders a group deployment result, listing failed repositories
+func formatGroupSlackMessage(result *GroupDeployResult) string {
+	if result.Success {
+		return fmt.Sprintf(":white_check_mark: Group deployment succeeded: *%s* (%s, %d repositories)\nTotal time: %s",
+			result.GroupName, result.Strategy, len(result.Results), result.TotalTime)
+	}
+
+	repoNames := make([]string, 0, len(result.Results))
+	for repoName := range result.Results {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf(":x: Group deployment failed: *%s* (%s)", result.GroupName, result.Strategy))
+	for _, repoName := range repoNames {
+		repoResult := result.Results[repoName]
+		if repoResult.Success {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("• %s: %s (%s)", repoName, repoResult.Error, repoResult.Duration))
+	}
+	lines = append(lines, fmt.Sprintf("Total time: %s", result.TotalTime))
+
+	return strings.Join(lines, "\n")
+}
+
+// parseNotificationTemplate parses a notification target template; unknown fields are errors
+func parseNotificationTemplate(content string) (*template.Template, error) {
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderNotificationTemplate renders a target template with the deploy result as data
+func renderNotificationTemplate(content string, result *DeployResult) (string, error) {
+	tmpl, err := parseNotificationTemplate(content)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, result); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// matches reports whether the target wants a notification for this result
+func (t *NotificationTarget) matches(result *DeployResult) bool {
+	if result.Success && !t.OnSuccess {
+		return false
+	}
+	if !result.Success && t.OnFailure != nil && !*t.OnFailure {
+		return false
+	}
+	return t.inScope(result.RepoName, result.GroupName)
+}
+
+// inScope reports whether a repository passes the target's repositories and groups filters
+func (t *NotificationTarget) inScope(repoName string, groupName string) bool {
+	if len(t.Repositories) > 0 && !slices.Contains(t.Repositories, repoName) {
+		return false
+	}
+	if len(t.Groups) > 0 && !slices.Contains(t.Groups, groupName) {
+		return false
+	}
+	return true
+}
+
+// notificationBody builds the request body and content type a target receives for a result
+// Slack targets get the template as message text; generic webhooks get it verbatim, or the
+// webhook_url JSON payload when no template is set.
+func (t *NotificationTarget) notificationBody(result *DeployResult) ([]byte, string, error) {
+	var text string
+	if t.Template != "" {
+		rendered, err := renderNotificationTemplate(t.Template, result)
+		if err != nil {
+			return nil, "", err
+		}
+		text = rendered
+	}
+
+	if t.Type == notificationTypeSlack {
+		if t.Template == "" {
+			text = formatDeploySlackMessage(result)
+		}
+		body, err := json.Marshal(slackMessage{Text: text})
+		return body, "application/json", err
+	}
+
+	if t.Template == "" {
+		body, err := json.Marshal(newDeployWebhookPayload(result))
+		return body, "application/json", err
+	}
+	if json.Valid([]byte(text)) {
+		return []byte(text), "application/json", nil
+	}
+	return []byte(text), "text/plain; charset=utf-8", nil
+}
+
+// notifyTargets delivers a finalized repository deploy result to every matching notification target
+// Delivery and template failures are logged and never change the deployment outcome.
+func (d *DeployService) notifyTargets(result *DeployResult) {
+	targets := d.currentConfig().Global.Notifications.Targets
+	for i := range targets {
+		target := &targets[i]
+		if !target.matches(result) {
+			continue
+		}
+
+		body, contentType, err := target.notificationBody(result)
+		if err == nil {
+			err = d.postBody(target.URL, contentType, body)
+		}
+		if err != nil {
+			AppLogger.WarnS("Failed to send deployment notification",
+				"repo", result.RepoName,
+				"target", target.Type,
+				"error", err)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSlackTestServer returns a server that forwards each received Slack message text
+func newSlackTestServer(t *testing.T, status int) (*httptest.Server, chan string) {
+	t.Helper()
+
+	received := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var message slackMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Errorf("slack body is not JSON: %v", err)
+		}
+		received <- message.Text
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// newSlackTestConfig returns a config whose single repository fails to clone
+func newSlackTestConfig(t *testing.T, notifications NotificationsConfig) *Config {
+	return &Config{
+		Global: GlobalConfig{
+			TmpDir:        t.TempDir(),
+			Cleanup:       true,
+			Notifications: notifications,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "slack-repo",
+				Deploy: DeployConfig{
+					QARepoURL:    filepath.Join(t.TempDir(), "missing"),
+					QARepoBranch: "main",
+					RepoType:     "git",
+					ProjectName:  "slack",
+					Commands:     []CommandSpec{{Run: "true"}},
+				},
+			},
+		},
+		Groups: map[string]GroupConfig{
+			"slack-group": {ExecutionStrategy: "sequential"},
+		},
+	}
+}
+
+func TestSlackNotificationOnFailure(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	server, received := newSlackTestServer(t, http.StatusOK)
+	config := newSlackTestConfig(t, NotificationsConfig{SlackWebhookURL: server.URL})
+	service := NewDeployService(config)
+
+	if err := service.DeployIndividual(context.Background(), &config.Repositories[0], nil); err == nil {
+		t.Fatal("DeployIndividual() expected error for missing QA repo")
+	}
+
+	var text string
+	select {
+	case text = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slack message was not delivered")
+	}
+
+	for _, want := range []string{"Deployment failed", "slack-repo", "Error: failed to clone QA repository", "Duration: "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("slack text = %q, want it to contain %q", text, want)
+		}
+	}
+
+	groupConfig := config.Groups["slack-group"]
+	if err := service.DeployGroup(context.Background(), "slack-group", []string{"slack-repo"}, &groupConfig, nil); err == nil {
+		t.Fatal("DeployGroup() expected error for missing QA repo")
+	}
+
+	select {
+	case text = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("group slack message was not delivered")
+	}
+
+	for _, want := range []string{"Group deployment failed", "slack-group", "• slack-repo: failed to clone QA repository", "Total time: "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("group slack text = %q, want it to contain %q", text, want)
+		}
+	}
+}
+
+func TestSlackNotificationToggles(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	disabled := false
+	tests := []struct {
+		name          string
+		notifications NotificationsConfig
+		success       bool
+		expected      bool
+	}{
+		{name: "no webhook url", notifications: NotificationsConfig{}, success: false, expected: false},
+		{name: "failure by default", notifications: NotificationsConfig{SlackWebhookURL: "http://slack"}, success: false, expected: true},
+		{name: "success off by default", notifications: NotificationsConfig{SlackWebhookURL: "http://slack"}, success: true, expected: false},
+		{name: "failure disabled", notifications: NotificationsConfig{SlackWebhookURL: "http://slack", OnFailure: &disabled}, success: false, expected: false},
+		{name: "success enabled", notifications: NotificationsConfig{SlackWebhookURL: "http://slack", OnSuccess: true}, success: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewDeployService(newSlackTestConfig(t, tt.notifications))
+			if got := service.shouldNotify(tt.success); got != tt.expected {
+				t.Errorf("shouldNotify(%v) = %v, want %v", tt.success, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSlackNotificationUnreachable(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	server, received := newSlackTestServer(t, http.StatusInternalServerError)
+	config := newSlackTestConfig(t, NotificationsConfig{SlackWebhookURL: server.URL})
+	service := NewDeployService(config)
+
+	// A failing Slack endpoint must not alter the deployment error
+	err := service.DeployIndividual(context.Background(), &config.Repositories[0], nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to clone QA repository") {
+		t.Errorf("DeployIndividual() error = %v, want clone failure", err)
+	}
+	<-received
+}
+
+func TestSlackNotificationFailureHidesWebhookURL(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+	defer InitializeLogger(false)
+
+	// Nothing listens on the URL any more, so the POST itself fails
+	server := httptest.NewServer(http.NotFoundHandler())
+	webhookURL := server.URL + "/services/T0000/B0000/slack-path-secret"
+	server.Close()
+
+	config := newSlackTestConfig(t, NotificationsConfig{SlackWebhookURL: webhookURL})
+	service := NewDeployService(config)
+
+	err := service.postWebhook(webhookURL, slackMessage{Text: "hello"})
+	if err == nil || strings.Contains(err.Error(), "slack-path-secret") || !strings.Contains(err.Error(), server.Listener.Addr().String()) {
+		t.Errorf("postWebhook() error = %v, want the failure naming only the host", err)
+	}
+
+	var logs bytes.Buffer
+	AppLogger.SetOutput(&logs)
+	service.postSlack("hello", "repo", "slack-repo")
+	if !strings.Contains(logs.String(), "Failed to send Slack notification") || strings.Contains(logs.String(), "slack-path-secret") {
+		t.Errorf("logged failure = %q, want it without the webhook path", logs.String())
+	}
+}
+
+func TestNotificationTargetBody(t *testing.T) {
+	success := &DeployResult{RepoName: "app", GroupName: "web", Success: true, Duration: "2s", CommandsRun: []string{"kubectl apply -f ."}}
+	failure := &DeployResult{RepoName: "app", Success: false, Error: "clone failed", Duration: "1s"}
+
+	tests := []struct {
+		name            string
+		target          NotificationTarget
+		result          *DeployResult
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			name:            "generic webhook template on success",
+			target:          NotificationTarget{Type: "generic_webhook", Template: `{"repo":"{{.RepoName}}","group":"{{.GroupName}}","ok":{{.Success}},"steps":{{len .CommandsRun}}}`},
+			result:          success,
+			wantBody:        `{"repo":"app","group":"web","ok":true,"steps":1}`,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "generic webhook plain text on failure",
+			target:          NotificationTarget{Type: "generic_webhook", Template: `{{.RepoName}} failed after {{.Duration}}: {{.Error}}`},
+			result:          failure,
+			wantBody:        `app failed after 1s: clone failed`,
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:            "slack template",
+			target:          NotificationTarget{Type: "slack", Template: `{{if .Success}}:rocket:{{else}}:fire:{{end}} {{.RepoName}}`},
+			result:          failure,
+			wantBody:        `{"text":":fire: app"}`,
+			wantContentType: "application/json",
+		},
+		{
+			name:   "slack default message with trigger",
+			target: NotificationTarget{Type: "slack"},
+			result: &DeployResult{RepoName: "app", Error: "boom", Duration: "1s", Trigger: &DeployTrigger{
+				Branch: "main", CommitInfo: CommitInfo{SHA: "0123456789abcdef", Author: "Alice"},
+			}},
+			wantBody:        `{"text":":x: Deployment failed: *app*\nError: boom\nDuration: 1s\nCommit: 01234567 on main by Alice"}`,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "slack default message",
+			target:          NotificationTarget{Type: "slack"},
+			result:          success,
+			wantBody:        `{"text":":white_check_mark: Deployment succeeded: *app*\nDuration: 2s"}`,
+			wantContentType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType, err := tt.target.notificationBody(tt.result)
+			if err != nil {
+				t.Fatalf("notificationBody() error = %v", err)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+			if contentType != tt.wantContentType {
+				t.Errorf("content type = %q, want %q", contentType, tt.wantContentType)
+			}
+		})
+	}
+
+	if _, _, err := (&NotificationTarget{Type: "slack", Template: "{{.Missing}}"}).notificationBody(success); err == nil {
+		t.Error("notificationBody() expected error for unknown template field")
+	}
+}
+
+func TestNotificationTargetMatches(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name   string
+		target NotificationTarget
+		result DeployResult
+		want   bool
+	}{
+		{"failure by default", NotificationTarget{}, DeployResult{RepoName: "app"}, true},
+		{"success off by default", NotificationTarget{}, DeployResult{RepoName: "app", Success: true}, false},
+		{"success enabled", NotificationTarget{OnSuccess: true}, DeployResult{RepoName: "app", Success: true}, true},
+		{"failure disabled", NotificationTarget{OnFailure: &disabled}, DeployResult{RepoName: "app"}, false},
+		{"repository in scope", NotificationTarget{Repositories: []string{"app"}}, DeployResult{RepoName: "app"}, true},
+		{"repository out of scope", NotificationTarget{Repositories: []string{"other"}}, DeployResult{RepoName: "app"}, false},
+		{"group in scope", NotificationTarget{Groups: []string{"web"}}, DeployResult{RepoName: "app", GroupName: "web"}, true},
+		{"ungrouped out of group scope", NotificationTarget{Groups: []string{"web"}}, DeployResult{RepoName: "app"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.matches(&tt.result); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationTargetsDelivery(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	type delivery struct {
+		path        string
+		contentType string
+		body        string
+	}
+	received := make(chan delivery, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- delivery{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: string(body)}
+	}))
+	t.Cleanup(server.Close)
+
+	config := newSlackTestConfig(t, NotificationsConfig{Targets: []NotificationTarget{
+		{Type: "generic_webhook", URL: server.URL + "/ops", Template: "{{.RepoName}}: {{.Error}}"},
+		{Type: "slack", URL: server.URL + "/other-team", Repositories: []string{"other-repo"}},
+		{Type: "generic_webhook", URL: server.URL + "/success-only", OnSuccess: true, OnFailure: new(bool)},
+	}})
+	service := NewDeployService(config)
+
+	if err := service.DeployIndividual(context.Background(), &config.Repositories[0], nil); err == nil {
+		t.Fatal("DeployIndividual() expected error for missing QA repo")
+	}
+
+	// Delivery is synchronous, so every matching target has been posted to
+	close(received)
+	var deliveries []delivery
+	for d := range received {
+		deliveries = append(deliveries, d)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want only the /ops target", deliveries)
+	}
+	if deliveries[0].path != "/ops" || deliveries[0].contentType != "text/plain; charset=utf-8" ||
+		!strings.HasPrefix(deliveries[0].body, "slack-repo: failed to clone QA repository") {
+		t.Errorf("delivery = %+v, want rendered failure on /ops", deliveries[0])
+	}
+}
+
+func TestValidateNotificationTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    NotificationTarget
+		wantPaths []string
+	}{
+		{"valid slack", NotificationTarget{Type: "slack", URL: "https://hooks.slack.com/x"}, nil},
+		{"valid template", NotificationTarget{Type: "generic_webhook", URL: "https://ops", Template: "{{.RepoName}}"}, nil},
+		{"unknown type", NotificationTarget{Type: "email", URL: "https://ops"}, []string{"targets[0].type"}},
+		{"missing url", NotificationTarget{Type: "slack"}, []string{"targets[0].url"}},
+		{"broken template", NotificationTarget{Type: "slack", URL: "https://ops", Template: "{{.RepoName"}, []string{"targets[0].template"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPaths []string
+			for _, err := range validateNotificationTarget(&tt.target, "targets[0]") {
+				gotPaths = append(gotPaths, err.Path)
+			}
+			if strings.Join(gotPaths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validateNotificationTarget() paths = %v, want %v", gotPaths, tt.wantPaths)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// authTypeOAuth authenticates with access tokens obtained from an OAuth refresh token
+const authTypeOAuth = "oauth"
+
+// oauthTokenRefreshMargin renews a cached access token this long before it expires
+const oauthTokenRefreshMargin = 5 * time.Minute
+
+// oauthDefaultTokenLifetime is assumed for access tokens whose response has no expires_in
+const oauthDefaultTokenLifetime = time.Hour
+
+// oauthTokens is shared by monitoring and deployment so a rotated refresh token is used by both
+var oauthTokens = newOAuthTokenSource()
+
+// oauthToken is a cached access token and the refresh token to renew it with
+type oauthToken struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// oauthTokenResponse is the token endpoint's answer to a refresh_token grant
+type oauthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// oauthTokenSource refreshes and caches OAuth access tokens
+type oauthTokenSource struct {
+	mu     sync.Mutex
+	tokens map[string]*oauthToken // "<token url>|<client id>|<configured refresh token>" -> token
+	now    func() time.Time       // Replaceable in tests
+}
+
+// newOAuthTokenSource creates an empty token cache
+func newOAuthTokenSource() *oauthTokenSource {
+	return &oauthTokenSource{
+		tokens: make(map[string]*oauthToken),
+		now:    time.Now,
+	}
+}
+
+// Token returns a valid access token for auth, refreshing it when none is cached or the cached one is about to expire
+// A failed refresh is logged and falls back to a cached token that has not yet expired; otherwise the error is
+// returned so the caller fails this cycle and the refresh is retried on the next one.
+func (s *oauthTokenSource) Token(ctx context.Context, client *http.Client, auth *AuthConfig) (string, error) {
+	key := fmt.Sprintf("%s|%s|%s", auth.TokenURL, auth.ClientID, auth.RefreshToken)
+
+	// Held while refreshing so concurrent checks spend a rotating refresh token only once
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached, ok := s.tokens[key]
+	if !ok {
+		cached = &oauthToken{AccessToken: auth.Token, RefreshToken: auth.RefreshToken}
+		s.tokens[key] = cached
+	}
+	now := s.now()
+	if cached.AccessToken != "" && now.Add(oauthTokenRefreshMargin).Before(cached.ExpiresAt) {
+		return cached.AccessToken, nil
+	}
+
+	refreshed, err := s.refresh(ctx, client, auth, cached.RefreshToken)
+	if err != nil {
+		if cached.AccessToken != "" && now.Before(cached.ExpiresAt) {
+			AppLogger.WarnS("OAuth token refresh failed; using the current access token until it expires",
+				"token_url", auth.TokenURL,
+				"expires_at", cached.ExpiresAt,
+				"error", err)
+			return cached.AccessToken, nil
+		}
+		return "", fmt.Errorf("failed to refresh OAuth access token: %w", err)
+	}
+
+	cached.AccessToken = refreshed.AccessToken
+	if refreshed.RefreshToken != "" {
+		// Providers that rotate refresh tokens invalidate the one just spent
+		cached.RefreshToken = refreshed.RefreshToken
+	}
+	lifetime := oauthDefaultTokenLifetime
+	if refreshed.ExpiresIn > 0 {
+		lifetime = time.Duration(refreshed.ExpiresIn) * time.Second
+	}
+	cached.ExpiresAt = now.Add(lifetime)
+
+	// Keep the new tokens out of log output like configured ones
+	logSecrets.Register(cached.AccessToken, cached.RefreshToken)
+
+	AppLogger.DebugS("Refreshed OAuth access token",
+		"token_url", auth.TokenURL,
+		"expires_at", cached.ExpiresAt)
+	return cached.AccessToken, nil
+}
+
+// refresh exchanges refreshToken for a new access token with a refresh_token grant
+func (s *oauthTokenSource) refresh(ctx context.Context, client *http.Client, auth *AuthConfig, refreshToken string) (*oauthTokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	if auth.ClientID != "" {
+		form.Set("client_id", auth.ClientID)
+	}
+	if auth.ClientSecret != "" {
+		form.Set("client_secret", auth.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, classifyTransportError(fmt.Errorf("hTTP request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: "oAuth", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("response has no access_token")
+	}
+	return &token, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oauthRefreshResponse builds a token endpoint response for a refresh_token grant
+func oauthRefreshResponse(accessToken string, refreshToken string, expiresIn int) string {
+	return fmt.Sprintf(`{"access_token":%q,"refresh_token":%q,"token_type":"bearer","expires_in":%d}`, accessToken, refreshToken, expiresIn)
+}
+
+func TestOAuthTokenSourceRefresh(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	auth := &AuthConfig{Type: authTypeOAuth, RefreshToken: "rt-1", TokenURL: "https://gitlab.example.com/oauth/token", ClientID: "sentry", ClientSecret: "client-secret"}
+
+	now := time.Date(2025, 9, 17, 12, 0, 0, 0, time.UTC)
+	source := newOAuthTokenSource()
+	source.now = func() time.Time { return now }
+
+	var refreshes int
+	var spent []string
+	client := &http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		refreshes++
+		body, _ := io.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(body))
+		if req.Method != "POST" || req.URL.String() != auth.TokenURL || form.Get("grant_type") != "refresh_token" ||
+			form.Get("client_id") != "sentry" || form.Get("client_secret") != "client-secret" {
+			t.Errorf("refresh request = %s %s %v", req.Method, req.URL, form)
+		}
+		spent = append(spent, form.Get("refresh_token"))
+		return stubResponse(http.StatusOK, oauthRefreshResponse(fmt.Sprintf("at-%d", refreshes), fmt.Sprintf("rt-%d", refreshes+1), 3600)), nil
+	})}
+
+	token := func() string {
+		t.Helper()
+		got, err := source.Token(context.Background(), client, auth)
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		return got
+	}
+
+	if got := token(); got != "at-1" || refreshes != 1 {
+		t.Fatalf("first Token() = %q after %d refreshes, want at-1 after 1", got, refreshes)
+	}
+
+	// Well before expiry the cached token is reused
+	now = now.Add(50 * time.Minute)
+	if got := token(); got != "at-1" || refreshes != 1 {
+		t.Errorf("cached Token() = %q after %d refreshes, want at-1 after 1", got, refreshes)
+	}
+
+	// Within the refresh margin the token is renewed with the rotated refresh token
+	now = now.Add(6 * time.Minute)
+	if got := token(); got != "at-2" || refreshes != 2 {
+		t.Errorf("refreshed Token() = %q after %d refreshes, want at-2 after 2", got, refreshes)
+	}
+	if strings.Join(spent, ",") != "rt-1,rt-2" {
+		t.Errorf("refresh tokens spent = %v, want the configured one then the rotated one", spent)
+	}
+}
+
+func TestOAuthTokenSourceRefreshFailure(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	const invalidGrant = `{"error":"invalid_grant","error_description":"The provided authorization grant is invalid, expired, or revoked"}`
+
+	tests := []struct {
+		name         string
+		accessToken  string        // access token configured alongside the refresh token
+		validFor     time.Duration // how long a first successful refresh's token lasts; 0 skips it
+		wantToken    string
+		wantErr      string
+		wantRefreshs int // refresh attempts after two Token calls
+	}{
+		{
+			name:         "expired refresh token",
+			wantErr:      "invalid_grant",
+			wantRefreshs: 2,
+		},
+		{
+			name:         "current access token still valid",
+			validFor:     4 * time.Minute,
+			wantToken:    "at-1",
+			wantRefreshs: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &AuthConfig{Type: authTypeOAuth, RefreshToken: "rt-expired", TokenURL: "https://auth.example.com/token"}
+			now := time.Date(2025, 9, 17, 12, 0, 0, 0, time.UTC)
+			source := newOAuthTokenSource()
+			source.now = func() time.Time { return now }
+
+			var refreshes int
+			client := &http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				refreshes++
+				if refreshes == 1 && tt.validFor > 0 {
+					return stubResponse(http.StatusOK, oauthRefreshResponse("at-1", "", int(tt.validFor.Seconds()))), nil
+				}
+				return stubResponse(http.StatusBadRequest, invalidGrant), nil
+			})}
+
+			if tt.validFor > 0 {
+				// Inside the refresh margin from the start, so every call tries to refresh
+				if _, err := source.Token(context.Background(), client, auth); err != nil {
+					t.Fatalf("initial Token() error = %v", err)
+				}
+			}
+
+			// Failures are returned, never cached, so the next cycle retries the refresh
+			var got string
+			var err error
+			for i := 0; i < 2; i++ {
+				got, err = source.Token(context.Background(), client, auth)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Token() = %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+			} else if err != nil || got != tt.wantToken {
+				t.Errorf("Token() = %q, %v, want %q", got, err, tt.wantToken)
+			}
+			if refreshes != tt.wantRefreshs {
+				t.Errorf("refresh attempts = %d, want %d", refreshes, tt.wantRefreshs)
+			}
+		})
+	}
+}
+
+func TestGetLatestCommitWithOAuthRefresh(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	previous := oauthTokens
+	oauthTokens = newOAuthTokenSource()
+	t.Cleanup(func() { oauthTokens = previous })
+
+	var refreshes int
+	monitor := NewMonitorService(&Config{}, nil)
+	monitor.retry = RetryConfig{}
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/oauth/token" {
+			refreshes++
+			return stubResponse(http.StatusOK, oauthRefreshResponse("gl-refreshed", "rt-2", 7200)), nil
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer gl-refreshed" {
+			t.Errorf("Authorization = %q, want the refreshed access token", got)
+		}
+		return stubResponse(http.StatusOK, `{"id":"abc123","title":"Bump","author_name":"Alice","created_at":"2024-05-01T12:30:00Z"}`), nil
+	})})
+
+	config := &MonitorConfig{
+		RepoURL:  "https://gitlab.example.com/group/project",
+		RepoType: "gitlab",
+		Auth:     AuthConfig{Type: authTypeOAuth, Token: "gl-stale", RefreshToken: "rt-1", TokenURL: "https://gitlab.example.com/oauth/token"},
+	}
+	for i := 0; i < 2; i++ {
+		commit, err := monitor.GetLatestCommit(config, "main")
+		if err != nil || commit.SHA != "abc123" {
+			t.Fatalf("GetLatestCommit() = %v, %v, want abc123", commit, err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("token refreshes = %d, want 1 across both polls", refreshes)
+	}
+}
+
+func TestValidateAuthConfigOAuth(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		auth      AuthConfig
+		wantPaths []string
+	}{
+		{"valid", AuthConfig{Type: authTypeOAuth, RefreshToken: "rt", TokenURL: "https://gitlab.com/oauth/token"}, nil},
+		{"missing refresh token", AuthConfig{Type: authTypeOAuth, TokenURL: "https://gitlab.com/oauth/token"}, []string{"auth.refresh_token"}},
+		{"missing token url", AuthConfig{Type: authTypeOAuth, RefreshToken: "rt"}, []string{"auth.token_url"}},
+		{"token url without scheme", AuthConfig{Type: authTypeOAuth, RefreshToken: "rt", TokenURL: "gitlab.com/oauth/token"}, []string{"auth.token_url"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			for _, err := range validateAuthConfig(&tt.auth, "auth", "gitlab") {
+				paths = append(paths, err.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validation paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// getCommitStatePath returns the configured commit state file or default
+func getCommitStatePath(config *Config) string {
+	if config.Global.CommitStateFile != "" {
+		return config.Global.CommitStateFile
+	}
+	tmpDir := config.Global.TmpDir
+	if tmpDir == "" {
+		tmpDir = "/tmp/sentry"
+	}
+	return filepath.Join(tmpDir, "sentry-commits.json")
+}
+
+// watchOnce runs a single check cycle, deploying only repositories that changed since the last run
+// The last seen commits are persisted between runs, so a cron job behaves like one long-running watcher.
+func (app *SentryApp) watchOnce() error {
+	AppLogger.Info("Running a single repository check cycle...")
+
+	app.cleanupStaleTempDirectories()
+
+	statePath := getCommitStatePath(app.config)
+	if err := app.monitorService.LoadCommitState(statePath); err != nil {
+		return err
+	}
+
+	// Cancelled on SIGINT/SIGTERM so deployments stop starting new commands
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	checkErr := app.monitorService.CheckAllRepositories(ctx)
+
+	// Save even after failures: a commit that failed to deploy is not retried, just like in watch
+	if err := app.monitorService.SaveCommitState(statePath); err != nil {
+		return err
+	}
+	if checkErr != nil {
+		return fmt.Errorf("check cycle failed: %w", checkErr)
+	}
+
+	AppLogger.Info("Check cycle completed")
+	return nil
+}
+
+// LoadCommitState restores the last seen commits and tags from a previous run
+// A missing file is normal on the first run and leaves every repository to be baselined.
+func (m *MonitorService) LoadCommitState(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read commit state: %w", err)
+	}
+
+	state := make(map[string]string)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode commit state %s: %w", path, err)
+	}
+
+	config := m.currentConfig()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, sha := range state {
+		// Repositories removed from the config are forgotten, as on reload
+		if configuresRef(config, key) {
+			m.lastCommit[key] = sha
+		}
+	}
+	return nil
+}
+
+// SaveCommitState writes the last seen commits and tags to disk atomically
+func (m *MonitorService) SaveCommitState(path string) error {
+	m.mu.RLock()
+	data, err := json.MarshalIndent(m.lastCommit, "", "  ")
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create commit state directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write commit state: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write commit state: %w", err)
+	}
+	return nil
+}
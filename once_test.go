@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatchOnce(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name        string
+		savedSHA    string // Empty means no state file from a previous run
+		status      int
+		wantDeploys int
+		wantErr     bool
+		wantSaved   string
+	}{
+		{
+			name:        "changed",
+			savedSHA:    "old-sha",
+			status:      http.StatusOK,
+			wantDeploys: 1,
+			wantSaved:   "new-sha",
+		},
+		{
+			name:      "no change",
+			savedSHA:  "new-sha",
+			status:    http.StatusOK,
+			wantSaved: "new-sha",
+		},
+		{
+			name:      "first run records a baseline",
+			status:    http.StatusOK,
+			wantSaved: "new-sha",
+		},
+		{
+			name:      "provider error",
+			savedSHA:  "old-sha",
+			status:    http.StatusInternalServerError,
+			wantErr:   true,
+			wantSaved: "old-sha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+			config.Repositories[0].Monitor = MonitorConfig{
+				RepoURL:  "https://github.com/owner/app",
+				Branches: []string{"main"},
+				RepoType: "github",
+			}
+			statePath := filepath.Join(t.TempDir(), "commits.json")
+			config.Global.CommitStateFile = statePath
+
+			key := refCacheKey("drain-repo", "main")
+			if tt.savedSHA != "" {
+				data, _ := json.Marshal(map[string]string{key: tt.savedSHA, "removed-repo:main": "gone"})
+				if err := os.WriteFile(statePath, data, 0644); err != nil {
+					t.Fatalf("failed to write commit state: %v", err)
+				}
+			}
+
+			deployService := NewDeployService(config)
+			monitor := NewMonitorService(config, deployService)
+			monitor.retry = RetryConfig{}
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				if tt.status != http.StatusOK {
+					return stubResponse(tt.status, `{"message":"boom"}`), nil
+				}
+				return stubResponse(http.StatusOK, `{"sha":"new-sha","commit":{"message":"Bump image","author":{"name":"Alice"}}}`), nil
+			})})
+			app := &SentryApp{
+				config:         config,
+				monitorService: monitor,
+				deployService:  deployService,
+				appConfig:      &AppConfig{Action: "watch", Once: true},
+			}
+
+			err := app.watchAction()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("watchAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := len(deployService.history.recent()); got != tt.wantDeploys {
+				t.Errorf("deployments = %d, want %d", got, tt.wantDeploys)
+			}
+
+			data, err := os.ReadFile(statePath)
+			if err != nil {
+				t.Fatalf("commit state not saved: %v", err)
+			}
+			var saved map[string]string
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatalf("commit state is not JSON: %v", err)
+			}
+			if saved[key] != tt.wantSaved {
+				t.Errorf("saved commit = %q, want %q", saved[key], tt.wantSaved)
+			}
+			if _, exists := saved["removed-repo:main"]; exists {
+				t.Error("state of an unconfigured repository was kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+)
+
+// defaultMaxOutputBytes caps each captured command stream when deploy.max_output_bytes is unset, like API response bodies
+const defaultMaxOutputBytes = 1024 * 1024 // 1MB
+
+// getMaxOutputBytes gets how many bytes of a command's stdout and of its stderr are kept
+func getMaxOutputBytes(deploy *DeployConfig) int {
+	if deploy.MaxOutputBytes > 0 {
+		return deploy.MaxOutputBytes
+	}
+	return defaultMaxOutputBytes
+}
+
+// tailBuffer is an io.Writer keeping only the last limit bytes written to it
+// The tail is kept because the end of a failing command's output usually holds the error.
+type tailBuffer struct {
+	limit   int
+	buf     []byte
+	dropped int64
+}
+
+// newTailBuffer creates a tailBuffer keeping at most limit bytes
+func newTailBuffer(limit int) *tailBuffer {
+	return &tailBuffer{limit: limit}
+}
+
+// Write implements io.Writer, discarding the oldest bytes beyond the limit
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= b.limit {
+		b.dropped += int64(len(b.buf) + n - b.limit)
+		b.buf = append(b.buf[:0], p[n-b.limit:]...)
+		return n, nil
+	}
+
+	if excess := len(b.buf) + n - b.limit; excess > 0 {
+		b.dropped += int64(excess)
+		b.buf = b.buf[:copy(b.buf, b.buf[excess:])]
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+// String returns the kept output, preceded by a marker when earlier output was dropped
+func (b *tailBuffer) String() string {
+	if b.dropped == 0 {
+		return string(b.buf)
+	}
+	return fmt.Sprintf("[... %d bytes truncated ...]\n%s", b.dropped, b.buf)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name   string
+		limit  int
+		writes []string
+		want   string
+	}{
+		{"under the limit", 10, []string{"abc", "def"}, "abcdef"},
+		{"exactly the limit", 6, []string{"abc", "def"}, "abcdef"},
+		{"drops the oldest bytes", 4, []string{"abc", "def"}, "[... 2 bytes truncated ...]\ncdef"},
+		{"single write over the limit", 3, []string{"abcdefgh"}, "[... 5 bytes truncated ...]\nfgh"},
+		{"large write after small ones", 3, []string{"ab", "cdefg"}, "[... 4 bytes truncated ...]\nefg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := newTailBuffer(tt.limit)
+			for _, w := range tt.writes {
+				if n, err := buf.Write([]byte(w)); n != len(w) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v, want %d, nil", w, n, err, len(w))
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployRepositoryTruncatesCommandOutput(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// Print 64KB of noise, then the error that matters, and fail
+	command := `i=0; while [ $i -lt 1024 ]; do printf '%064d\n' 0; printf '%064d\n' 0 >&2; i=$((i+1)); done; echo "error: webhook denied the request" >&2; exit 1`
+	config := newDrainTestConfig(t, []CommandSpec{{Run: command}})
+	config.Repositories[0].Deploy.MaxOutputBytes = 1024
+	service := NewDeployService(config)
+
+	result := service.deployRepository("drain-repo", context.Background(), nil)
+	if result.Success {
+		t.Fatal("deployRepository() succeeded, want the command to fail")
+	}
+	if len(result.CommandOutputs) != 1 {
+		t.Fatalf("CommandOutputs = %d entries, want 1", len(result.CommandOutputs))
+	}
+
+	output := result.CommandOutputs[0]
+	marker := "[... "
+	for name, stream := range map[string]string{"stdout": output.Stdout, "stderr": output.Stderr} {
+		if !strings.HasPrefix(stream, marker) {
+			t.Errorf("%s = %.40q..., want it to start with the truncation marker", name, stream)
+		}
+		if len(stream) > 1024+len("[... 65536 bytes truncated ...]\n") {
+			t.Errorf("%s kept %d bytes, want at most the 1024 byte limit plus the marker", name, len(stream))
+		}
+	}
+	if !strings.HasSuffix(output.Stderr, "error: webhook denied the request\n") {
+		t.Errorf("stderr does not end with the command's error: %q", output.Stderr[len(output.Stderr)-80:])
+	}
+	if len(result.Error) > 4096 {
+		t.Errorf("deployment error is %d bytes, want the captured output bounded", len(result.Error))
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// matchPathPattern reports whether a changed file matches a monitor path glob
+// Patterns use path.Match syntax; a trailing "/**" matches everything below a directory.
+func matchPathPattern(pattern string, file string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(file, dir+"/")
+	}
+	matched, _ := path.Match(pattern, file)
+	return matched
+}
+
+// firstMatchingPath returns the first changed file matching any pattern, or "" when none does
+func firstMatchingPath(patterns []string, files []string) string {
+	for _, file := range files {
+		for _, pattern := range patterns {
+			if matchPathPattern(pattern, file) {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// changedFilesAllowed reports whether the commits after lastSHA up to sha touch a monitored path
+// Every commit of the range counts, since one poll or push often covers several. Without configured paths
+// every change deploys; if the changed files cannot be listed the change deploys too.
+func (m *MonitorService) changedFilesAllowed(repo *RepositoryConfig, lastSHA string, sha string) bool {
+	if len(repo.Monitor.Paths) == 0 {
+		return true
+	}
+
+	files, err := m.listChangedFiles(&repo.Monitor, lastSHA, sha)
+	if err != nil {
+		AppLogger.WarnS("Failed to list changed files, deploying anyway",
+			"repo", repo.GetDisplayName(),
+			"sha", shortSHA(sha),
+			"error", err)
+		return true
+	}
+
+	if file := firstMatchingPath(repo.Monitor.Paths, files); file != "" {
+		AppLogger.DebugS("Commit touches monitored path",
+			"repo", repo.GetDisplayName(),
+			"sha", shortSHA(sha),
+			"file", file)
+		return true
+	}
+
+	AppLogger.InfoS("Skipping deployment for commit outside monitored paths",
+		"repo", repo.GetDisplayName(),
+		"sha", shortSHA(sha),
+		"paths", repo.Monitor.Paths,
+		"changed_files", len(files))
+	return false
+}
+
+// listChangedFiles returns the files changed after lastSHA up to sha, or by sha alone when lastSHA is empty
+func (m *MonitorService) listChangedFiles(monitor *MonitorConfig, lastSHA string, sha string) ([]string, error) {
+	switch monitor.RepoType {
+	case "github":
+		return m.listGitHubChangedFiles(monitor, lastSHA, sha)
+	default:
+		return nil, fmt.Errorf("path filtering is not supported for %s repositories", monitor.RepoType)
+	}
+}
+
+// listGitHubChangedFiles reads the files list of GitHub's compare API for lastSHA...sha,
+// or of its single-commit API when there is no earlier commit to compare with
+func (m *MonitorService) listGitHubChangedFiles(monitor *MonitorConfig, lastSHA string, sha string) ([]string, error) {
+	repoAPIURL, err := githubRepoAPIURL(monitor)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/commits/%s", repoAPIURL, sha)
+	if lastSHA != "" {
+		url = fmt.Sprintf("%s/compare/%s...%s", repoAPIURL, lastSHA, sha)
+	}
+	req, err := m.newAPIRequest("GET", url)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", auth.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := m.doAPIRequest(req)
+	if err != nil {
+		return nil, classifyTransportError(fmt.Errorf("hTTP request failed: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if rateLimitErr := parseGitHubRateLimit(resp); rateLimitErr != nil {
+		return nil, rateLimitErr
+	}
+
+	// Limit response body size to prevent memory issues
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: "gitHub", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
+	}
+
+	var commit struct {
+		Files []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(body, &commit); err != nil {
+		return nil, fmt.Errorf("failed to parse commit files: %w", err)
+	}
+
+	var files []string
+	for _, file := range commit.Files {
+		files = append(files, file.Filename)
+		// A rename out of a monitored path changes that path too
+		if file.PreviousFilename != "" {
+			files = append(files, file.PreviousFilename)
+		}
+	}
+	return files, nil
+}
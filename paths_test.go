@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"deploy/*.yaml", "deploy/pipeline.yaml", true},
+		{"deploy/*.yaml", "deploy/nested/pipeline.yaml", false},
+		{"charts/**", "charts/app/values.yaml", true},
+		{"charts/**", "chartsx/values.yaml", false},
+		{"Dockerfile", "Dockerfile", true},
+		{"Dockerfile", "docs/Dockerfile", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.file, func(t *testing.T) {
+			if got := matchPathPattern(tt.pattern, tt.file); got != tt.want {
+				t.Errorf("matchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMonitorCheckRepositoryBranchPathFilter checks only commits touching monitored paths trigger deployment
+func TestMonitorCheckRepositoryBranchPathFilter(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name        string
+		files       string // Files of the compared range
+		status      int
+		wantChanged bool
+		headFiles   string // Files of the head commit alone
+	}{
+		{"matching path deploys", `[{"filename":"docs/readme.md"},{"filename":"src/main.go"}]`, http.StatusOK, true, `[]`},
+		{"docs-only change is skipped", `[{"filename":"docs/readme.md"},{"filename":"docs/guide.md"}]`, http.StatusOK, false, `[]`},
+		{"rename out of monitored path deploys", `[{"filename":"old/main.go","previous_filename":"src/main.go"}]`, http.StatusOK, true, `[]`},
+		{"file list failure deploys", `[]`, http.StatusInternalServerError, true, `[]`},
+		{
+			name:        "older commit of the range deploys",
+			files:       `[{"filename":"deploy/pipeline.yaml"},{"filename":"docs/readme.md"}]`,
+			headFiles:   `[{"filename":"docs/readme.md"}]`,
+			status:      http.StatusOK,
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head := "aaa"
+			var requested []string
+			monitor := NewMonitorService(&Config{}, nil)
+			monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.Path)
+				switch {
+				case strings.HasSuffix(req.URL.Path, "/commits/main"):
+					return stubResponse(http.StatusOK, `{"sha":"`+head+`","commit":{"message":"change"}}`), nil
+				case strings.HasSuffix(req.URL.Path, "/commits/bbb"):
+					return stubResponse(http.StatusOK, `{"sha":"bbb","files":`+tt.headFiles+`}`), nil
+				}
+				return stubResponse(tt.status, `{"files":`+tt.files+`}`), nil
+			})})
+
+			repo := &RepositoryConfig{
+				Name: "paths-repo",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/owner/repo",
+					RepoType: "github",
+					Paths:    []string{"src/**", "deploy/*.yaml"},
+				},
+			}
+
+			if _, err := monitor.checkRepositoryBranch(repo, "main"); err != nil {
+				t.Fatalf("baseline checkRepositoryBranch() error = %v", err)
+			}
+			head = "bbb"
+			changed, err := monitor.checkRepositoryBranch(repo, "main")
+			if err != nil {
+				t.Fatalf("checkRepositoryBranch() error = %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if last := requested[len(requested)-1]; last != "/repos/owner/repo/compare/aaa...bbb" {
+				t.Errorf("changed files requested from %s, want /repos/owner/repo/compare/aaa...bbb", last)
+			}
+		})
+	}
+}
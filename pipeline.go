@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	deployModeCommands       = "commands"        // Clone the QA repository and run commands locally (default)
+	deployModeGitLabPipeline = "gitlab_pipeline" // Trigger a pipeline of the QA project through the GitLab trigger API
+)
+
+// PipelineTriggerConfig configures the gitlab_pipeline deploy mode
+type PipelineTriggerConfig struct {
+	TriggerToken string            `yaml:"trigger_token"`          // Pipeline trigger token of the QA project
+	Ref          string            `yaml:"ref,omitempty"`          // Branch or tag to run (default qa_repo_branch)
+	Variables    map[string]string `yaml:"variables,omitempty"`    // Pipeline variables; values may use command template fields
+	APIBaseURL   string            `yaml:"api_base_url,omitempty"` // GitLab API endpoint when it cannot be derived from qa_repo_url
+}
+
+// getDeployMode returns the repository's deploy mode, defaulting to commands
+func getDeployMode(deploy *DeployConfig) string {
+	if deploy.Mode == "" {
+		return deployModeCommands
+	}
+	return deploy.Mode
+}
+
+// pipelineRef returns the ref a triggered pipeline runs on
+func pipelineRef(deploy *DeployConfig) string {
+	if deploy.Pipeline.Ref != "" {
+		return deploy.Pipeline.Ref
+	}
+	return deploy.QARepoBranch
+}
+
+// triggerGitLabPipeline starts a pipeline of the QA project instead of running commands locally
+// The created pipeline's ID and URL are recorded on result; Sentry does not wait for it to finish.
+func (d *DeployService) triggerGitLabPipeline(ctx context.Context, repoConfig *RepositoryConfig, result *DeployResult) error {
+	deploy := &repoConfig.Deploy
+	apiBaseURL, projectPath, err := gitlabProject(&MonitorConfig{RepoURL: deploy.QARepoURL, APIBaseURL: deploy.Pipeline.APIBaseURL})
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("token", deploy.Pipeline.TriggerToken)
+	form.Set("ref", pipelineRef(deploy))
+
+	// Render variables in a stable order so a template error names the same key every time
+	templateData := newCommandTemplateData(repoConfig, result.deployTrigger())
+	keys := make([]string, 0, len(deploy.Pipeline.Variables))
+	for key := range deploy.Pipeline.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := renderCommandTemplate(deploy.Pipeline.Variables[key], templateData)
+		if err != nil {
+			return fmt.Errorf("pipeline variable %s: %w", key, err)
+		}
+		form.Set(fmt.Sprintf("variables[%s]", key), value)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, getDeployTimeout(d.currentConfig()))
+	defer cancel()
+
+	apiURL := fmt.Sprintf("%s/projects/%s/trigger/pipeline", apiBaseURL, projectPath)
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	AppLogger.InfoS("Triggering GitLab pipeline",
+		"repo", repoConfig.GetDisplayName(),
+		"project", redactCredentials(deploy.QARepoURL),
+		"ref", pipelineRef(deploy))
+
+	// deploy_timeout bounds the request through ctx, not webhook_timeout
+	client := *d.webhookClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		// The request URL carries no secrets, but the transport error may echo the form
+		return fmt.Errorf("pipeline trigger request failed: %s", redactCredentials(err.Error(), deploy.Pipeline.TriggerToken))
+	}
+	defer resp.Body.Close()
+
+	// Limit response body size to prevent memory issues
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return &APIError{Provider: "gitLab", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var pipeline struct {
+		ID     int64  `json:"id"`
+		WebURL string `json:"web_url"`
+	}
+	if err := json.Unmarshal(body, &pipeline); err != nil {
+		return fmt.Errorf("failed to parse pipeline response: %w", err)
+	}
+
+	result.PipelineID = pipeline.ID
+	result.PipelineURL = pipeline.WebURL
+
+	AppLogger.InfoS("GitLab pipeline created",
+		"repo", repoConfig.GetDisplayName(),
+		"pipeline_id", pipeline.ID,
+		"url", pipeline.WebURL)
+	return nil
+}
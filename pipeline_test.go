@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPipelineTestService returns a deploy service for one gitlab_pipeline repository and a stubbed trigger API
+func newPipelineTestService(t *testing.T, handler func(req *http.Request, form url.Values) *http.Response) *DeployService {
+	config := &Config{
+		Global: GlobalConfig{TmpDir: t.TempDir(), Cleanup: true},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "pipeline-repo",
+				Deploy: DeployConfig{
+					QARepoURL:    "https://gitlab.example.com/qa/pipelines.git",
+					QARepoBranch: "main",
+					RepoType:     "gitlab",
+					ProjectName:  "pipelines",
+					Mode:         deployModeGitLabPipeline,
+					Pipeline: PipelineTriggerConfig{
+						TriggerToken: "trigger-secret",
+						Variables:    map[string]string{"IMAGE_TAG": "sha-{{.CommitSHA}}"},
+					},
+				},
+			},
+		},
+	}
+
+	service := NewDeployService(config)
+	service.webhookClient = &http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(body))
+		return handler(req, form), nil
+	})}
+	return service
+}
+
+func TestDeployRepositoryTriggersGitLabPipeline(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	var gotURL string
+	var gotForm url.Values
+	service := newPipelineTestService(t, func(req *http.Request, form url.Values) *http.Response {
+		gotURL = req.URL.String()
+		gotForm = form
+		return stubResponse(http.StatusCreated, `{"id":4242,"web_url":"https://gitlab.example.com/qa/pipelines/-/pipelines/4242"}`)
+	})
+	trigger := &DeployTrigger{Branch: "main", CommitInfo: CommitInfo{SHA: "abc123"}}
+
+	result := service.deployRepository("pipeline-repo", context.Background(), trigger)
+	if !result.Success {
+		t.Fatalf("deployRepository() error = %s", result.Error)
+	}
+
+	if want := "https://gitlab.example.com/api/v4/projects/qa%2Fpipelines/trigger/pipeline"; gotURL != want {
+		t.Errorf("requested %s, want %s", gotURL, want)
+	}
+	wantForm := map[string]string{"token": "trigger-secret", "ref": "main", "variables[IMAGE_TAG]": "sha-abc123"}
+	for key, want := range wantForm {
+		if got := gotForm.Get(key); got != want {
+			t.Errorf("form %s = %q, want %q", key, got, want)
+		}
+	}
+	if result.PipelineID != 4242 || result.PipelineURL != "https://gitlab.example.com/qa/pipelines/-/pipelines/4242" {
+		t.Errorf("pipeline = %d %s, want 4242 and its web URL", result.PipelineID, result.PipelineURL)
+	}
+	if len(result.CommandsRun) != 0 || result.ClonePath != "" {
+		t.Errorf("pipeline mode should not clone or run commands: %+v", result)
+	}
+}
+
+func TestDeployRepositoryGitLabPipelineDeployTimeout(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	var deadline time.Time
+	var hasDeadline bool
+	service := newPipelineTestService(t, func(req *http.Request, form url.Values) *http.Response {
+		deadline, hasDeadline = req.Context().Deadline()
+		return stubResponse(http.StatusCreated, `{"id":1,"web_url":"https://gitlab.example.com/qa/pipelines/-/pipelines/1"}`)
+	})
+	service.currentConfig().Global.DeployTimeout = 600
+	service.webhookClient.Timeout = 10 * time.Second
+
+	start := time.Now()
+	if result := service.deployRepository("pipeline-repo", context.Background(), nil); !result.Success {
+		t.Fatalf("deployRepository() error = %s", result.Error)
+	}
+
+	// The trigger request is bounded by deploy_timeout rather than webhook_timeout
+	if !hasDeadline {
+		t.Fatal("pipeline trigger request has no deadline")
+	}
+	if remaining := deadline.Sub(start); remaining < 590*time.Second || remaining > 601*time.Second {
+		t.Errorf("pipeline trigger deadline in %v, want deploy_timeout of 600s", remaining)
+	}
+}
+
+func TestDeployRepositoryGitLabPipelineFailure(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	service := newPipelineTestService(t, func(req *http.Request, form url.Values) *http.Response {
+		return stubResponse(http.StatusBadRequest, `{"message":{"base":["Reference not found"]}}`)
+	})
+
+	result := service.deployRepository("pipeline-repo", context.Background(), nil)
+	if result.Success {
+		t.Fatal("deployRepository() succeeded, want failure")
+	}
+	if !strings.Contains(result.Error, "failed to trigger GitLab pipeline") || !strings.Contains(result.Error, "Reference not found") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+	if strings.Contains(result.Error, "trigger-secret") {
+		t.Errorf("error leaks the trigger token: %s", result.Error)
+	}
+}
+
+func TestValidateDeployConfigModes(t *testing.T) {
+	base := DeployConfig{
+		QARepoURL:    "https://gitlab.example.com/qa/pipelines.git",
+		QARepoBranch: "main",
+		RepoType:     "gitlab",
+		ProjectName:  "pipelines",
+		Auth:         AuthConfig{Token: "token"},
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(d *DeployConfig)
+		wantPaths []string
+	}{
+		{"commands mode needs commands", func(d *DeployConfig) {}, []string{"deploy.commands"}},
+		{"pipeline mode valid", func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+			d.Pipeline.TriggerToken = "secret"
+		}, nil},
+		{"pipeline mode needs token", func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+		}, []string{"deploy.pipeline.trigger_token"}},
+		{"pipeline mode needs gitlab", func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+			d.Pipeline.TriggerToken = "secret"
+			d.RepoType = "github"
+		}, []string{"deploy.repo_type"}},
+		{"pipeline mode rejects commands", func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+			d.Pipeline.TriggerToken = "secret"
+			d.Commands = []CommandSpec{{Run: "kubectl apply -f ."}}
+		}, []string{"deploy.commands"}},
+		{"pipeline mode rejects pre_apply_validate", func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+			d.Pipeline.TriggerToken = "secret"
+			d.PreApplyValidate = true
+		}, []string{"deploy.pre_apply_validate"}},
+		{"branch_map valid", func(d *DeployConfig) {
+			d.Commands = []CommandSpec{{Run: "kubectl apply -f ."}}
+			d.BranchMap = map[string]string{"release/.*": "release", "main": "main"}
+		}, nil},
+		{"branch_map invalid pattern and empty QA branch", func(d *DeployConfig) {
+			d.Commands = []CommandSpec{{Run: "kubectl apply -f ."}}
+			d.BranchMap = map[string]string{"release/(": "release", "main": " "}
+		}, []string{"deploy.branch_map.main", "deploy.branch_map.release/("}},
+		{"negative max_output_bytes", func(d *DeployConfig) {
+			d.Commands = []CommandSpec{{Run: "kubectl apply -f ."}}
+			d.MaxOutputBytes = -1
+		}, []string{"deploy.max_output_bytes"}},
+		{"unknown mode", func(d *DeployConfig) {
+			d.Mode = "argo"
+		}, []string{"deploy.mode"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := base
+			tt.modify(&deploy)
+
+			var paths []string
+			for _, err := range validateDeployConfig(&deploy, "deploy", nil) {
+				paths = append(paths, err.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validation paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
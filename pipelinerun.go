@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultPipelineRunTimeout is how long verification waits for a PipelineRun when timeout is unset
+const defaultPipelineRunTimeout = 30 * time.Minute
+
+// defaultPipelineRunPollInterval is how often the PipelineRun status is checked
+const defaultPipelineRunPollInterval = 10 * time.Second
+
+// PipelineRunVerification waits for the Tekton PipelineRun started by the deploy commands to finish
+type PipelineRunVerification struct {
+	PipelineName string `yaml:"pipeline_name"`       // Pipeline whose newest run is checked
+	Namespace    string `yaml:"namespace,omitempty"` // Default: kube_namespace, else the kubeconfig's namespace
+	Timeout      int    `yaml:"timeout,omitempty"`   // Seconds to wait for a terminal condition (default 1800)
+}
+
+// getPipelineRunTimeout returns the configured verification timeout or default
+func getPipelineRunTimeout(verify *PipelineRunVerification) time.Duration {
+	if verify.Timeout > 0 {
+		return time.Duration(verify.Timeout) * time.Second
+	}
+	return defaultPipelineRunTimeout
+}
+
+// pipelineRunList is the subset of `kubectl get pipelinerun -o json` output verification reads
+type pipelineRunList struct {
+	Items []pipelineRun `json:"items"`
+}
+
+// pipelineRun is the subset of a Tekton PipelineRun verification reads
+type pipelineRun struct {
+	Metadata struct {
+		Name              string    `json:"name"`
+		CreationTimestamp time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	Status struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+// outcome reports whether the run finished and, if so, whether it succeeded
+// A finished run that failed also returns its reason and message.
+func (r *pipelineRun) outcome() (done bool, succeeded bool, detail string) {
+	for _, condition := range r.Status.Conditions {
+		if condition.Type != "Succeeded" {
+			continue
+		}
+		switch condition.Status {
+		case "True":
+			return true, true, ""
+		case "False":
+			return true, false, fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+		}
+	}
+	return false, false, ""
+}
+
+// verifyPipelineRun waits for the newest run of the configured pipeline started since startedAt
+// to reach a terminal condition, and fails unless it succeeded. The run name is recorded on result.
+func (d *DeployService) verifyPipelineRun(ctx context.Context, repoConfig *RepositoryConfig, workDir string, startedAt time.Time, result *DeployResult) error {
+	verify := repoConfig.Deploy.VerifyPipelineRun
+	if verify == nil {
+		return nil
+	}
+
+	args := []string{"get", "pipelinerun", "-l", "tekton.dev/pipeline=" + verify.PipelineName, "-o", "json"}
+	if namespace := pipelineRunNamespace(&repoConfig.Deploy); namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	if usesKubeGuard(&repoConfig.Deploy) {
+		args = append([]string{"--kubeconfig", hostKubeconfigPath(workDir)}, args...)
+	}
+
+	timeout := getPipelineRunTimeout(verify)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// creationTimestamp has second precision
+	since := startedAt.Truncate(time.Second)
+
+	AppLogger.InfoS("Waiting for PipelineRun to finish",
+		"repo", repoConfig.GetDisplayName(),
+		"pipeline", verify.PipelineName,
+		"timeout", timeout)
+
+	var lastErr error
+	for {
+		// A failed lookup is retried on the next poll; only the timeout ends verification
+		run, err := d.latestPipelineRun(ctx, args, since)
+		if err != nil && ctx.Err() == nil {
+			AppLogger.WarnS("Failed to check PipelineRun status", "repo", repoConfig.GetDisplayName(), "error", err)
+			lastErr = err
+		}
+		if run != nil {
+			result.PipelineRun = run.Metadata.Name
+			if done, succeeded, detail := run.outcome(); done {
+				if !succeeded {
+					return fmt.Errorf("PipelineRun %s failed: %s", run.Metadata.Name, detail)
+				}
+				AppLogger.InfoS("PipelineRun succeeded", "repo", repoConfig.GetDisplayName(), "pipeline_run", run.Metadata.Name)
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return fmt.Errorf("PipelineRun verification interrupted: %w", ErrShuttingDown)
+			}
+			if result.PipelineRun != "" {
+				return fmt.Errorf("PipelineRun %s did not finish within %s", result.PipelineRun, timeout)
+			}
+			if lastErr != nil {
+				return fmt.Errorf("no PipelineRun of pipeline %s found within %s: %w", verify.PipelineName, timeout, lastErr)
+			}
+			return fmt.Errorf("no PipelineRun of pipeline %s started within %s", verify.PipelineName, timeout)
+		case <-time.After(d.pipelineRunPollInterval):
+		}
+	}
+}
+
+// latestPipelineRun returns the newest PipelineRun created at or after since, or nil if none exists yet
+func (d *DeployService) latestPipelineRun(ctx context.Context, args []string, since time.Time) (*pipelineRun, error) {
+	output, err := d.kubectl(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PipelineRuns: %w", err)
+	}
+
+	var list pipelineRunList
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode PipelineRuns: %w", err)
+	}
+
+	var latest *pipelineRun
+	for i := range list.Items {
+		run := &list.Items[i]
+		if run.Metadata.CreationTimestamp.Before(since) {
+			continue
+		}
+		if latest == nil || run.Metadata.CreationTimestamp.After(latest.Metadata.CreationTimestamp) {
+			latest = run
+		}
+	}
+	return latest, nil
+}
+
+// pipelineRunNamespace returns the namespace PipelineRuns are looked up in, "" for the kubeconfig default
+func pipelineRunNamespace(deploy *DeployConfig) string {
+	if deploy.VerifyPipelineRun.Namespace != "" {
+		return deploy.VerifyPipelineRun.Namespace
+	}
+	return deploy.KubeNamespace
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipelineRunListJSON renders `kubectl get pipelinerun -o json` output; runs map names to a Succeeded status, "" for none yet
+func pipelineRunListJSON(created time.Time, runs ...[2]string) []byte {
+	var items []string
+	for _, run := range runs {
+		conditions := "[]"
+		switch run[1] {
+		case "True":
+			conditions = `[{"type":"Succeeded","status":"True","reason":"Succeeded","message":"Tasks Completed: 2"}]`
+		case "False":
+			conditions = `[{"type":"Succeeded","status":"False","reason":"Failed","message":"Tasks Completed: 2 (Failed: 1)"}]`
+		case "Unknown":
+			conditions = `[{"type":"Succeeded","status":"Unknown","reason":"Running","message":"Tasks Completed: 1"}]`
+		}
+		items = append(items, fmt.Sprintf(`{"metadata":{"name":%q,"creationTimestamp":%q},"status":{"conditions":%s}}`,
+			run[0], created.UTC().Format(time.RFC3339), conditions))
+	}
+	return []byte(`{"apiVersion":"v1","kind":"List","items":[` + strings.Join(items, ",") + `]}`)
+}
+
+func TestDeployRepositoryVerifyPipelineRun(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	later := now.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		polls     []func() ([]byte, error) // kubectl answers, in order; the last one repeats
+		wantRun   string
+		wantError string
+	}{
+		{
+			name: "succeeded after running",
+			polls: []func() ([]byte, error){
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", ""}), nil },
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", "Unknown"}), nil },
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", "True"}), nil },
+			},
+			wantRun: "app-run-2",
+		},
+		{
+			name: "failed",
+			polls: []func() ([]byte, error){
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", "False"}), nil },
+			},
+			wantRun:   "app-run-2",
+			wantError: "pipeline run verification failed: PipelineRun app-run-2 failed: Failed: Tasks Completed: 2 (Failed: 1)",
+		},
+		{
+			name: "transient kubectl error is retried",
+			polls: []func() ([]byte, error){
+				func() ([]byte, error) { return nil, errors.New("connection refused") },
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", "True"}), nil },
+			},
+			wantRun: "app-run-2",
+		},
+		{
+			name: "runs from before the deployment are ignored",
+			polls: []func() ([]byte, error){
+				func() ([]byte, error) { return pipelineRunListJSON(old, [2]string{"app-run-1", "True"}), nil },
+			},
+			wantError: "pipeline run verification failed: no PipelineRun of pipeline app-pipeline started within 1s",
+		},
+		{
+			name: "still running at the timeout",
+			polls: []func() ([]byte, error){
+				func() ([]byte, error) { return pipelineRunListJSON(later, [2]string{"app-run-2", "Unknown"}), nil },
+			},
+			wantRun:   "app-run-2",
+			wantError: "pipeline run verification failed: PipelineRun app-run-2 did not finish within 1s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+			config.Repositories[0].Deploy.VerifyPipelineRun = &PipelineRunVerification{
+				PipelineName: "app-pipeline",
+				Namespace:    "tekton-pipelines",
+				Timeout:      1,
+			}
+
+			var calls []string
+			service := NewDeployService(config)
+			service.pipelineRunPollInterval = time.Millisecond
+			service.kubectl = func(ctx context.Context, args ...string) ([]byte, error) {
+				calls = append(calls, strings.Join(args, " "))
+				poll := tt.polls[min(len(calls), len(tt.polls))-1]
+				return poll()
+			}
+
+			result := service.deployRepository("drain-repo", context.Background(), nil)
+
+			if want := "get pipelinerun -l tekton.dev/pipeline=app-pipeline -o json -n tekton-pipelines"; len(calls) == 0 || calls[0] != want {
+				t.Errorf("kubectl calls = %q, want %q", calls, want)
+			}
+			if result.PipelineRun != tt.wantRun {
+				t.Errorf("PipelineRun = %q, want %q", result.PipelineRun, tt.wantRun)
+			}
+			if tt.wantError == "" {
+				if !result.Success {
+					t.Errorf("deployRepository() error = %s, want success", result.Error)
+				}
+				return
+			}
+			if result.Success || result.Error != tt.wantError {
+				t.Errorf("result = success %v error %q, want %q", result.Success, result.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestVerifyPipelineRunNotConfigured(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+	service := NewDeployService(config)
+	service.kubectl = func(ctx context.Context, args ...string) ([]byte, error) {
+		t.Errorf("unexpected kubectl call: %v", args)
+		return nil, nil
+	}
+
+	if result := service.deployRepository("drain-repo", context.Background(), nil); !result.Success || result.PipelineRun != "" {
+		t.Errorf("result = success %v run %q, want success without verification", result.Success, result.PipelineRun)
+	}
+}
+
+func TestValidateDeployConfigVerifyPipelineRun(t *testing.T) {
+	base := DeployConfig{
+		QARepoURL:    "https://gitlab.example.com/qa/pipelines.git",
+		QARepoBranch: "main",
+		RepoType:     "gitlab",
+		ProjectName:  "pipelines",
+		Auth:         AuthConfig{Token: "token"},
+		Commands:     []CommandSpec{{Run: "kubectl create -f pipelinerun.yaml"}},
+	}
+
+	tests := []struct {
+		name      string
+		verify    PipelineRunVerification
+		modify    func(d *DeployConfig)
+		wantPaths []string
+	}{
+		{"valid", PipelineRunVerification{PipelineName: "app-pipeline", Namespace: "ci", Timeout: 600}, func(d *DeployConfig) {}, nil},
+		{"needs pipeline name", PipelineRunVerification{}, func(d *DeployConfig) {}, []string{"deploy.verify_pipeline_run.pipeline_name"}},
+		{"invalid namespace and timeout", PipelineRunVerification{PipelineName: "app-pipeline", Namespace: "CI", Timeout: -1}, func(d *DeployConfig) {},
+			[]string{"deploy.verify_pipeline_run.namespace", "deploy.verify_pipeline_run.timeout"}},
+		{"rejected in pipeline mode", PipelineRunVerification{PipelineName: "app-pipeline"}, func(d *DeployConfig) {
+			d.Mode = deployModeGitLabPipeline
+			d.Pipeline.TriggerToken = "secret"
+			d.Commands = nil
+		}, []string{"deploy.verify_pipeline_run"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := base
+			verify := tt.verify
+			deploy.VerifyPipelineRun = &verify
+			tt.modify(&deploy)
+
+			var paths []string
+			for _, err := range validateDeployConfig(&deploy, "deploy", nil) {
+				paths = append(paths, err.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("validation paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "time"
+
+// pollSchedule tracks when each configured repository is next due for a check
+type pollSchedule struct {
+	intervals []time.Duration
+	next      []time.Time
+}
+
+// newPollSchedule schedules every repository's first check one interval after start
+func newPollSchedule(config *Config, start time.Time) *pollSchedule {
+	schedule := &pollSchedule{
+		intervals: make([]time.Duration, len(config.Repositories)),
+		next:      make([]time.Time, len(config.Repositories)),
+	}
+	for i := range config.Repositories {
+		schedule.intervals[i] = getPollInterval(config, &config.Repositories[i])
+		schedule.next[i] = start.Add(schedule.intervals[i])
+	}
+	return schedule
+}
+
+// getPollInterval gets the repository's polling interval, falling back to the global one
+func getPollInterval(config *Config, repo *RepositoryConfig) time.Duration {
+	if repo.PollInterval > 0 {
+		return time.Duration(repo.PollInterval) * time.Second
+	}
+	return time.Duration(config.PollingInterval) * time.Second
+}
+
+// nextDue returns the earliest time any repository is due, false when nothing is scheduled
+func (s *pollSchedule) nextDue() (time.Time, bool) {
+	var earliest time.Time
+	for i, next := range s.next {
+		if i == 0 || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	return earliest, len(s.next) > 0
+}
+
+// due returns the indexes of repositories due at now and reschedules them one interval later
+func (s *pollSchedule) due(now time.Time) []int {
+	var indexes []int
+	for i, next := range s.next {
+		if next.After(now) {
+			continue
+		}
+		indexes = append(indexes, i)
+
+		// Skip missed slots rather than checking in a burst after a slow pass
+		for !s.next[i].After(now) {
+			s.next[i] = s.next[i].Add(s.intervals[i])
+		}
+	}
+	return indexes
+}
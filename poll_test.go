@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetPollInterval(t *testing.T) {
+	config := &Config{PollingInterval: 300}
+
+	tests := []struct {
+		name     string
+		repo     RepositoryConfig
+		expected time.Duration
+	}{
+		{name: "global default", repo: RepositoryConfig{Name: "repo"}, expected: 300 * time.Second},
+		{name: "override", repo: RepositoryConfig{Name: "repo", PollInterval: 60}, expected: 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPollInterval(config, &tt.repo); got != tt.expected {
+				t.Errorf("getPollInterval() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPollScheduleCadence(t *testing.T) {
+	config := &Config{
+		PollingInterval: 600,
+		Repositories: []RepositoryConfig{
+			{Name: "monorepo", PollInterval: 60},
+			{Name: "quiet-repo"},
+			{Name: "medium-repo", PollInterval: 180},
+		},
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := newPollSchedule(config, start)
+
+	// Follow the schedule for 20 minutes, counting checks per repository
+	checks := make([]int, len(config.Repositories))
+	var firstPass []int
+	for {
+		now, ok := schedule.nextDue()
+		if !ok {
+			t.Fatal("nextDue() reported an empty schedule")
+		}
+		if now.After(start.Add(20 * time.Minute)) {
+			break
+		}
+		due := schedule.due(now)
+		if len(due) == 0 {
+			t.Fatalf("due(%v) returned nothing at the time nextDue reported", now)
+		}
+		if firstPass == nil {
+			firstPass = due
+		}
+		for _, i := range due {
+			checks[i]++
+		}
+	}
+
+	if want := []int{20, 2, 6}; !reflect.DeepEqual(checks, want) {
+		t.Errorf("checks = %v, want %v", checks, want)
+	}
+	if want := []int{0}; !reflect.DeepEqual(firstPass, want) {
+		t.Errorf("first due = %v, want only the 60s repository", firstPass)
+	}
+}
+
+func TestPollScheduleSkipsMissedSlots(t *testing.T) {
+	config := &Config{PollingInterval: 60, Repositories: []RepositoryConfig{{Name: "repo"}}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := newPollSchedule(config, start)
+
+	// A pass that overran several intervals checks once, then resumes the cadence
+	if due := schedule.due(start.Add(5*time.Minute + 10*time.Second)); len(due) != 1 {
+		t.Fatalf("due() = %v, want one check", due)
+	}
+	next, _ := schedule.nextDue()
+	if want := start.Add(6 * time.Minute); !next.Equal(want) {
+		t.Errorf("nextDue() = %v, want %v", next, want)
+	}
+}
+
+func TestPollScheduleEmpty(t *testing.T) {
+	schedule := newPollSchedule(&Config{PollingInterval: 60}, time.Now())
+	if _, ok := schedule.nextDue(); ok {
+		t.Error("nextDue() ok = true for a config without repositories")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// kubectlDryRunFlag makes kubectl apply send the manifests through server-side validation and admission without persisting them
+const kubectlDryRunFlag = "--dry-run=server"
+
+// kubectlApplyDryRun returns command with a server-side dry run added after its apply subcommand
+// ok is false for commands that are not a single kubectl apply, and for ones already dry-running:
+// a chained command cannot be dry-run without also running the rest of the chain for real.
+func kubectlApplyDryRun(command string) (dryRun string, ok bool) {
+	for _, operator := range commandChainOperators {
+		if strings.Contains(command, operator) {
+			return "", false
+		}
+	}
+	if strings.Contains(command, "--dry-run") {
+		return "", false
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) < 2 || filepath.Base(strings.Trim(fields[0], `"'`)) != "kubectl" {
+		return "", false
+	}
+
+	// The subcommand is the first argument that is neither a flag nor a flag's value
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		if strings.HasPrefix(field, "-") {
+			if !strings.Contains(field, "=") && kubectlFlagTakesValue(field) {
+				i++
+			}
+			continue
+		}
+		if field != "apply" {
+			return "", false
+		}
+		fields = append(fields[:i+1], append([]string{kubectlDryRunFlag}, fields[i+1:]...)...)
+		return strings.Join(fields, " "), true
+	}
+	return "", false
+}
+
+// kubectlFlagTakesValue reports whether a global kubectl flag given before the subcommand is followed by its value
+func kubectlFlagTakesValue(flag string) bool {
+	switch flag {
+	case "-n", "--namespace", "--context", "--kubeconfig", "--cluster", "--user", "-s", "--server", "--token", "--as", "--request-timeout":
+		return true
+	}
+	return false
+}
+
+// preApplyValidate dry-runs every kubectl apply command before any command runs
+// Each dry run is recorded in result.ValidationOutputs; the first rejected manifest fails the deployment.
+func (d *DeployService) preApplyValidate(ctx context.Context, repoConfig *RepositoryConfig, workDir string, result *DeployResult) error {
+	templateData := newCommandTemplateData(repoConfig, result.deployTrigger())
+	allowed := d.currentConfig().Global.AllowedCommandBinaries
+
+	for i, spec := range repoConfig.Deploy.Commands {
+		dryRun, ok := kubectlApplyDryRun(spec.Run)
+		if !ok {
+			if strings.Contains(spec.Run, "kubectl") && strings.Contains(spec.Run, "apply") {
+				AppLogger.WarnS("Command not dry-run by pre_apply_validate; only single kubectl apply commands are",
+					"repo", repoConfig.GetDisplayName(),
+					"step", i+1,
+					"command", spec.Run)
+			}
+			continue
+		}
+
+		if shuttingDown(ctx) {
+			return fmt.Errorf("step %d not validated: %w", i+1, ErrShuttingDown)
+		}
+
+		if err := checkAllowedCommand(dryRun, allowed); err != nil {
+			return fmt.Errorf("step %d not validated: command not allowed: %w", i+1, err)
+		}
+
+		AppLogger.InfoS("Validating manifests",
+			"repo", repoConfig.GetDisplayName(),
+			"step", i+1,
+			"command", dryRun)
+
+		dryRunSpec := spec
+		dryRunSpec.Run = dryRun
+		output, _, err := runDeploymentCommand(ctx, repoConfig, workDir, &dryRunSpec, templateData)
+		result.ValidationOutputs = append(result.ValidationOutputs, output)
+		if err != nil {
+			return fmt.Errorf("step %d rejected by %s: %s, error: %w, output: %s", i+1, kubectlDryRunFlag, dryRun, err, output.combined())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKubectlApplyDryRun(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+		wantOK  bool
+	}{
+		{"apply", "kubectl apply -f .", "kubectl apply --dry-run=server -f .", true},
+		{"global flags before apply", "kubectl -n qa --context kind-qa apply -k overlays/qa", "kubectl -n qa --context kind-qa apply --dry-run=server -k overlays/qa", true},
+		{"joined flag value", "kubectl --namespace=qa apply -f deploy.yaml", "kubectl --namespace=qa apply --dry-run=server -f deploy.yaml", true},
+		{"full path", "/usr/local/bin/kubectl apply -f -", "/usr/local/bin/kubectl apply --dry-run=server -f -", true},
+		{"other subcommand", "kubectl wait --for=condition=Ready pipeline/rag-build", "", false},
+		{"apply as an argument", "kubectl delete configmap apply", "", false},
+		{"already dry-running", "kubectl apply --dry-run=client -f .", "", false},
+		{"chained", "kubectl apply -f . && kubectl rollout status deploy/rag", "", false},
+		{"piped", "kustomize build . | kubectl apply -f -", "", false},
+		{"not kubectl", "helm upgrade --install rag charts/rag", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := kubectlApplyDryRun(tt.command)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("kubectlApplyDryRun(%q) = %q, %v, want %q, %v", tt.command, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDeployRepositoryPreApplyValidate(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	// A stub kubectl logs its arguments and rejects manifest files missing from the working directory
+	binDir := t.TempDir()
+	argsLog := filepath.Join(binDir, "kubectl.log")
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$@" >> %s
+for arg in "$@"; do
+  case "$arg" in
+    *.yaml) [ -f "$arg" ] || { echo "error: the path \"$arg\" does not exist" >&2; exit 1; } ;;
+  esac
+done
+echo "pipeline.tekton.dev/rag-build configured"
+`, argsLog)
+	if err := os.WriteFile(filepath.Join(binDir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write kubectl stub: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name            string
+		command         string
+		wantSuccess     bool
+		wantError       string
+		wantCalls       []string // kubectl invocations, in order
+		wantValidations int
+	}{
+		{
+			name:            "valid manifest is dry-run then applied",
+			command:         "kubectl apply -f deploy.yaml",
+			wantSuccess:     true,
+			wantCalls:       []string{"apply --dry-run=server -f deploy.yaml", "apply -f deploy.yaml"},
+			wantValidations: 1,
+		},
+		{
+			name:            "invalid manifest stops the deployment before any command",
+			command:         "kubectl apply -f missing.yaml",
+			wantError:       "pre-apply validation failed",
+			wantCalls:       []string{"apply --dry-run=server -f missing.yaml"},
+			wantValidations: 1,
+		},
+		{
+			name:        "chained apply is not validated",
+			command:     "kubectl apply -f deploy.yaml && true",
+			wantSuccess: true,
+			wantCalls:   []string{"apply -f deploy.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(argsLog)
+
+			config := newDrainTestConfig(t, []CommandSpec{{Run: tt.command}})
+			rollbackMarker := filepath.Join(t.TempDir(), "rolled-back")
+			config.Repositories[0].Deploy.PreApplyValidate = true
+			config.Repositories[0].Deploy.RollbackCommands = []CommandSpec{{Run: "touch " + rollbackMarker}}
+			service := NewDeployService(config)
+
+			result := service.deployRepository("drain-repo", context.Background(), nil)
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("deployRepository() success = %v, error = %s, want success %v", result.Success, result.Error, tt.wantSuccess)
+			}
+			if tt.wantError != "" && !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("deployRepository() error = %q, want it to contain %q", result.Error, tt.wantError)
+			}
+
+			data, _ := os.ReadFile(argsLog)
+			calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if strings.Join(calls, "|") != strings.Join(tt.wantCalls, "|") {
+				t.Errorf("kubectl calls = %q, want %q", calls, tt.wantCalls)
+			}
+
+			if len(result.ValidationOutputs) != tt.wantValidations {
+				t.Fatalf("ValidationOutputs = %+v, want %d entries", result.ValidationOutputs, tt.wantValidations)
+			}
+			if !tt.wantSuccess {
+				if !strings.Contains(result.ValidationOutputs[0].Stderr, "does not exist") {
+					t.Errorf("validation stderr = %q, want the kubectl error", result.ValidationOutputs[0].Stderr)
+				}
+				if len(result.CommandsRun) != 0 || len(result.RollbackRun) != 0 {
+					t.Errorf("CommandsRun = %v, RollbackRun = %v, want nothing run after a failed validation", result.CommandsRun, result.RollbackRun)
+				}
+				if _, err := os.Stat(rollbackMarker); err == nil {
+					t.Error("rollback commands ran after a failed validation")
+				}
+			}
+		})
+	}
+}
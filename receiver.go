@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultWebhookAddr is where the push webhook receiver listens when webhook_addr is unset
+const defaultWebhookAddr = ":9000"
+
+// webhookPathPrefix is followed by the repository name in push webhook URLs, e.g. /webhooks/my-repo
+const webhookPathPrefix = "/webhooks/"
+
+// maxWebhookBodySize bounds push payloads read by the receiver
+const maxWebhookBodySize = 1024 * 1024 // 1MB
+
+// WebhookReceiver is the optional HTTP server accepting GitHub and GitLab push webhooks
+type WebhookReceiver struct {
+	server *http.Server
+}
+
+// pushEvent is the part of a push webhook that drives change detection
+type pushEvent struct {
+	branch string
+	commit CommitInfo
+}
+
+// getWebhookAddr gets the push webhook listen address from global config or uses default
+func getWebhookAddr(config *Config) string {
+	if config.Global.WebhookAddr != "" {
+		return config.Global.WebhookAddr
+	}
+	return defaultWebhookAddr
+}
+
+// usesPushWebhooks reports whether any repository has opted into push webhooks
+func usesPushWebhooks(config *Config) bool {
+	for _, repo := range config.Repositories {
+		if repo.WebhookSecret != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWebhookReceiver creates a receiver on addr; pushes deploy through monitor until ctx is cancelled
+func NewWebhookReceiver(ctx context.Context, addr string, monitor *MonitorService) *WebhookReceiver {
+	return &WebhookReceiver{
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           newWebhookHandler(ctx, monitor),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start serves requests in the background; listen errors are logged
+func (r *WebhookReceiver) Start() {
+	AppLogger.InfoS("Starting push webhook receiver", "addr", r.server.Addr)
+
+	go func() {
+		if err := r.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			AppLogger.ErrorS("Push webhook receiver stopped", "addr", r.server.Addr, "error", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops the receiver
+func (r *WebhookReceiver) Shutdown(ctx context.Context) error {
+	return r.server.Shutdown(ctx)
+}
+
+// newWebhookHandler routes POST /webhooks/<repo> to the repository's provider-specific push parser
+// Accepted pushes are answered immediately and deployed in the background.
+func newWebhookHandler(ctx context.Context, monitor *MonitorService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		repo := monitor.webhookRepository(strings.TrimPrefix(r.URL.Path, webhookPathPrefix))
+		if !strings.HasPrefix(r.URL.Path, webhookPathPrefix) || repo == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		var event *pushEvent
+		switch repo.Monitor.RepoType {
+		case "github":
+			event, err = parseGitHubPush(r, body, repo.WebhookSecret)
+		case "gitlab":
+			event, err = parseGitLabPush(r, body, repo.WebhookSecret)
+		default:
+			err = fmt.Errorf("%w: push webhooks are not supported for %s repositories", errBadPush, repo.Monitor.RepoType)
+		}
+
+		switch {
+		case errors.Is(err, errBadSignature):
+			AppLogger.WarnS("Rejected push webhook", "repo", repo.GetDisplayName(), "error", err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		case err != nil:
+			AppLogger.WarnS("Rejected push webhook", "repo", repo.GetDisplayName(), "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case event == nil:
+			// Pings, tag pushes and branch deletions need no deployment
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "ignored")
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "accepted")
+
+		go func() {
+			if err := monitor.HandlePush(ctx, repo, event.branch, &event.commit); err != nil {
+				AppLogger.ErrorS("Push webhook deployment failed", "repo", repo.GetDisplayName(), "error", err)
+			}
+		}()
+	})
+}
+
+// webhookRepository returns the repository named in a webhook URL if it has a webhook secret
+func (m *MonitorService) webhookRepository(name string) *RepositoryConfig {
+	repos := m.currentConfig().Repositories
+	for i := range repos {
+		repo := &repos[i]
+		if repo.Name == name && repo.WebhookSecret != "" {
+			return repo
+		}
+	}
+	return nil
+}
+
+// HandlePush feeds a pushed branch head into change detection and deploys it like a polled change
+// Pushes to branches the repository does not monitor are ignored.
+func (m *MonitorService) HandlePush(ctx context.Context, repo *RepositoryConfig, branch string, commit *CommitInfo) error {
+	if !repo.IsEnabled() {
+		AppLogger.InfoS("Ignoring push to disabled repository", "repo", repo.GetDisplayName(), "branch", branch)
+		return nil
+	}
+	if !monitorsBranch(&repo.Monitor, branch) {
+		AppLogger.DebugS("Ignoring push to unmonitored branch", "repo", repo.GetDisplayName(), "branch", branch)
+		return nil
+	}
+
+	AppLogger.InfoS("Push webhook received",
+		"repo", repo.GetDisplayName(),
+		"branch", branch,
+		"sha", shortSHA(commit.SHA))
+
+	if !m.recordBranchCommit(repo, branch, commit) {
+		return nil
+	}
+
+	// Do not start deployments once shutdown has been requested
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if failures := m.deployChanges(ctx, []repoChange{{repo: repo, refs: []string{branch}}}); len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// monitorsBranch reports whether branch matches one of the monitor's branch names or patterns
+func monitorsBranch(monitor *MonitorConfig, branch string) bool {
+	for _, configured := range monitor.Branches {
+		if matchesBranch(configured, branch) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	errBadSignature = errors.New("webhook signature mismatch")
+	errBadPush      = errors.New("invalid push webhook")
+)
+
+// parseGitHubPush verifies X-Hub-Signature-256 and extracts the pushed branch head
+// It returns a nil event for pings, non-push events, tag pushes and branch deletions.
+func parseGitHubPush(r *http.Request, body []byte, secret string) (*pushEvent, error) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(r.Header.Get("X-Hub-Signature-256")), []byte(expected)) {
+		return nil, errBadSignature
+	}
+
+	if r.Header.Get("X-GitHub-Event") != "push" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		HeadCommit struct {
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			URL       string    `json:"url"`
+			Author    struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"head_commit"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadPush, err)
+	}
+
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok || payload.Deleted {
+		return nil, nil
+	}
+
+	return &pushEvent{
+		branch: branch,
+		commit: CommitInfo{
+			SHA:       payload.After,
+			Message:   payload.HeadCommit.Message,
+			Author:    payload.HeadCommit.Author.Name,
+			Timestamp: payload.HeadCommit.Timestamp,
+			URL:       payload.HeadCommit.URL,
+		},
+	}, nil
+}
+
+// parseGitLabPush verifies X-Gitlab-Token and extracts the pushed branch head
+// It returns a nil event for non-push events and branch deletions.
+func parseGitLabPush(r *http.Request, body []byte, secret string) (*pushEvent, error) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+		return nil, errBadSignature
+	}
+
+	if r.Header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, nil
+	}
+
+	var payload struct {
+		Ref     string `json:"ref"`
+		After   string `json:"after"`
+		Commits []struct {
+			ID        string    `json:"id"`
+			Message   string    `json:"message"`
+			Timestamp time.Time `json:"timestamp"`
+			URL       string    `json:"url"`
+			Author    struct {
+				Name string `json:"name"`
+			} `json:"author"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadPush, err)
+	}
+
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok || strings.Trim(payload.After, "0") == "" {
+		return nil, nil
+	}
+
+	event := &pushEvent{branch: branch, commit: CommitInfo{SHA: payload.After}}
+	for _, commit := range payload.Commits {
+		if commit.ID == payload.After {
+			event.commit.Message = commit.Message
+			event.commit.Author = commit.Author.Name
+			event.commit.Timestamp = commit.Timestamp
+			event.commit.URL = commit.URL
+		}
+	}
+	return event, nil
+}
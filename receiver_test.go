@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newReceiverTestService returns a monitor whose drain-repo deploys by touching marker on a webhook push
+func newReceiverTestService(t *testing.T, repoType, marker string) *MonitorService {
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "touch " + marker}})
+	config.Repositories[0].Monitor = MonitorConfig{
+		RepoURL:  "https://" + repoType + ".com/owner/app",
+		Branches: []string{"main", "release-.*"},
+		RepoType: repoType,
+	}
+	config.Repositories[0].WebhookSecret = "s3cret"
+
+	service := NewMonitorService(config, NewDeployService(config))
+	service.lastCommit[refCacheKey("drain-repo", "main")] = "0000000000000000000000000000000000000001"
+	return service
+}
+
+// signGitHubPayload returns the X-Hub-Signature-256 value for body
+func signGitHubPayload(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const githubPushBody = `{"ref":"refs/heads/main","after":"abcdef1234567890","deleted":false,"head_commit":{"message":"Update app","author":{"name":"Alice"}}}`
+
+func TestWebhookReceiverGitHubPushTriggersDeployment(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	marker := filepath.Join(t.TempDir(), "deployed")
+	service := newReceiverTestService(t, "github", marker)
+	handler := newWebhookHandler(context.Background(), service)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/drain-repo", strings.NewReader(githubPushBody))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", signGitHubPayload("s3cret", githubPushBody))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d (%s)", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+
+	waitForFile(t, marker)
+	if !service.deployService.WaitForDeployments(10 * time.Second) {
+		t.Fatal("deployment did not finish")
+	}
+
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+	if got := service.lastCommit[refCacheKey("drain-repo", "main")]; got != "abcdef1234567890" {
+		t.Errorf("lastCommit = %q, want pushed SHA", got)
+	}
+}
+
+func TestWebhookReceiverRejectsRequests(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		repoType   string
+		headers    map[string]string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "bad github signature",
+			method:     http.MethodPost,
+			path:       "/webhooks/drain-repo",
+			repoType:   "github",
+			headers:    map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": signGitHubPayload("wrong", githubPushBody)},
+			body:       githubPushBody,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing github signature",
+			method:     http.MethodPost,
+			path:       "/webhooks/drain-repo",
+			repoType:   "github",
+			headers:    map[string]string{"X-GitHub-Event": "push"},
+			body:       githubPushBody,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "github ping",
+			method:     http.MethodPost,
+			path:       "/webhooks/drain-repo",
+			repoType:   "github",
+			headers:    map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": signGitHubPayload("s3cret", `{"zen":"hi"}`)},
+			body:       `{"zen":"hi"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bad gitlab token",
+			method:     http.MethodPost,
+			path:       "/webhooks/drain-repo",
+			repoType:   "gitlab",
+			headers:    map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "wrong"},
+			body:       `{"ref":"refs/heads/main","after":"abcdef1234567890"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown repository",
+			method:     http.MethodPost,
+			path:       "/webhooks/other-repo",
+			repoType:   "github",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       "/webhooks/drain-repo",
+			repoType:   "github",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newReceiverTestService(t, tt.repoType, filepath.Join(t.TempDir(), "deployed"))
+			handler := newWebhookHandler(context.Background(), service)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseGitLabPush(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      string
+		body       string
+		wantBranch string
+		wantMsg    string
+	}{
+		{"push", "Push Hook", `{"ref":"refs/heads/main","after":"bbb","commits":[{"id":"aaa","message":"old"},{"id":"bbb","message":"new","author":{"name":"Bob"}}]}`, "main", "new"},
+		{"tag push", "Tag Push Hook", `{"ref":"refs/tags/v1","after":"bbb"}`, "", ""},
+		{"tag ref", "Push Hook", `{"ref":"refs/tags/v1","after":"bbb"}`, "", ""},
+		{"branch deleted", "Push Hook", `{"ref":"refs/heads/main","after":"0000000000000000000000000000000000000000"}`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/repo", strings.NewReader(tt.body))
+			req.Header.Set("X-Gitlab-Token", "s3cret")
+			req.Header.Set("X-Gitlab-Event", tt.event)
+
+			event, err := parseGitLabPush(req, []byte(tt.body), "s3cret")
+			if err != nil {
+				t.Fatalf("parseGitLabPush() error = %v", err)
+			}
+			if tt.wantBranch == "" {
+				if event != nil {
+					t.Errorf("parseGitLabPush() = %+v, want ignored", event)
+				}
+				return
+			}
+			if event == nil || event.branch != tt.wantBranch || event.commit.Message != tt.wantMsg {
+				t.Errorf("parseGitLabPush() = %+v, want branch %q message %q", event, tt.wantBranch, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestHandlePushIgnoresUnmonitoredBranch(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	service := newReceiverTestService(t, "github", filepath.Join(t.TempDir(), "deployed"))
+	repo := &service.currentConfig().Repositories[0]
+
+	if err := service.HandlePush(context.Background(), repo, "feature", &CommitInfo{SHA: "abc"}); err != nil {
+		t.Fatalf("HandlePush() error = %v", err)
+	}
+	if _, seen := service.lastCommit[refCacheKey("drain-repo", "feature")]; seen {
+		t.Error("HandlePush() recorded a commit for an unmonitored branch")
+	}
+}
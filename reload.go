@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Reload swaps in a new, already validated configuration without restarting the watcher
+// Commit state is kept for repositories that still exist, so they do not redeploy or
+// re-baseline; repositories that were removed are forgotten. Listener addresses, logging,
+// the result store, retry, timeout, cooldown and history settings keep their startup values.
+func (m *MonitorService) Reload(config *Config) {
+	m.config.Store(config)
+	if m.deployService != nil {
+		m.deployService.config.Store(config)
+	}
+
+	m.mu.Lock()
+	for key := range m.lastCommit {
+		if !configuresRef(config, key) {
+			delete(m.lastCommit, key)
+		}
+	}
+	// A cached ETag would turn the first poll of a re-added repository into a 304 that never baselines
+	m.etags = make(map[string]string)
+	m.mu.Unlock()
+
+	// Wake the polling loop; a pending signal already covers this reload
+	select {
+	case m.reloads <- struct{}{}:
+	default:
+	}
+}
+
+// configuresRef reports whether a commit cache key belongs to a configured repository
+func configuresRef(config *Config, key string) bool {
+	for _, repo := range config.Repositories {
+		if strings.HasPrefix(key, refCacheKey(repo.Name, "")) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBaseline reports whether any branch or tag of the repository has a recorded commit
+func (m *MonitorService) hasBaseline(repoName string) bool {
+	prefix := refCacheKey(repoName, "")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for key := range m.lastCommit {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadConfig reloads and validates the config file and applies it to the running watcher
+// An invalid config is logged and the current one stays in effect.
+func (app *SentryApp) reloadConfig() bool {
+	AppLogger.Info("Reloading configuration from %s...", app.appConfig.ConfigPath)
+
+	config, err := LoadConfig(app.appConfig.ConfigPath, app.appConfig.configLoadOptions())
+	if err != nil {
+		AppLogger.ErrorS("Configuration reload failed, keeping the current configuration", "error", err)
+		return false
+	}
+
+	app.config = config
+	app.monitorService.Reload(config)
+	AppLogger.InfoS("Configuration reloaded", "repositories", len(config.Repositories))
+	return true
+}
+
+// getConfigCheckInterval gets how often watch checks the config file for changes, 0 when it does not
+func getConfigCheckInterval(config *Config) time.Duration {
+	return time.Duration(config.Global.ConfigCheckInterval) * time.Second
+}
+
+// configFileWatcher detects changes to the content of a config file, such as a remounted ConfigMap
+type configFileWatcher struct {
+	path string
+	sum  []byte // SHA-256 of the content seen last, nil when the file could not be read
+}
+
+// newConfigFileWatcher creates a watcher that treats the file's current content as seen
+func newConfigFileWatcher(path string) *configFileWatcher {
+	w := &configFileWatcher{path: path}
+	w.changed()
+	return w
+}
+
+// changed reports whether the file content differs from the last check, and remembers the new content
+// Content that fails to load is remembered too, so a broken file is reported once rather than every check.
+func (w *configFileWatcher) changed() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if bytes.Equal(w.sum, sum[:]) {
+		return false, nil
+	}
+	w.sum = sum[:]
+	return true, nil
+}
+
+// reloadIfConfigChanged reloads the config through the SIGHUP path when its file content changed
+func (app *SentryApp) reloadIfConfigChanged(watcher *configFileWatcher) bool {
+	changed, err := watcher.changed()
+	if err != nil {
+		AppLogger.WarnS("Failed to check configuration file for changes", "path", app.appConfig.ConfigPath, "error", err)
+		return false
+	}
+	if !changed {
+		return false
+	}
+	AppLogger.InfoS("Configuration file changed", "path", app.appConfig.ConfigPath)
+	return app.reloadConfig()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reloadTestRepo renders one repository entry of a reload test config
+func reloadTestRepo(name string) string {
+	return fmt.Sprintf(`
+  - name: %q
+    monitor:
+      repo_url: "https://github.com/test/%s"
+      branches: ["main"]
+      repo_type: "github"
+      auth:
+        token: "token"
+    deploy:
+      qa_repo_url: "https://gitlab.com/qa/repo"
+      qa_repo_branch: "main"
+      repo_type: "gitlab"
+      auth:
+        token: "token"
+      project_name: "reload-project"
+      commands:
+        - "echo test"
+`, name, name)
+}
+
+func TestReloadConfigMonitorsAddedRepository(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	configPath := filepath.Join(t.TempDir(), "sentry.yaml")
+	writeConfig := func(repos ...string) {
+		content := "polling_interval: 3600\nrepositories:" + strings.Join(repos, "")
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+
+	writeConfig(reloadTestRepo("existing"))
+	appConfig := &AppConfig{ConfigPath: configPath}
+	config, err := LoadConfig(configPath, appConfig.configLoadOptions())
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var fetchesMu sync.Mutex
+	fetches := make(map[string]int)
+	deployService := NewDeployService(config)
+	monitor := NewMonitorService(config, deployService)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		repoName := strings.Split(req.URL.Path, "/")[3] // /repos/test/<name>/commits/main
+		fetchesMu.Lock()
+		fetches[repoName]++
+		fetchesMu.Unlock()
+		return stubResponse(http.StatusOK, fmt.Sprintf(`{"sha":"%s-sha","commit":{"message":"Initial","author":{"name":"Alice"}}}`, repoName)), nil
+	})})
+	app := &SentryApp{config: config, monitorService: monitor, deployService: deployService, appConfig: appConfig}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- monitor.StartMonitoring(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	lastCommit := func(repoName string) string {
+		monitor.mu.RLock()
+		defer monitor.mu.RUnlock()
+		return monitor.lastCommit[refCacheKey(repoName, "main")]
+	}
+	waitForBaseline := func(repoName string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for lastCommit(repoName) == "" {
+			if time.Now().After(deadline) {
+				t.Fatalf("repository %s was never baselined", repoName)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitForBaseline("existing")
+
+	// An invalid config is rejected and the running one stays in effect
+	writeConfig(reloadTestRepo(""))
+	if app.reloadConfig() {
+		t.Fatal("reloadConfig() = true for an invalid config, want false")
+	}
+	if app.config != config || monitor.currentConfig() != config {
+		t.Error("invalid reload replaced the running config")
+	}
+
+	// A valid config with an added repository is applied to both services
+	writeConfig(reloadTestRepo("existing"), reloadTestRepo("added"))
+	if !app.reloadConfig() {
+		t.Fatal("reloadConfig() = false for a valid config, want true")
+	}
+	if got := len(monitor.currentConfig().Repositories); got != 2 {
+		t.Fatalf("monitor has %d repositories after reload, want 2", got)
+	}
+	if deployService.currentConfig() != monitor.currentConfig() {
+		t.Error("deploy service config was not swapped with the monitor config")
+	}
+
+	// The added repository is baselined right away instead of one polling interval later
+	waitForBaseline("added")
+	if got := lastCommit("added"); got != "added-sha" {
+		t.Errorf("added lastCommit = %q, want added-sha", got)
+	}
+
+	// The existing repository keeps its state and is not re-fetched
+	if got := lastCommit("existing"); got != "existing-sha" {
+		t.Errorf("existing lastCommit = %q, want existing-sha", got)
+	}
+	fetchesMu.Lock()
+	defer fetchesMu.Unlock()
+	if fetches["existing"] != 1 {
+		t.Errorf("existing repository fetched %d times, want 1", fetches["existing"])
+	}
+}
+
+func TestReloadIfConfigChanged(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	configPath := filepath.Join(t.TempDir(), "sentry.yaml")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	complete := "polling_interval: 3600\nrepositories:" + reloadTestRepo("existing") + reloadTestRepo("added")
+
+	writeConfig("polling_interval: 3600\nrepositories:" + reloadTestRepo("existing"))
+	appConfig := &AppConfig{ConfigPath: configPath}
+	config, err := LoadConfig(configPath, appConfig.configLoadOptions())
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	deployService := NewDeployService(config)
+	monitor := NewMonitorService(config, deployService)
+	app := &SentryApp{config: config, monitorService: monitor, deployService: deployService, appConfig: appConfig}
+	watcher := newConfigFileWatcher(configPath)
+
+	steps := []struct {
+		name       string
+		content    string // written before the check, "" to leave the file alone
+		wantReload bool
+		wantRepos  []string
+	}{
+		{"unchanged file", "", false, []string{"existing"}},
+		{"partially written file", complete[:len(complete)/2+40], false, []string{"existing"}},
+		{"completed write adds a repository", complete, true, []string{"existing", "added"}},
+		{"no change since the reload", "", false, []string{"existing", "added"}},
+	}
+
+	for _, step := range steps {
+		if step.content != "" {
+			writeConfig(step.content)
+		}
+		if got := app.reloadIfConfigChanged(watcher); got != step.wantReload {
+			t.Fatalf("%s: reloadIfConfigChanged() = %v, want %v", step.name, got, step.wantReload)
+		}
+
+		var repos []string
+		for _, repo := range monitor.currentConfig().Repositories {
+			repos = append(repos, repo.Name)
+		}
+		if strings.Join(repos, ",") != strings.Join(step.wantRepos, ",") {
+			t.Errorf("%s: monitored repositories = %v, want %v", step.name, repos, step.wantRepos)
+		}
+		if app.config != monitor.currentConfig() {
+			t.Errorf("%s: app config and monitor config differ", step.name)
+		}
+	}
+}
+
+func TestReloadForgetsRemovedRepositories(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{Repositories: []RepositoryConfig{{Name: "kept"}, {Name: "removed"}}}
+	monitor := NewMonitorService(config, nil)
+	monitor.lastCommit[refCacheKey("kept", "main")] = "kept-sha"
+	monitor.lastCommit[refCacheKey("removed", "main")] = "removed-sha"
+	monitor.lastCommit[refCacheKey("removed", "tag:v*")] = "v1.0.0"
+	monitor.etags[refCacheKey("removed", "main")] = `"v1"`
+
+	monitor.Reload(&Config{Repositories: []RepositoryConfig{{Name: "kept"}}})
+
+	if !monitor.hasBaseline("kept") {
+		t.Error("kept repository lost its commit state")
+	}
+	if monitor.hasBaseline("removed") {
+		t.Error("removed repository still has commit state")
+	}
+	if len(monitor.etags) != 0 {
+		t.Errorf("etags = %v, want cleared so re-added repositories baseline", monitor.etags)
+	}
+}
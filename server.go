@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StatusServer is the optional HTTP server exposing /healthz, /metrics and /status
+type StatusServer struct {
+	server *http.Server
+}
+
+// statusResponse is the JSON body served at /status
+type statusResponse struct {
+	RecentDeployments []DeploymentHistoryEntry `json:"recent_deployments"`
+}
+
+// NewStatusServer creates a status server listening on addr
+// /status is only served when deployService is set.
+func NewStatusServer(addr string, metrics *Metrics, deployService *DeployService) *StatusServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	if metrics != nil {
+		mux.Handle("/metrics", metrics.Handler())
+	}
+	if deployService != nil {
+		mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(statusResponse{RecentDeployments: deployService.RecentDeployments()}); err != nil {
+				AppLogger.WarnS("Failed to write status response", "error", err)
+			}
+		})
+	}
+
+	return &StatusServer{
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Handler returns the server's request router
+func (s *StatusServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Start serves requests in the background; listen errors are logged
+func (s *StatusServer) Start() {
+	AppLogger.InfoS("Starting status server", "addr", s.server.Addr)
+
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			AppLogger.ErrorS("Status server stopped", "addr", s.server.Addr, "error", err)
+		}
+	}()
+}
+
+// Shutdown gracefully stops the server
+func (s *StatusServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
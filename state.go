@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// exportStateAction prints the commit state file as JSON, keyed by "<repo>:<branch>" or "<repo>:tag:<pattern>"
+// Entries of repositories no longer configured are left out, as when the state is loaded.
+func (app *SentryApp) exportStateAction(w io.Writer) error {
+	if err := app.monitorService.LoadCommitState(getCommitStatePath(app.config)); err != nil {
+		return err
+	}
+
+	app.monitorService.mu.RLock()
+	data, err := json.MarshalIndent(app.monitorService.lastCommit, "", "  ")
+	app.monitorService.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit state: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// importStateAction replaces the commit state file with the state read from -state ("-" for stdin)
+// Keys that do not name a monitored branch or tag pattern of the current config are skipped with a warning.
+func (app *SentryApp) importStateAction(stdin io.Reader) error {
+	if app.appConfig.StatePath == "" {
+		return fmt.Errorf("-state is required for import-state")
+	}
+
+	var data []byte
+	var err error
+	if app.appConfig.StatePath == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(app.appConfig.StatePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state to import: %w", err)
+	}
+
+	state := make(map[string]string)
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode state to import: %w", err)
+	}
+
+	imported := make(map[string]string)
+	for _, key := range sortedStateKeys(state) {
+		sha := strings.TrimSpace(state[key])
+		switch {
+		case sha == "":
+			AppLogger.WarnS("Skipping state entry without a commit", "key", key)
+		case !stateKeyConfigured(app.config, key):
+			AppLogger.WarnS("Skipping state entry for an unmonitored repository branch or tag", "key", key)
+		default:
+			imported[key] = sha
+		}
+	}
+
+	app.monitorService.mu.Lock()
+	app.monitorService.lastCommit = imported
+	app.monitorService.mu.Unlock()
+
+	statePath := getCommitStatePath(app.config)
+	if err := app.monitorService.SaveCommitState(statePath); err != nil {
+		return err
+	}
+
+	AppLogger.InfoS("Commit state imported",
+		"path", statePath,
+		"imported", len(imported),
+		"skipped", len(state)-len(imported))
+	return nil
+}
+
+// stateKeyConfigured reports whether a commit state key names a branch or tag pattern the config monitors
+// Repository names may contain ':', so every repository whose key prefix matches is considered.
+func stateKeyConfigured(config *Config, key string) bool {
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		ref, found := strings.CutPrefix(key, refCacheKey(repo.Name, ""))
+		if !found || ref == "" {
+			continue
+		}
+		if pattern, isTag := strings.CutPrefix(ref, tagRefPrefix); isTag {
+			if slices.Contains(repo.Monitor.Tags, pattern) {
+				return true
+			}
+		} else if monitorsBranch(&repo.Monitor, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedStateKeys returns the keys of a commit state map in a stable order for logging
+func sortedStateKeys(state map[string]string) []string {
+	keys := make([]string, 0, len(state))
+	for key := range state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
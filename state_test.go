@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateExportImportRoundTrip(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+	config.Repositories[0].Monitor.Branches = []string{"main", "release-.*"}
+	config.Repositories[0].Monitor.Tags = []string{"v*"}
+	config.Global.CommitStateFile = filepath.Join(t.TempDir(), "commits.json")
+
+	newApp := func(statePath string) *SentryApp {
+		deployService := NewDeployService(config)
+		return &SentryApp{
+			config:         config,
+			monitorService: NewMonitorService(config, deployService),
+			deployService:  deployService,
+			appConfig:      &AppConfig{StatePath: statePath},
+		}
+	}
+
+	want := map[string]string{
+		refCacheKey("drain-repo", "main"):            "sha-main",
+		refCacheKey("drain-repo", "release-1.2"):     "sha-release",
+		refCacheKey("drain-repo", tagRefPrefix+"v*"): "sha-tag",
+	}
+	exported := filepath.Join(t.TempDir(), "exported.json")
+	data, _ := json.Marshal(want)
+	if err := os.WriteFile(config.Global.CommitStateFile, data, 0644); err != nil {
+		t.Fatalf("failed to write commit state: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := newApp("").exportStateAction(&out); err != nil {
+		t.Fatalf("exportStateAction() error = %v", err)
+	}
+	if err := os.WriteFile(exported, out.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write exported state: %v", err)
+	}
+
+	// Importing replaces whatever the state file holds
+	if err := os.WriteFile(config.Global.CommitStateFile, []byte(`{"drain-repo:main":"stale"}`), 0644); err != nil {
+		t.Fatalf("failed to overwrite commit state: %v", err)
+	}
+	if err := newApp(exported).importStateAction(strings.NewReader("")); err != nil {
+		t.Fatalf("importStateAction() error = %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := newApp("").exportStateAction(&got); err != nil {
+		t.Fatalf("exportStateAction() after import error = %v", err)
+	}
+	var roundTripped map[string]string
+	if err := json.Unmarshal(got.Bytes(), &roundTripped); err != nil {
+		t.Fatalf("exported state is not JSON: %v", err)
+	}
+	if len(roundTripped) != len(want) {
+		t.Errorf("round-tripped state = %v, want %v", roundTripped, want)
+	}
+	for key, sha := range want {
+		if roundTripped[key] != sha {
+			t.Errorf("state[%q] = %q, want %q", key, roundTripped[key], sha)
+		}
+	}
+}
+
+func TestImportState(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tests := []struct {
+		name      string
+		statePath string // "-" reads input from stdin
+		input     string
+		wantErr   bool
+		wantSaved map[string]string
+	}{
+		{
+			name:      "orphans and empty commits are skipped",
+			statePath: "-",
+			input: `{"drain-repo:main":"sha-main","drain-repo:develop":"sha-dev",` +
+				`"removed-repo:main":"gone","drain-repo:tag:v*":"sha-tag","drain-repo:tag:nightly":"x","drain-repo:main-copy":""}`,
+			wantSaved: map[string]string{"drain-repo:main": "sha-main", "drain-repo:tag:v*": "sha-tag"},
+		},
+		{
+			name:    "state path required",
+			wantErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			statePath: "-",
+			input:     `["drain-repo:main"]`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newDrainTestConfig(t, []CommandSpec{{Run: "true"}})
+			config.Repositories[0].Monitor.Branches = []string{"main"}
+			config.Repositories[0].Monitor.Tags = []string{"v*"}
+			config.Global.CommitStateFile = filepath.Join(t.TempDir(), "commits.json")
+
+			deployService := NewDeployService(config)
+			app := &SentryApp{
+				config:         config,
+				monitorService: NewMonitorService(config, deployService),
+				deployService:  deployService,
+				appConfig:      &AppConfig{StatePath: tt.statePath},
+			}
+
+			err := app.importStateAction(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("importStateAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := os.Stat(config.Global.CommitStateFile); err == nil {
+					t.Error("commit state written despite failed import")
+				}
+				return
+			}
+
+			data, err := os.ReadFile(config.Global.CommitStateFile)
+			if err != nil {
+				t.Fatalf("commit state not saved: %v", err)
+			}
+			var saved map[string]string
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatalf("commit state is not JSON: %v", err)
+			}
+			if len(saved) != len(tt.wantSaved) {
+				t.Errorf("saved state = %v, want %v", saved, tt.wantSaved)
+			}
+			for key, sha := range tt.wantSaved {
+				if saved[key] != sha {
+					t.Errorf("saved[%q] = %q, want %q", key, saved[key], sha)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// BranchStatus is the latest known commit of one monitored repository branch
+type BranchStatus struct {
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	SHA       string    `json:"sha,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// CollectStatus does a one-shot lookup of the latest commit on every monitored branch
+// It never triggers deployments; unreachable branches are reported with Error set.
+func (m *MonitorService) CollectStatus() []BranchStatus {
+	var statuses []BranchStatus
+
+	repos := m.currentConfig().Repositories
+	for i := range repos {
+		repo := &repos[i]
+
+		branches, err := m.expandBranches(&repo.Monitor)
+		if err != nil {
+			statuses = append(statuses, BranchStatus{Repo: repo.GetDisplayName(), Branch: "*", Error: err.Error()})
+			continue
+		}
+
+		for _, branch := range branches {
+			status := BranchStatus{Repo: repo.GetDisplayName(), Branch: branch}
+			commit, err := m.GetLatestCommit(&repo.Monitor, branch)
+			if err != nil {
+				status.Error = err.Error()
+			} else {
+				status.SHA = commit.SHA
+				status.Author = commit.Author
+				status.Timestamp = commit.Timestamp
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses
+}
+
+// writeStatusReport prints branch statuses as a table or JSON
+func writeStatusReport(w io.Writer, statuses []BranchStatus, output string) error {
+	if output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statuses)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REPO\tBRANCH\tSHA\tAUTHOR\tTIMESTAMP")
+	for _, status := range statuses {
+		if status.Error != "" {
+			fmt.Fprintf(table, "%s\t%s\t-\t-\tERROR: %s\n", status.Repo, status.Branch, status.Error)
+			continue
+		}
+
+		timestamp := "-"
+		if !status.Timestamp.IsZero() {
+			timestamp = status.Timestamp.Format(time.RFC3339)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", status.Repo, status.Branch, shortSHA(status.SHA), status.Author, timestamp)
+	}
+	return table.Flush()
+}
+
+// statusAction reports the latest commit of every monitored branch and fails when any is unreachable
+func (app *SentryApp) statusAction(w io.Writer) error {
+	statuses := app.monitorService.CollectStatus()
+	if err := writeStatusReport(w, statuses, app.appConfig.Output); err != nil {
+		return fmt.Errorf("failed to write status report: %w", err)
+	}
+
+	unreachable := 0
+	for _, status := range statuses {
+		if status.Error != "" {
+			unreachable++
+		}
+	}
+	if unreachable > 0 {
+		return fmt.Errorf("%d repository branch(es) unreachable", unreachable)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// newStatusTestApp returns an app monitoring one reachable and one failing GitHub repository
+func newStatusTestApp(output string) *SentryApp {
+	noRetries := 0
+	config := &Config{
+		Global: GlobalConfig{MaxRetries: &noRetries},
+		Repositories: []RepositoryConfig{
+			{
+				Name: "healthy-repo",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/owner/healthy",
+					Branches: []string{"main"},
+					RepoType: "github",
+				},
+			},
+			{
+				Name: "missing-repo",
+				Monitor: MonitorConfig{
+					RepoURL:  "https://github.com/owner/missing",
+					Branches: []string{"develop"},
+					RepoType: "github",
+				},
+			},
+		},
+	}
+
+	monitorService := NewMonitorService(config, nil)
+	monitorService.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "/missing/") {
+			return stubResponse(http.StatusNotFound, `{"message":"Not Found"}`), nil
+		}
+		return stubResponse(http.StatusOK, `{"sha":"0123456789abcdef","commit":{"author":{"name":"Alice","date":"2024-05-01T10:00:00Z"}}}`), nil
+	})})
+
+	return &SentryApp{
+		config:         config,
+		monitorService: monitorService,
+		appConfig:      &AppConfig{Action: "status", Output: output},
+	}
+}
+
+func TestStatusAction(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app := newStatusTestApp("text")
+
+	var out bytes.Buffer
+	err := app.statusAction(&out)
+	if err == nil || !strings.Contains(err.Error(), "1 repository branch(es) unreachable") {
+		t.Errorf("statusAction() error = %v, want one unreachable branch", err)
+	}
+
+	text := out.String()
+	for _, want := range []string{"REPO", "healthy-repo", "main", "01234567", "Alice", "2024-05-01T10:00:00Z", "missing-repo", "develop", "ERROR: "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("status output = %q, want it to contain %q", text, want)
+		}
+	}
+}
+
+func TestStatusActionJSON(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	app := newStatusTestApp("json")
+
+	var out bytes.Buffer
+	if err := app.statusAction(&out); err == nil {
+		t.Error("statusAction() error = nil, want unreachable branch failure")
+	}
+
+	var statuses []BranchStatus
+	if err := json.Unmarshal(out.Bytes(), &statuses); err != nil {
+		t.Fatalf("status output is not JSON: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("len(statuses) = %d, want 2", len(statuses))
+	}
+	if statuses[0].SHA != "0123456789abcdef" || statuses[0].Author != "Alice" || statuses[0].Error != "" {
+		t.Errorf("statuses[0] = %+v, want healthy main commit", statuses[0])
+	}
+	if statuses[1].Repo != "missing-repo" || statuses[1].Error == "" {
+		t.Errorf("statuses[1] = %+v, want missing-repo error", statuses[1])
+	}
+}
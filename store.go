@@ -0,0 +1,182 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver (works with CGO_ENABLED=0)
+)
+
+// DeployRecord represents a persisted deployment result row
+type DeployRecord struct {
+	ID        int64     `json:"id"`
+	RepoName  string    `json:"repo_name"`
+	GroupName string    `json:"group_name,omitempty"`
+	CommitSHA string    `json:"commit_sha,omitempty"`
+	Success   bool      `json:"success"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GroupDeployRecord represents a persisted group deployment result row
+type GroupDeployRecord struct {
+	ID        int64     `json:"id"`
+	GroupName string    `json:"group_name"`
+	Strategy  string    `json:"strategy"`
+	Success   bool      `json:"success"`
+	TotalTime string    `json:"total_time"`
+	RepoCount int       `json:"repo_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResultStore persists deployment results to a SQLite database
+type ResultStore struct {
+	db *sql.DB
+}
+
+// resultStoreSchema creates the deployment history tables
+const resultStoreSchema = `
+CREATE TABLE IF NOT EXISTS deploy_results (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	repo_name  TEXT    NOT NULL,
+	group_name TEXT    NOT NULL DEFAULT '',
+	commit_sha TEXT    NOT NULL DEFAULT '',
+	success    INTEGER NOT NULL,
+	duration   TEXT    NOT NULL DEFAULT '',
+	error      TEXT    NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_deploy_results_created_at ON deploy_results(created_at);
+CREATE TABLE IF NOT EXISTS group_deploy_results (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	group_name TEXT    NOT NULL,
+	strategy   TEXT    NOT NULL DEFAULT '',
+	success    INTEGER NOT NULL,
+	total_time TEXT    NOT NULL DEFAULT '',
+	repo_count INTEGER NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL
+);
+`
+
+// OpenResultStore opens (and initializes if needed) the SQLite database at dbPath
+// Use ":memory:" for a transient in-memory database
+func OpenResultStore(dbPath string) (*ResultStore, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result database: %w", err)
+	}
+
+	// SQLite allows a single writer; this also keeps ":memory:" databases on one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(resultStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize result database schema: %w", err)
+	}
+
+	return &ResultStore{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *ResultStore) Close() error {
+	return s.db.Close()
+}
+
+// RecordDeploy persists a single repository deployment result
+func (s *ResultStore) RecordDeploy(record DeployRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO deploy_results (repo_name, group_name, commit_sha, success, duration, error, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.RepoName, record.GroupName, record.CommitSHA, record.Success,
+		record.Duration, record.Error, record.CreatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to record deploy result: %w", err)
+	}
+	return nil
+}
+
+// RecordGroupDeploy persists a group deployment result
+func (s *ResultStore) RecordGroupDeploy(record GroupDeployRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO group_deploy_results (group_name, strategy, success, total_time, repo_count, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		record.GroupName, record.Strategy, record.Success, record.TotalTime,
+		record.RepoCount, record.CreatedAt.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to record group deploy result: %w", err)
+	}
+	return nil
+}
+
+// RecentFailures returns failed repository deployments recorded since the given time, newest first
+func (s *ResultStore) RecentFailures(since time.Time) ([]DeployRecord, error) {
+	return s.queryDeploys(
+		`SELECT id, repo_name, group_name, commit_sha, success, duration, error, created_at
+		 FROM deploy_results WHERE success = 0 AND created_at >= ? ORDER BY created_at DESC, id DESC`,
+		since.UnixNano())
+}
+
+// GroupFailures returns failed repository deployments within a group since the given time, newest first
+func (s *ResultStore) GroupFailures(groupName string, since time.Time) ([]DeployRecord, error) {
+	return s.queryDeploys(
+		`SELECT id, repo_name, group_name, commit_sha, success, duration, error, created_at
+		 FROM deploy_results WHERE success = 0 AND group_name = ? AND created_at >= ? ORDER BY created_at DESC, id DESC`,
+		groupName, since.UnixNano())
+}
+
+// RecentGroupDeploys returns group deployments recorded since the given time, newest first
+func (s *ResultStore) RecentGroupDeploys(since time.Time) ([]GroupDeployRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, group_name, strategy, success, total_time, repo_count, created_at
+		 FROM group_deploy_results WHERE created_at >= ? ORDER BY created_at DESC, id DESC`,
+		since.UnixNano())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query group deploy results: %w", err)
+	}
+	defer rows.Close()
+
+	var records []GroupDeployRecord
+	for rows.Next() {
+		var record GroupDeployRecord
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.GroupName, &record.Strategy, &record.Success,
+			&record.TotalTime, &record.RepoCount, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group deploy result: %w", err)
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// queryDeploys runs a deploy_results query and scans the rows into records
+func (s *ResultStore) queryDeploys(query string, args ...interface{}) ([]DeployRecord, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deploy results: %w", err)
+	}
+	defer rows.Close()
+
+	var records []DeployRecord
+	for rows.Next() {
+		var record DeployRecord
+		var createdAt int64
+		if err := rows.Scan(&record.ID, &record.RepoName, &record.GroupName, &record.CommitSHA,
+			&record.Success, &record.Duration, &record.Error, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deploy result: %w", err)
+		}
+		record.CreatedAt = time.Unix(0, createdAt)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
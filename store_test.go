@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResultStoreRecentFailures(t *testing.T) {
+	store, err := OpenResultStore(":memory:")
+	if err != nil {
+		t.Fatalf("OpenResultStore() error = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	records := []DeployRecord{
+		{RepoName: "old-failure", GroupName: "group-a", Success: false, Error: "boom", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{RepoName: "recent-success", GroupName: "group-a", Success: true, Duration: "1s", CreatedAt: now.Add(-time.Hour)},
+		{RepoName: "recent-failure-a", GroupName: "group-a", CommitSHA: "abc123", Success: false, Error: "apply failed", CreatedAt: now.Add(-2 * time.Hour)},
+		{RepoName: "recent-failure-b", GroupName: "group-b", Success: false, Error: "clone failed", CreatedAt: now.Add(-30 * time.Minute)},
+	}
+	for _, record := range records {
+		if err := store.RecordDeploy(record); err != nil {
+			t.Fatalf("RecordDeploy() error = %v", err)
+		}
+	}
+
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+
+	failures, err := store.RecentFailures(lastWeek)
+	if err != nil {
+		t.Fatalf("RecentFailures() error = %v", err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("RecentFailures() returned %d records, want 2", len(failures))
+	}
+	// Newest first
+	if failures[0].RepoName != "recent-failure-b" || failures[1].RepoName != "recent-failure-a" {
+		t.Errorf("RecentFailures() order = [%s, %s], want [recent-failure-b, recent-failure-a]",
+			failures[0].RepoName, failures[1].RepoName)
+	}
+	if failures[1].CommitSHA != "abc123" || failures[1].Error != "apply failed" {
+		t.Errorf("RecentFailures() record fields not persisted: %+v", failures[1])
+	}
+
+	groupFailures, err := store.GroupFailures("group-a", lastWeek)
+	if err != nil {
+		t.Fatalf("GroupFailures() error = %v", err)
+	}
+	if len(groupFailures) != 1 || groupFailures[0].RepoName != "recent-failure-a" {
+		t.Errorf("GroupFailures() = %+v, want only recent-failure-a", groupFailures)
+	}
+}
+
+func TestResultStoreGroupDeploys(t *testing.T) {
+	store, err := OpenResultStore(":memory:")
+	if err != nil {
+		t.Fatalf("OpenResultStore() error = %v", err)
+	}
+	defer store.Close()
+
+	if err := store.RecordGroupDeploy(GroupDeployRecord{
+		GroupName: "test-group",
+		Strategy:  "parallel",
+		Success:   false,
+		TotalTime: "3s",
+		RepoCount: 2,
+	}); err != nil {
+		t.Fatalf("RecordGroupDeploy() error = %v", err)
+	}
+
+	groups, err := store.RecentGroupDeploys(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("RecentGroupDeploys() error = %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("RecentGroupDeploys() returned %d records, want 1", len(groups))
+	}
+	if groups[0].GroupName != "test-group" || groups[0].RepoCount != 2 || groups[0].Success {
+		t.Errorf("RecentGroupDeploys() record = %+v", groups[0])
+	}
+}
+
+func TestDeployServiceRecordsResults(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	config := &Config{
+		Global: GlobalConfig{
+			TmpDir:  "/tmp/test-sentry",
+			Cleanup: true,
+		},
+		Groups: map[string]GroupConfig{
+			"test-group": {
+				ExecutionStrategy: "sequential",
+				MaxParallel:       1,
+				ContinueOnError:   true,
+				GlobalTimeout:     60,
+			},
+		},
+	}
+
+	service := NewDeployService(config)
+
+	// Without a store, queries report that persistence is disabled
+	if _, err := service.RecentFailures(time.Now()); err == nil {
+		t.Error("RecentFailures() should error when no result store is configured")
+	}
+
+	if err := service.EnableResultStore(":memory:"); err != nil {
+		t.Fatalf("EnableResultStore() error = %v", err)
+	}
+	defer service.Close()
+
+	groupConfig := config.Groups["test-group"]
+	service.DeployGroup(context.Background(), "test-group", []string{"missing-repo"}, &groupConfig, nil)
+
+	failures, err := service.RecentFailures(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("RecentFailures() error = %v", err)
+	}
+	if len(failures) != 1 || failures[0].RepoName != "missing-repo" {
+		t.Fatalf("RecentFailures() = %+v, want a single missing-repo failure", failures)
+	}
+
+	groups, err := service.resultStore.RecentGroupDeploys(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("RecentGroupDeploys() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].GroupName != "test-group" {
+		t.Errorf("RecentGroupDeploys() = %+v, want a single test-group record", groups)
+	}
+}
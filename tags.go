@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// tagRefPrefix namespaces tag patterns in change-detection keys and trigger sources.
+// Git ref names cannot contain ':', so a tag ref never collides with a branch name.
+const tagRefPrefix = "tag:"
+
+// TagInfo represents a repository tag and the commit it points to
+type TagInfo struct {
+	Name string `json:"name"`
+	SHA  string `json:"sha"`
+}
+
+// tagRef returns the ref identifying a monitored tag pattern
+func tagRef(pattern string) string {
+	return tagRefPrefix + pattern
+}
+
+// refCacheKey returns the change-detection key for a branch or tagRef of a repository
+func refCacheKey(repoName string, ref string) string {
+	return fmt.Sprintf("%s:%s", repoName, ref)
+}
+
+// checkRepositoryTags checks every configured tag pattern and returns the tag refs whose latest tag changed
+func (m *MonitorService) checkRepositoryTags(repo *RepositoryConfig) ([]string, error) {
+	if len(repo.Monitor.Tags) == 0 {
+		return nil, nil
+	}
+
+	tags, err := m.listTags(&repo.Monitor)
+	m.metrics.RecordRepoCheck(repo.Name, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var changedRefs []string
+	for _, configured := range repo.Monitor.Tags {
+		pattern, err := compileBranchPattern(configured)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag pattern %q: %w", configured, err)
+		}
+
+		latest := latestMatchingTag(tags, pattern)
+		if latest == nil {
+			AppLogger.DebugS("Tag pattern matched no tags",
+				"repo", repo.GetDisplayName(),
+				"pattern", configured)
+			continue
+		}
+
+		if m.checkRepositoryTag(repo, configured, latest) {
+			changedRefs = append(changedRefs, tagRef(configured))
+		}
+	}
+	return changedRefs, nil
+}
+
+// checkRepositoryTag records the latest tag for a pattern and reports whether a new tag appeared
+func (m *MonitorService) checkRepositoryTag(repo *RepositoryConfig, pattern string, latest *TagInfo) bool {
+	cacheKey := refCacheKey(repo.Name, tagRef(pattern))
+
+	m.mu.Lock()
+	lastTag, exists := m.lastCommit[cacheKey]
+	m.lastCommit[cacheKey] = latest.Name
+	m.mu.Unlock()
+
+	if !exists {
+		AppLogger.InfoS("Initial tag recorded",
+			"repo", repo.GetDisplayName(),
+			"pattern", pattern,
+			"tag", latest.Name)
+		return false
+	}
+
+	// A deleted tag can make an older one the latest; that re-baselines without deploying
+	if compareTagNames(latest.Name, lastTag) <= 0 {
+		return false
+	}
+
+	AppLogger.InfoS("New tag detected",
+		"repo", repo.GetDisplayName(),
+		"pattern", pattern,
+		"old_tag", lastTag,
+		"new_tag", latest.Name,
+		"sha", shortSHA(latest.SHA))
+
+	m.metrics.RecordCommitChange(repo.Name)
+
+	m.setPendingTrigger(repo.Name, tagRef(pattern), DeployTrigger{CommitInfo: CommitInfo{SHA: latest.SHA}})
+	return true
+}
+
+// latestMatchingTag returns the highest matching tag in natural version order, or nil
+func latestMatchingTag(tags []TagInfo, pattern *regexp.Regexp) *TagInfo {
+	var latest *TagInfo
+	for i := range tags {
+		if !pattern.MatchString(tags[i].Name) {
+			continue
+		}
+		if latest == nil || compareTagNames(tags[i].Name, latest.Name) > 0 {
+			latest = &tags[i]
+		}
+	}
+	return latest
+}
+
+// compareTagNames compares tag names treating digit runs as numbers, so v1.10 sorts after v1.9
+func compareTagNames(a, b string) int {
+	for a != "" && b != "" {
+		aChunk, aRest := splitTagChunk(a)
+		bChunk, bRest := splitTagChunk(b)
+
+		if isDigitChunk(aChunk) && isDigitChunk(bChunk) {
+			aNum := strings.TrimLeft(aChunk, "0")
+			bNum := strings.TrimLeft(bChunk, "0")
+			if len(aNum) != len(bNum) {
+				return compareInts(len(aNum), len(bNum))
+			}
+			if c := strings.Compare(aNum, bNum); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(aChunk, bChunk); c != 0 {
+			return c
+		}
+
+		a, b = aRest, bRest
+	}
+	return compareInts(len(a), len(b))
+}
+
+// splitTagChunk splits off the leading run of digits or non-digits
+func splitTagChunk(s string) (string, string) {
+	digits := unicode.IsDigit(rune(s[0]))
+	i := 1
+	for i < len(s) && unicode.IsDigit(rune(s[i])) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigitChunk(chunk string) bool {
+	return chunk != "" && unicode.IsDigit(rune(chunk[0]))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// listTags returns every tag of a repository using the provider's tag-list API
+func (m *MonitorService) listTags(monitor *MonitorConfig) ([]TagInfo, error) {
+	switch monitor.RepoType {
+	case "github":
+		return m.listGitHubTags(monitor)
+	default:
+		return nil, fmt.Errorf("tag monitoring is not supported for %s repositories", monitor.RepoType)
+	}
+}
+
+// listGitHubTags walks the paginated GitHub tag-list API
+func (m *MonitorService) listGitHubTags(monitor *MonitorConfig) ([]TagInfo, error) {
+	repoAPIURL, err := githubRepoAPIURL(monitor)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := m.monitorAuth(monitor)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []TagInfo
+	for page := 1; page <= maxBranchPages; page++ {
+		url := fmt.Sprintf("%s/tags?per_page=%d&page=%d", repoAPIURL, branchPageSize, page)
+		req, err := m.newAPIRequest("GET", url)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", auth.Token))
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+		resp, err := m.doAPIRequest(req)
+		if err != nil {
+			return nil, classifyTransportError(fmt.Errorf("hTTP request failed: %w", err))
+		}
+
+		if rateLimitErr := parseGitHubRateLimit(resp); rateLimitErr != nil {
+			resp.Body.Close()
+			return nil, rateLimitErr
+		}
+
+		// Limit response body size to prevent memory issues
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{Provider: "gitHub", StatusCode: resp.StatusCode, Body: string(body), RequestID: req.Header.Get(requestIDHeader)}
+		}
+
+		var pageTags []struct {
+			Name   string `json:"name"`
+			Commit struct {
+				SHA string `json:"sha"`
+			} `json:"commit"`
+		}
+		if err := json.Unmarshal(body, &pageTags); err != nil {
+			return nil, fmt.Errorf("failed to parse tag list: %w", err)
+		}
+
+		for _, tag := range pageTags {
+			tags = append(tags, TagInfo{Name: tag.Name, SHA: tag.Commit.SHA})
+		}
+
+		if len(pageTags) < branchPageSize {
+			break
+		}
+	}
+
+	return tags, nil
+}
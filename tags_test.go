@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRefCacheKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		ref      string
+		expected string
+	}{
+		{name: "branch", repoName: "repo", ref: "main", expected: "repo:main"},
+		{name: "tag pattern", repoName: "repo", ref: tagRef("v.*"), expected: "repo:tag:v.*"},
+		{name: "branch named like tag", repoName: "repo", ref: "tag", expected: "repo:tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refCacheKey(tt.repoName, tt.ref); got != tt.expected {
+				t.Errorf("refCacheKey(%q, %q) = %q, want %q", tt.repoName, tt.ref, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareTagNames(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "v1.10.0", b: "v1.9.0", expected: 1},
+		{a: "v1.2.0", b: "v1.2.0", expected: 0},
+		{a: "v1.2", b: "v1.2.1", expected: -1},
+		{a: "v2.0.0", b: "v10.0.0", expected: -1},
+		{a: "v1.02", b: "v1.2", expected: 0},
+		{a: "release-b", b: "release-a", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareTagNames(tt.a, tt.b); got != tt.expected {
+				t.Errorf("compareTagNames(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMonitorCheckRepositoryTags(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	tagLists := []string{
+		`[{"name":"v1.9.0","commit":{"sha":"sha-190"}},{"name":"nightly","commit":{"sha":"sha-n"}}]`,
+		`[{"name":"v1.9.0","commit":{"sha":"sha-190"}},{"name":"v1.10.0","commit":{"sha":"sha-1100"}}]`,
+		`[{"name":"v1.9.0","commit":{"sha":"sha-190"}}]`,
+	}
+	call := 0
+
+	deployService := NewDeployService(&Config{})
+	monitor := NewMonitorService(&Config{}, deployService)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if !strings.HasSuffix(req.URL.Path, "/repos/owner/repo/tags") {
+			t.Errorf("unexpected request path %s", req.URL.Path)
+		}
+		body := tagLists[call]
+		call++
+		return stubResponse(http.StatusOK, body), nil
+	})})
+
+	repo := &RepositoryConfig{
+		Name: "tagged-repo",
+		Monitor: MonitorConfig{
+			RepoURL:  "https://github.com/owner/repo",
+			RepoType: "github",
+			Tags:     []string{`v[0-9.]+`},
+		},
+	}
+
+	// A branch sharing the cache must not be confused with the tag pattern state
+	monitor.lastCommit[refCacheKey(repo.Name, "v[0-9.]+")] = "branch-sha"
+
+	changed, err := monitor.checkRepositoryTags(repo)
+	if err != nil || len(changed) != 0 {
+		t.Fatalf("first checkRepositoryTags() = %v, %v; want baseline without change", changed, err)
+	}
+	if got := monitor.lastCommit["tagged-repo:tag:v[0-9.]+"]; got != "v1.9.0" {
+		t.Errorf("baseline tag = %q, want v1.9.0", got)
+	}
+
+	changed, err = monitor.checkRepositoryTags(repo)
+	if err != nil || len(changed) != 1 || changed[0] != "tag:v[0-9.]+" {
+		t.Fatalf("second checkRepositoryTags() = %v, %v; want [tag:v[0-9.]+]", changed, err)
+	}
+	if got := monitor.takePendingTriggers(repo.Name, changed)["tag:v[0-9.]+"]; got == nil || got.SHA != "sha-1100" || got.Branch != "" {
+		t.Errorf("pending trigger = %+v, want sha-1100 without a branch", got)
+	}
+
+	// Deleting the newest tag re-baselines without triggering a deploy of an older tag
+	changed, err = monitor.checkRepositoryTags(repo)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("third checkRepositoryTags() = %v, %v; want no change", changed, err)
+	}
+
+	if got := monitor.lastCommit[refCacheKey(repo.Name, "v[0-9.]+")]; got != "branch-sha" {
+		t.Errorf("branch cache entry = %q, want it untouched by tag checks", got)
+	}
+}
+
+func TestMonitorListTagsUnsupportedProvider(t *testing.T) {
+	monitor := NewMonitorService(&Config{}, nil)
+
+	_, err := monitor.listTags(&MonitorConfig{RepoURL: "https://gitlab.com/owner/repo", RepoType: "gitlab"})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("listTags() error = %v, want unsupported provider", err)
+	}
+}
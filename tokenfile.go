@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readTokenFile reads a token mounted as a file, e.g. a Kubernetes secret, without surrounding whitespace
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// resolveTokenFiles loads the token of every auth configured with token_file into its Token
+// Validation already ensured token and token_file are not both set, so an inline token is never replaced.
+func resolveTokenFiles(config *Config) error {
+	for i := range config.Repositories {
+		repo := &config.Repositories[i]
+		for _, auth := range []*AuthConfig{&repo.Monitor.Auth, &repo.Deploy.Auth} {
+			if auth.TokenFile == "" || auth.Token != "" {
+				continue
+			}
+			token, err := readTokenFile(auth.TokenFile)
+			if err != nil {
+				return fmt.Errorf("repository %s: %w", repo.Name, err)
+			}
+			auth.Token = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigTokenFile(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "github-token")
+	if err := os.WriteFile(tokenFile, []byte("  gh-file-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	configPath := filepath.Join(dir, "sentry.yaml")
+	configContent := `
+polling_interval: 60
+repositories:
+  - name: "file-token-repo"
+    monitor:
+      repo_url: "https://github.com/owner/repo"
+      branches: ["main"]
+      repo_type: "github"
+      auth:
+        token_file: "` + tokenFile + `"
+    deploy:
+      qa_repo_url: "https://gitlab.com/qa/repo"
+      qa_repo_branch: "main"
+      repo_type: "gitlab"
+      auth:
+        token: "inline-token"
+      project_name: "file-token-project"
+      commands:
+        - "echo test"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath, ConfigLoadOptions{EnvFile: filepath.Join(dir, ".env.missing")})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	repo := &config.Repositories[0]
+	if repo.Monitor.Auth.Token != "gh-file-token" {
+		t.Errorf("monitor token = %q, want the trimmed token file content", repo.Monitor.Auth.Token)
+	}
+	if repo.Deploy.Auth.Token != "inline-token" {
+		t.Errorf("deploy token = %q, want the inline token unchanged", repo.Deploy.Auth.Token)
+	}
+
+	// The token read from the file authenticates provider API calls
+	var gotAuth string
+	monitor := NewMonitorService(config, nil)
+	monitor.SetHTTPClient(&http.Client{Transport: stubRoundTripper(func(req *http.Request) (*http.Response, error) {
+		gotAuth = req.Header.Get("Authorization")
+		return stubResponse(http.StatusOK, `{"sha":"abc123","commit":{"message":"m","author":{"name":"a","date":"2024-05-01T12:30:00Z"}}}`), nil
+	})})
+	if _, err := monitor.GetLatestCommit(&repo.Monitor, "main"); err != nil {
+		t.Fatalf("GetLatestCommit() error = %v", err)
+	}
+	if gotAuth != "token gh-file-token" {
+		t.Errorf("Authorization = %q, want the token from the file", gotAuth)
+	}
+}
+
+func TestValidateAuthConfigTokenFile(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	dir := t.TempDir()
+	validFile := filepath.Join(dir, "token")
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(validFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	if err := os.WriteFile(emptyFile, []byte(" \n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		auth     AuthConfig
+		wantPath string
+		wantErr  string
+	}{
+		{name: "inline token", auth: AuthConfig{Token: "secret"}},
+		{name: "token file", auth: AuthConfig{TokenFile: validFile}},
+		{name: "neither", auth: AuthConfig{}, wantPath: "auth.token", wantErr: "cannot be empty"},
+		{name: "both", auth: AuthConfig{Token: "secret", TokenFile: validFile}, wantPath: "auth.token_file", wantErr: "cannot be combined with token"},
+		{name: "missing file", auth: AuthConfig{TokenFile: filepath.Join(dir, "missing")}, wantPath: "auth.token_file", wantErr: "failed to read token file"},
+		{name: "empty file", auth: AuthConfig{TokenFile: emptyFile}, wantPath: "auth.token_file", wantErr: "is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateAuthConfig(&tt.auth, "auth", "github")
+			if tt.wantErr == "" {
+				if len(errs) != 0 {
+					t.Errorf("validateAuthConfig() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || errs[0].Path != tt.wantPath || !strings.Contains(errs[0].Message, tt.wantErr) {
+				t.Errorf("validateAuthConfig() = %v, want %s containing %q", errs, tt.wantPath, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// proxySchemes are the proxy URL schemes the HTTP transport and git both support
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// parseProxyURL parses the global proxy setting, e.g. http://proxy.corp:3128 or socks5://proxy.corp:1080
+func parseProxyURL(proxy string) (*url.URL, error) {
+	parsed, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if !slices.Contains(proxySchemes, parsed.Scheme) || parsed.Host == "" {
+		return nil, fmt.Errorf("proxy must be an http, https, socks5 or socks5h URL with a host, got: %s", redactCredentials(proxy))
+	}
+	return parsed, nil
+}
+
+// bypassProxy reports whether requests to target go direct because of a no_proxy entry
+// Entries are "*", host names matching themselves and their subdomains (a leading "." is optional),
+// IP addresses, CIDR ranges, and any of these with ":port" to match only that port.
+func bypassProxy(target *url.URL, noProxy []string) bool {
+	host, port := strings.ToLower(target.Hostname()), target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if entry != "" && (host == entry || strings.HasSuffix(host, "."+entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadCACertPool returns the system roots plus every certificate in the PEM bundle at path
+func loadCACertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// newHTTPTransport builds the transport for provider and webhook calls from the global TLS and proxy settings
+// It returns nil, meaning http.DefaultTransport, when nothing is configured. A CA bundle that cannot be
+// loaded is logged and left out, so hosts signed by it keep failing verification rather than being trusted.
+func newHTTPTransport(config *Config) http.RoundTripper {
+	global := &config.Global
+	if global.CACertFile == "" && !global.InsecureSkipVerify && global.Proxy == "" {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if global.Proxy != "" {
+		// Replaces the HTTP(S)_PROXY environment lookup, so no_proxy is the only bypass list
+		if proxyURL, err := parseProxyURL(global.Proxy); err != nil {
+			AppLogger.WarnS("Ignoring proxy", "error", err)
+		} else {
+			noProxy := global.NoProxy
+			transport.Proxy = func(req *http.Request) (*url.URL, error) {
+				if bypassProxy(req.URL, noProxy) {
+					return nil, nil
+				}
+				return proxyURL, nil
+			}
+		}
+	}
+	if global.CACertFile == "" && !global.InsecureSkipVerify {
+		return transport
+	}
+
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: global.InsecureSkipVerify}
+	if global.CACertFile != "" {
+		pool, err := loadCACertPool(global.CACertFile)
+		if err != nil {
+			AppLogger.WarnS("Ignoring ca_cert_file",
+				"path", global.CACertFile,
+				"error", err)
+		} else {
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+	return transport
+}
+
+// gitCommandEnv returns the environment for git clone and ls-remote commands
+// Prompts are disabled, and git uses the same proxy and CA bundle (or skips verification) as API calls.
+func gitCommandEnv(config *Config) []string {
+	env := append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=true")
+	if config.Global.CACertFile != "" {
+		env = append(env, "GIT_SSL_CAINFO="+config.Global.CACertFile)
+	}
+	if config.Global.InsecureSkipVerify {
+		env = append(env, "GIT_SSL_NO_VERIFY=true")
+	}
+	if config.Global.Proxy != "" {
+		// Both spellings, since curl only reads lowercase http_proxy and other tools the uppercase one
+		noProxy := strings.Join(config.Global.NoProxy, ",")
+		env = append(env,
+			"HTTP_PROXY="+config.Global.Proxy, "http_proxy="+config.Global.Proxy,
+			"HTTPS_PROXY="+config.Global.Proxy, "https_proxy="+config.Global.Proxy,
+			"NO_PROXY="+noProxy, "no_proxy="+noProxy)
+	}
+	return env
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeServerCAFile writes the certificate of a TLS test server as a PEM bundle
+func writeServerCAFile(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	return path
+}
+
+func TestNewHTTPTransportTLS(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	caFile := writeServerCAFile(t, server)
+
+	tests := []struct {
+		name       string
+		global     GlobalConfig
+		wantNil    bool
+		wantRootCA bool
+		wantSkip   bool
+		wantErr    bool // the TLS server's self-signed certificate is rejected
+	}{
+		{name: "defaults", wantNil: true, wantErr: true},
+		{name: "ca bundle", global: GlobalConfig{CACertFile: caFile}, wantRootCA: true},
+		{name: "insecure skip verify", global: GlobalConfig{InsecureSkipVerify: true}, wantSkip: true},
+		{name: "unreadable ca bundle", global: GlobalConfig{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Global: tt.global}
+			monitor := NewMonitorService(config, nil)
+
+			roundTripper := monitor.httpClient.Transport
+			if tt.wantNil {
+				if roundTripper != nil {
+					t.Errorf("Transport = %T, want nil for http.DefaultTransport", roundTripper)
+				}
+			} else {
+				transport, ok := roundTripper.(*http.Transport)
+				if !ok || transport.TLSClientConfig == nil {
+					t.Fatalf("Transport = %#v, want an *http.Transport with TLS settings", roundTripper)
+				}
+				if got := transport.TLSClientConfig.RootCAs != nil; got != tt.wantRootCA {
+					t.Errorf("RootCAs set = %v, want %v", got, tt.wantRootCA)
+				}
+				if transport.TLSClientConfig.InsecureSkipVerify != tt.wantSkip {
+					t.Errorf("InsecureSkipVerify = %v, want %v", transport.TLSClientConfig.InsecureSkipVerify, tt.wantSkip)
+				}
+			}
+
+			resp, err := monitor.httpClient.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GET over TLS error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitCommandEnvTLS(t *testing.T) {
+	config := &Config{Global: GlobalConfig{CACertFile: "/etc/ssl/internal-ca.pem", InsecureSkipVerify: true}}
+	env := gitCommandEnv(config)
+	for _, want := range []string{"GIT_TERMINAL_PROMPT=0", "GIT_SSL_CAINFO=/etc/ssl/internal-ca.pem", "GIT_SSL_NO_VERIFY=true"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("gitCommandEnv() is missing %s", want)
+		}
+	}
+	if env := gitCommandEnv(&Config{}); slices.Contains(env, "GIT_SSL_NO_VERIFY=true") {
+		t.Errorf("gitCommandEnv() skips verification without insecure_skip_verify")
+	}
+}
+
+func TestNewHTTPTransportProxy(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+	}))
+	t.Cleanup(proxy.Close)
+
+	config := &Config{Global: GlobalConfig{Proxy: proxy.URL, NoProxy: []string{".corp.example", "10.0.0.0/8"}}}
+	monitor := NewMonitorService(config, nil)
+
+	// The host does not resolve, so the request only succeeds through the proxy
+	resp, err := monitor.httpClient.Get("http://gitlab.public.example/api/v4/version")
+	if err != nil {
+		t.Fatalf("GET through proxy error = %v", err)
+	}
+	resp.Body.Close()
+	if got := <-proxied; got != "http://gitlab.public.example/api/v4/version" {
+		t.Errorf("proxy received %q, want the absolute request URL", got)
+	}
+
+	transport := monitor.httpClient.Transport.(*http.Transport)
+	for target, wantProxy := range map[string]bool{
+		"https://api.github.com/repos/o/r":   true,
+		"https://gitlab.corp.example/api/v4": false,
+		"http://10.1.2.3:8080/api":           false,
+	} {
+		req := httptest.NewRequest("GET", target, nil)
+		proxyURL, err := transport.Proxy(req)
+		if err != nil || (proxyURL != nil) != wantProxy {
+			t.Errorf("Proxy(%s) = %v, %v, want proxied %v", target, proxyURL, err, wantProxy)
+		}
+		if wantProxy && proxyURL.String() != proxy.URL {
+			t.Errorf("Proxy(%s) = %s, want %s", target, proxyURL, proxy.URL)
+		}
+	}
+}
+
+func TestBypassProxy(t *testing.T) {
+	noProxy := []string{"corp.example", ".internal", "10.0.0.0/8", "192.168.1.5", "gitea.lab:3000"}
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"https://corp.example/api", true},
+		{"https://gitlab.corp.example/api", true},
+		{"https://notcorp.example/api", false},
+		{"http://git.internal/api", true},
+		{"http://10.20.30.40/api", true},
+		{"http://11.0.0.1/api", false},
+		{"http://192.168.1.5:8080/api", true},
+		{"http://gitea.lab:3000/api", true},
+		{"http://gitea.lab/api", false},
+		{"https://api.github.com/repos", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			target, _ := url.Parse(tt.target)
+			if got := bypassProxy(target, noProxy); got != tt.want {
+				t.Errorf("bypassProxy(%s) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+
+	if target, _ := url.Parse("https://api.github.com"); !bypassProxy(target, []string{"*"}) {
+		t.Errorf("bypassProxy() with * = false, want true")
+	}
+}
+
+func TestGitCommandEnvProxy(t *testing.T) {
+	config := &Config{Global: GlobalConfig{Proxy: "socks5://proxy.corp:1080", NoProxy: []string{".corp.example", "10.0.0.0/8"}}}
+	env := gitCommandEnv(config)
+	for _, want := range []string{
+		"HTTP_PROXY=socks5://proxy.corp:1080", "https_proxy=socks5://proxy.corp:1080",
+		"NO_PROXY=.corp.example,10.0.0.0/8", "no_proxy=.corp.example,10.0.0.0/8",
+	} {
+		if !slices.Contains(env, want) {
+			t.Errorf("gitCommandEnv() is missing %s", want)
+		}
+	}
+}
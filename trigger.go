@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// triggerOutcome is the result of one group or repository deployment started by the trigger action
+type triggerOutcome struct {
+	Kind     string // group or repository
+	Name     string
+	Success  bool
+	Duration time.Duration
+	Error    string
+	Result   interface{} // *GroupDeployResult or *DeployResult, emitted by -output=json
+}
+
+// newTriggerOutcome records how a deployment started at startTime ended
+func newTriggerOutcome(kind string, name string, startTime time.Time, result interface{}, err error) triggerOutcome {
+	outcome := triggerOutcome{
+		Kind:     kind,
+		Name:     name,
+		Success:  err == nil,
+		Duration: time.Since(startTime).Round(time.Millisecond),
+		Result:   result,
+	}
+	if err != nil {
+		// Command output can span lines; the first line keeps the table readable
+		outcome.Error, _, _ = strings.Cut(err.Error(), "\n")
+	}
+	return outcome
+}
+
+// writeTriggerSummary prints one row per attempted deployment followed by the succeeded/failed counts
+func writeTriggerSummary(w io.Writer, outcomes []triggerOutcome) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TARGET\tKIND\tRESULT\tDURATION\tERROR")
+
+	succeeded := 0
+	for _, outcome := range outcomes {
+		result, errText := "FAILED", outcome.Error
+		if outcome.Success {
+			result, errText = "ok", "-"
+			succeeded++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", outcome.Name, outcome.Kind, result, outcome.Duration, errText)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d succeeded, %d failed\n", succeeded, len(outcomes)-succeeded)
+	return err
+}
+
+// writeTriggerResults encodes the deploy result of every outcome as a JSON array
+// Group entries are GroupDeployResult objects and repository entries DeployResult objects.
+func writeTriggerResults(w io.Writer, outcomes []triggerOutcome) error {
+	results := make([]interface{}, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		results = append(results, outcome.Result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// writeTriggerOutput prints the outcomes as a summary table, or as JSON results for -output=json
+func writeTriggerOutput(w io.Writer, output string, outcomes []triggerOutcome) error {
+	if output == "json" {
+		return writeTriggerResults(w, outcomes)
+	}
+	return writeTriggerSummary(w, outcomes)
+}
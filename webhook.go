@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DeployWebhookPayload is the JSON body POSTed to a repository's webhook_url
+type DeployWebhookPayload struct {
+	Event       string    `json:"event"`
+	RepoName    string    `json:"repo_name"`
+	DisplayName string    `json:"display_name,omitempty"`
+	GroupName   string    `json:"group_name,omitempty"`
+	Success     bool      `json:"success"`
+	Duration    string    `json:"duration"`
+	CommandsRun []string  `json:"commands_run"`
+	Error       string    `json:"error,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+
+	Trigger *DeployTrigger `json:"trigger,omitempty"` // Monitored change that caused the deployment
+}
+
+// getWebhookTimeout returns the configured webhook delivery timeout or default
+func getWebhookTimeout(config *Config) time.Duration {
+	if config.Global.WebhookTimeout > 0 {
+		return time.Duration(config.Global.WebhookTimeout) * time.Second
+	}
+	return 10 * time.Second
+}
+
+// newDeployWebhookPayload builds the webhook body for a finalized deploy result
+func newDeployWebhookPayload(result *DeployResult) DeployWebhookPayload {
+	commandsRun := result.CommandsRun
+	if commandsRun == nil {
+		commandsRun = []string{}
+	}
+
+	return DeployWebhookPayload{
+		Event:       "deploy.completed",
+		RepoName:    result.RepoName,
+		DisplayName: result.DisplayName,
+		GroupName:   result.GroupName,
+		Success:     result.Success,
+		Duration:    result.Duration,
+		CommandsRun: commandsRun,
+		Error:       result.Error,
+		Timestamp:   time.Now(),
+		Trigger:     result.Trigger,
+	}
+}
+
+// notifyDeployWebhook delivers a finalized deploy result to the repository's webhook_url
+// Delivery failures are logged and never change the deployment outcome.
+func (d *DeployService) notifyDeployWebhook(repoConfig *RepositoryConfig, result *DeployResult) {
+	if repoConfig.WebhookURL == "" {
+		return
+	}
+
+	if err := d.postWebhook(repoConfig.WebhookURL, newDeployWebhookPayload(result)); err != nil {
+		AppLogger.WarnS("Failed to deliver deployment webhook",
+			"repo", repoConfig.GetDisplayName(),
+			"error", err)
+		return
+	}
+
+	AppLogger.DebugS("Deployment webhook delivered", "repo", repoConfig.GetDisplayName())
+}
+
+// postWebhook POSTs a JSON payload and treats any non-2xx response as a failure
+func (d *DeployService) postWebhook(webhookURL string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return d.postBody(webhookURL, "application/json", body)
+}
+
+// postBody POSTs a raw body and treats any non-2xx response as a failure
+// Errors never include the full URL, since the path of Slack-style webhooks is their secret.
+func (d *DeployService) postBody(webhookURL string, contentType string, body []byte) error {
+	req, err := http.NewRequest("POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request for %s: %w", redactWebhookURL(webhookURL), stripURLError(err))
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := d.webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request to %s failed: %w", redactWebhookURL(webhookURL), stripURLError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// stripURLError returns the cause of a *url.Error, dropping the request URL it names
+func stripURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// redactWebhookURL keeps only the scheme and host of a webhook URL for error messages
+func redactWebhookURL(webhookURL string) string {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Host == "" {
+		return "***"
+	}
+	return fmt.Sprintf("%s://%s/***", parsed.Scheme, parsed.Host)
+}
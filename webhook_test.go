@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeployWebhookPayload(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("webhook method = %s, want POST", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("webhook body is not JSON: %v", err)
+		}
+		received <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Global: GlobalConfig{
+			TmpDir:  t.TempDir(),
+			Cleanup: true,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name:       "hooked-repo",
+				WebhookURL: server.URL,
+				Deploy: DeployConfig{
+					QARepoURL:    filepath.Join(t.TempDir(), "missing"),
+					QARepoBranch: "main",
+					RepoType:     "git",
+					ProjectName:  "hooked",
+					Commands:     []CommandSpec{{Run: "true"}},
+				},
+			},
+		},
+	}
+
+	service := NewDeployService(config)
+	result := service.deployRepository("hooked-repo", context.Background(), nil)
+
+	var payload map[string]interface{}
+	select {
+	case payload = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	if payload["event"] != "deploy.completed" {
+		t.Errorf("payload event = %v, want deploy.completed", payload["event"])
+	}
+	if payload["repo_name"] != "hooked-repo" {
+		t.Errorf("payload repo_name = %v, want hooked-repo", payload["repo_name"])
+	}
+	if payload["success"] != false {
+		t.Errorf("payload success = %v, want false", payload["success"])
+	}
+	if payload["error"] != result.Error || result.Error == "" {
+		t.Errorf("payload error = %v, want %q", payload["error"], result.Error)
+	}
+	if payload["duration"] != result.Duration {
+		t.Errorf("payload duration = %v, want %v", payload["duration"], result.Duration)
+	}
+	if commands, ok := payload["commands_run"].([]interface{}); !ok || len(commands) != 0 {
+		t.Errorf("payload commands_run = %v, want empty array", payload["commands_run"])
+	}
+}
+
+func TestDeployWebhookFailureDoesNotFailDeploy(t *testing.T) {
+	// Initialize logger for test
+	InitializeLogger(false)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	qaRepo := newLocalQARepo(t, map[string]string{"deploy.yaml": "kind: Pipeline\n"})
+
+	config := &Config{
+		Global: GlobalConfig{
+			TmpDir:  t.TempDir(),
+			Cleanup: true,
+		},
+		Repositories: []RepositoryConfig{
+			{
+				Name:       "hooked-repo",
+				WebhookURL: server.URL,
+				Deploy: DeployConfig{
+					QARepoURL:    qaRepo,
+					QARepoBranch: "main",
+					RepoType:     "git",
+					ProjectName:  "hooked",
+					Commands:     []CommandSpec{{Run: "true"}},
+				},
+			},
+		},
+	}
+
+	service := NewDeployService(config)
+	result := service.deployRepository("hooked-repo", context.Background(), nil)
+
+	if !result.Success {
+		t.Errorf("deployRepository() success = false (%s), webhook failure must not fail the deploy", result.Error)
+	}
+}
+
+func TestGetWebhookTimeout(t *testing.T) {
+	if got := getWebhookTimeout(&Config{}); got != 10*time.Second {
+		t.Errorf("getWebhookTimeout() default = %v, want 10s", got)
+	}
+
+	config := &Config{Global: GlobalConfig{WebhookTimeout: 3}}
+	if got := getWebhookTimeout(config); got != 3*time.Second {
+		t.Errorf("getWebhookTimeout() = %v, want 3s", got)
+	}
+	if got := NewDeployService(config).webhookClient.Timeout; got != 3*time.Second {
+		t.Errorf("webhook client timeout = %v, want 3s", got)
+	}
+}